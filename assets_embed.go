@@ -0,0 +1,14 @@
+//go:build embed
+
+package main
+
+import "embed"
+
+// 使用 go build -tags embed 编译时把素材目录编译进程序，发布时不需要拷贝素材目录
+//
+//go:embed assets
+var assetsFS embed.FS
+
+func init() {
+	embeddedAssets = assetsFS
+}
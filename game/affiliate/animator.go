@@ -0,0 +1,254 @@
+package affiliate
+
+import (
+	"ghost_escape/game/core"
+)
+
+// 进入动画状态的方式
+type AnimTransitionMode int
+
+const (
+	// 从第一帧重新播放
+	AnimTransitionModeRestart AnimTransitionMode = iota
+	// 接着上一个状态的当前帧播放
+	AnimTransitionModeSync
+)
+
+// 切换条件类型
+type AnimConditionType int
+
+const (
+	// 速度大于Value
+	AnimConditionSpeedAbove AnimConditionType = iota
+	// 速度小于等于Value
+	AnimConditionSpeedBelow
+	// 血量小于Value
+	AnimConditionHealthBelow
+	// 无敌中
+	AnimConditionInvincible
+	// 活着
+	AnimConditionAlive
+	// 当前状态的动画播放完毕
+	AnimConditionFinished
+	// 自定义条件，调用Func
+	AnimConditionFunc
+)
+
+// 切换条件
+type AnimCondition struct {
+	// 条件类型
+	Type AnimConditionType
+	// 比较的值
+	Value float32
+	// 是否取反
+	Not bool
+	// 自定义条件，类型为AnimConditionFunc时使用
+	Func func() bool
+}
+
+// 动画状态
+type AnimState struct {
+	// 状态名字
+	Name string
+	// 状态使用的动画，多个状态可以共用一个动画，播放不同的精灵表动画
+	Anim *SpriteAnim
+	// 精灵表中的动画名字，为空时不切换动画
+	Clip string
+	// 是否循环播放
+	Loop bool
+	// 进入方式
+	Mode AnimTransitionMode
+	// 进入状态时回调
+	OnEnter func()
+}
+
+// 状态切换
+type AnimTransition struct {
+	// 起始状态，为空表示任意状态
+	From string
+	// 目标状态
+	To string
+	// 切换条件，全部满足才切换，为空表示总是切换
+	Conditions []AnimCondition
+}
+
+// 动画状态机组件，按声明顺序检查切换，第一个满足条件的切换生效
+type Animator struct {
+	// 继承基础对象
+	core.Object
+	// 父节点，条件根据父节点的速度和属性判断
+	Parent *core.Actor
+	// 所有状态
+	states map[string]*AnimState
+	// 所有切换，按声明顺序排列
+	transitions []AnimTransition
+	// 当前状态
+	current *AnimState
+}
+
+var _ core.IObject = (*Animator)(nil)
+
+// 添加动画状态机组件到父对象中
+func AddAnimatorChild(parent *core.Actor) *Animator {
+	child := &Animator{}
+	child.Init()
+	child.Parent = parent
+	if parent != nil {
+		parent.AddChild(child)
+	}
+	return child
+}
+
+// 初始化
+func (a *Animator) Init() {
+	a.Object.Init()
+	a.states = make(map[string]*AnimState)
+	a.transitions = make([]AnimTransition, 0)
+	a.current = nil
+}
+
+// 更新，检查切换条件
+func (a *Animator) Update(dt float32) {
+	a.Object.Update(dt)
+	if a.current == nil {
+		return
+	}
+	for i := range a.transitions {
+		transition := &a.transitions[i]
+		if transition.From != "" && transition.From != a.current.Name {
+			continue
+		}
+		if !a.check(transition.Conditions) {
+			continue
+		}
+		// 目标就是当前状态时保持不变，后面的切换也不再检查
+		if transition.To != a.current.Name {
+			a.SetState(transition.To)
+		}
+		return
+	}
+}
+
+// 非接口实现
+
+// 添加状态，第一个添加的状态为初始状态
+func (a *Animator) AddState(state AnimState) {
+	s := &state
+	s.Anim.SetLoop(s.Loop)
+	a.states[s.Name] = s
+	if a.current == nil {
+		a.enter(s, nil)
+		return
+	}
+	if s.Anim != a.current.Anim {
+		s.Anim.SetActive(false)
+	}
+}
+
+// 添加切换，from为空表示任意状态
+func (a *Animator) AddTransition(from, to string, conditions ...AnimCondition) {
+	a.transitions = append(a.transitions, AnimTransition{From: from, To: to, Conditions: conditions})
+}
+
+// 切换到指定状态，状态不存在返回false
+func (a *Animator) SetState(name string) bool {
+	next, ok := a.states[name]
+	if !ok {
+		return false
+	}
+	a.enter(next, a.current)
+	return true
+}
+
+// 获取当前状态名字
+func (a *Animator) GetState() string {
+	if a.current == nil {
+		return ""
+	}
+	return a.current.Name
+}
+
+// 获取当前动画
+func (a *Animator) GetCurrentAnim() *SpriteAnim {
+	if a.current == nil {
+		return nil
+	}
+	return a.current.Anim
+}
+
+// 获取当前状态的动画是否播放完毕
+func (a *Animator) GetFinish() bool {
+	if a.current == nil {
+		return false
+	}
+	return a.current.Anim.GetFinish()
+}
+
+// 设置所有状态动画是否翻转
+func (a *Animator) SetFlip(flip bool) {
+	for _, state := range a.states {
+		state.Anim.SetFlip(flip)
+	}
+}
+
+// 进入状态
+func (a *Animator) enter(next, prev *AnimState) {
+	var frame, frameTimer float32
+	if prev != nil {
+		frame = prev.Anim.GetCurrentFrame()
+		frameTimer = prev.Anim.GetFrameTimer()
+		if prev.Anim != next.Anim {
+			prev.Anim.SetActive(false)
+		}
+	}
+	anim := next.Anim
+	anim.SetActive(true)
+	anim.SetLoop(next.Loop)
+	if next.Clip != "" {
+		anim.Play(next.Clip)
+	}
+	anim.SetFinish(false)
+	if prev != nil && next.Mode == AnimTransitionModeSync && anim.GetTotalFrame() > 0.0 {
+		// 帧数不同时取余，保证帧在范围内，同步过来的帧不重复触发事件
+		anim.SyncCurrentFrame(float32(int(frame) % int(anim.GetTotalFrame())))
+		anim.SetFrameTimer(frameTimer)
+	} else {
+		anim.SetCurrentFrame(0.0)
+		anim.SetFrameTimer(0.0)
+	}
+	a.current = next
+	if next.OnEnter != nil {
+		next.OnEnter()
+	}
+}
+
+// 检查条件是否全部满足
+func (a *Animator) check(conditions []AnimCondition) bool {
+	for i := range conditions {
+		if a.test(&conditions[i]) == conditions[i].Not {
+			return false
+		}
+	}
+	return true
+}
+
+// 检查单个条件，不考虑取反
+func (a *Animator) test(condition *AnimCondition) bool {
+	switch condition.Type {
+	case AnimConditionSpeedAbove:
+		return a.Parent != nil && a.Parent.GetVelocity().Len() > condition.Value
+	case AnimConditionSpeedBelow:
+		return a.Parent != nil && a.Parent.GetVelocity().Len() <= condition.Value
+	case AnimConditionHealthBelow:
+		return a.Parent != nil && a.Parent.Stats != nil && a.Parent.Stats.GetHealth() < condition.Value
+	case AnimConditionInvincible:
+		return a.Parent != nil && a.Parent.Stats != nil && a.Parent.Stats.GetInvincible()
+	case AnimConditionAlive:
+		return a.Parent != nil && a.Parent.GetAlive()
+	case AnimConditionFinished:
+		return a.GetFinish()
+	case AnimConditionFunc:
+		return condition.Func != nil && condition.Func()
+	}
+	return false
+}
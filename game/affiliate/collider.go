@@ -2,7 +2,9 @@ package affiliate
 
 import (
 	"ghost_escape/game/core"
+	"math"
 
+	"github.com/SunshineZzzz/purego-sdl3/sdl"
 	"github.com/go-gl/mathgl/mgl32"
 )
 
@@ -16,18 +18,35 @@ var _ core.IObject = (*Collider)(nil)
 var _ core.IObjectCollider = (*Collider)(nil)
 var _ core.IObjectAffiliate = (*Collider)(nil)
 
-// 添加碰撞器子对象
+// 添加碰撞器子对象，多边形碰撞器使用AddPolygonColliderChild
 func AddColliderChild(parent core.IObjectScreen, size mgl32.Vec2, colliderType core.ColliderType, anchorType core.AnchorType) *Collider {
-	if colliderType != core.ColliderTypeCircle {
+	if colliderType == core.ColliderTypePolygon {
 		return nil
 	}
 
 	child := &Collider{}
 	child.Init()
+	// 先设置锚点，设置大小时按锚点计算偏移
+	child.SetAnchorType(anchorType)
 	child.SetSize(size)
 	child.SetParent(parent)
 	child.SetColliderType(colliderType)
+	parent.AddChild(child)
+	return child
+}
+
+// 添加凸多边形碰撞器子对象，顶点至少3个，按顺序排列，锚点按顶点的包围盒计算
+func AddPolygonColliderChild(parent core.IObjectScreen, points []mgl32.Vec2, anchorType core.AnchorType) *Collider {
+	if len(points) < 3 {
+		return nil
+	}
+
+	child := &Collider{}
+	child.Init()
 	child.SetAnchorType(anchorType)
+	child.SetPoints(points)
+	child.SetParent(parent)
+	child.SetColliderType(core.ColliderTypePolygon)
 	parent.AddChild(child)
 	return child
 }
@@ -36,22 +55,49 @@ func AddColliderChild(parent core.IObjectScreen, size mgl32.Vec2, colliderType c
 func (s *Collider) Init() {
 	s.ObjectAffiliate.Init()
 	s.Type = core.ColliderTypeCircle
+	// 默认不关心任何层，需要碰撞回调时设置掩码
+	s.Layer = core.CollisionLayerDefault
+	s.Mask = core.CollisionLayerNone
+}
+
+// 更新，依附在世界对象上时注册到场景的空间哈希，场景在世界对象更新完后刷新位置
+func (s *Collider) Update(dt float32) {
+	s.ObjectAffiliate.Update(dt)
+	if _, ok := s.Parent.(core.IObjectWorld); !ok {
+		return
+	}
+	s.Game().GetCurrentScene().GetSpatialHash().Insert(s)
 }
 
 // 渲染
-// 渲染碰撞器
+// 渲染碰撞器，只在绘制调试信息时渲染
 func (s *Collider) Render() {
 	s.ObjectAffiliate.Render()
-	// if s.GetColliderType() == core.ColliderTypeCircle {
-	// 	pos := s.Parent.GetRenderPosition().Add(s.Offset)
-	// 	// 圆形碰撞器渲染
-	// 	s.Game().RenderFillCircle(pos, s.Size, 0.3)
-	// }
+	if !s.Game().GetDebugDraw() {
+		return
+	}
+	if s.GetColliderType() == core.ColliderTypeCircle {
+		pos := s.Parent.GetRenderPosition().Add(s.Offset)
+		// 圆形碰撞器渲染，世界视图中跟着相机缩放
+		s.Game().RenderFillCircle(pos, s.Size, 0.3)
+		return
+	}
+	// 其他形状画轮廓，形状是世界坐标，加上父节点渲染位置和世界位置的差转换到渲染坐标
+	shape := s.GetShape()
+	delta := s.Parent.GetRenderPosition().Sub(s.Parent.GetPosition())
+	points := shape.Points
+	if shape.Type == core.ColliderTypeCapsule {
+		points = capsuleOutline(shape.A, shape.B, shape.Radius)
+	}
+	for i := range points {
+		points[i] = points[i].Add(delta)
+	}
+	s.Game().DrawPolygon(points, sdl.FColor{R: 0.0, G: 1.0, B: 0.0, A: 0.6})
 }
 
 // 是否发生碰撞
 func (s *Collider) IsColliding(other core.IObjectCollider) bool {
-	if other == nil {
+	if other == nil || s.Parent == nil || other.GetParent() == nil {
 		return false
 	}
 	if s.GetColliderType() == core.ColliderTypeCircle && other.GetColliderType() == core.ColliderTypeCircle {
@@ -66,8 +112,31 @@ func (s *Collider) IsColliding(other core.IObjectCollider) bool {
 		}
 		return false
 	}
-	// TODO: 其他碰撞器类型
-	return false
+	// 其他碰撞器类型，圆形和胶囊按线段加半径，其余按凸多边形检测
+	shape := s.GetShape()
+	otherShape := other.GetShape()
+	return core.CollideShapes(&shape, &otherShape)
 }
 
 // 非接口实现
+
+// 胶囊轮廓，两端各是半圆
+func capsuleOutline(a, b mgl32.Vec2, radius float32) []mgl32.Vec2 {
+	const segments = 8
+	axis := b.Sub(a)
+	angle := float64(0.0)
+	if axis.Len() > 0.0 {
+		angle = math.Atan2(float64(axis.Y()), float64(axis.X()))
+	}
+	points := make([]mgl32.Vec2, 0, (segments+1)*2)
+	// b端从-90度到90度，a端从90度到270度
+	for i := 0; i <= segments; i++ {
+		t := angle - math.Pi/2 + math.Pi*float64(i)/segments
+		points = append(points, b.Add(mgl32.Vec2{float32(math.Cos(t)), float32(math.Sin(t))}.Mul(radius)))
+	}
+	for i := 0; i <= segments; i++ {
+		t := angle + math.Pi/2 + math.Pi*float64(i)/segments
+		points = append(points, a.Add(mgl32.Vec2{float32(math.Cos(t)), float32(math.Sin(t))}.Mul(radius)))
+	}
+	return points
+}
@@ -0,0 +1,267 @@
+package affiliate
+
+import (
+	"ghost_escape/game/core"
+	"math"
+
+	"github.com/SunshineZzzz/purego-sdl3/sdl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// 粒子模拟空间
+type ParticleSpace int
+
+const (
+	// 世界空间，粒子发射后不再跟随发射器移动，适合拖尾
+	ParticleSpaceWorld ParticleSpace = iota
+	// 本地空间，粒子跟随发射器一起移动
+	ParticleSpaceLocal
+)
+
+// 粒子发射配置
+type ParticleConfig struct {
+	// 粒子纹理，为空时绘制方块
+	Texture string
+	// 开始时一次发射的数量
+	Burst int
+	// 每秒持续发射的数量，0表示不持续发射
+	Rate float32
+	// 持续发射的时长，0表示一直发射
+	Duration float32
+	// 最多同时存在的粒子数量
+	MaxParticles int
+	// 生命周期范围，单位秒
+	LifetimeMin, LifetimeMax float32
+	// 初速度大小范围
+	SpeedMin, SpeedMax float32
+	// 发射方向，单位度，0度向右，90度向下
+	Direction float32
+	// 发射方向的扩散角度，单位度，360表示向四周发射
+	Spread float32
+	// 发射位置的随机半径
+	Radius float32
+	// 重力加速度
+	Gravity mgl32.Vec2
+	// 阻尼，每秒速度衰减的比例
+	Damping float32
+	// 出生和死亡时的颜色，中间线性变化
+	StartColor, EndColor sdl.FColor
+	// 出生和死亡时的大小，中间线性变化
+	StartSize, EndSize float32
+	// 模拟空间
+	Space ParticleSpace
+}
+
+// 粒子
+type particle struct {
+	// 位置，世界空间时是世界坐标，本地空间时是相对发射器的偏移
+	position mgl32.Vec2
+	// 速度
+	velocity mgl32.Vec2
+	// 已经存在的时间
+	life float32
+	// 生命周期
+	lifetime float32
+}
+
+// 粒子发射器组件，可以挂在任意屏幕对象或世界对象上
+type ParticleEmitter struct {
+	// 继承基础依附对象
+	core.ObjectAffiliate
+	// 发射配置
+	config ParticleConfig
+	// 粒子纹理，为nil时绘制方块
+	texture *core.Texture
+	// 存活的粒子
+	particles []particle
+	// 是否正在发射
+	emitting bool
+	// 已经发射的时间
+	emitTimer float32
+	// 持续发射时累计的未发射数量
+	emitAccumulator float32
+}
+
+var _ core.IObject = (*ParticleEmitter)(nil)
+var _ core.IObjectAffiliate = (*ParticleEmitter)(nil)
+
+// 添加粒子发射器到父对象中，添加后立即开始发射
+func AddParticleEmitterChild(parent core.IObjectScreen, config ParticleConfig) *ParticleEmitter {
+	child := &ParticleEmitter{}
+	child.Init()
+	child.SetConfig(config)
+	child.SetParent(parent)
+	parent.AddChild(child)
+	child.Start()
+	return child
+}
+
+// 初始化
+func (p *ParticleEmitter) Init() {
+	p.ObjectAffiliate.Init()
+	p.AnchorType = core.AnchorTypeTopLeft
+	p.particles = make([]particle, 0)
+}
+
+// 更新，发射新粒子并推进已有粒子
+func (p *ParticleEmitter) Update(dt float32) {
+	p.ObjectAffiliate.Update(dt)
+	if p.emitting && p.config.Rate > 0.0 {
+		p.emitTimer += dt
+		p.emitAccumulator += p.config.Rate * dt
+		count := int(p.emitAccumulator)
+		p.emitAccumulator -= float32(count)
+		p.Emit(count)
+		if p.config.Duration > 0.0 && p.emitTimer >= p.config.Duration {
+			p.emitting = false
+		}
+	}
+	// 速度每秒衰减Damping比例，与tick频率无关
+	damping := float32(math.Pow(float64(1.0-mgl32.Clamp(p.config.Damping, 0.0, 1.0)), float64(dt)))
+	alive := p.particles[:0]
+	for _, item := range p.particles {
+		item.life += dt
+		if item.life >= item.lifetime {
+			continue
+		}
+		item.velocity = item.velocity.Add(p.config.Gravity.Mul(dt)).Mul(damping)
+		item.position = item.position.Add(item.velocity.Mul(dt))
+		alive = append(alive, item)
+	}
+	p.particles = alive
+}
+
+// 渲染，颜色和大小按生命周期插值
+func (p *ParticleEmitter) Render() {
+	p.ObjectAffiliate.Render()
+	if len(p.particles) == 0 || p.Parent == nil {
+		return
+	}
+	origin := p.Parent.GetRenderPosition().Add(p.Offset)
+	if p.config.Space == ParticleSpaceWorld {
+		origin = p.Game().GetCurrentScene().GetRenderCameraPosition().Mul(-1.0)
+	}
+	for i := range p.particles {
+		item := &p.particles[i]
+		t := item.life / item.lifetime
+		color := lerpColor(p.config.StartColor, p.config.EndColor, t)
+		size := p.config.StartSize + (p.config.EndSize-p.config.StartSize)*t
+		if size <= 0.0 || color.A <= 0.0 {
+			continue
+		}
+		pos := origin.Add(item.position).Sub(mgl32.Vec2{size / 2.0, size / 2.0})
+		if p.texture == nil {
+			p.Game().DrawFillRect(pos, pos.Add(mgl32.Vec2{size, size}), color)
+			continue
+		}
+		p.texture.Color = color
+		p.Game().RenderTexture(p.texture, pos, mgl32.Vec2{size, size}, mgl32.Vec2{1.0, 1.0})
+	}
+}
+
+// 非接口实现
+
+// 获取发射配置
+func (p *ParticleEmitter) GetConfig() ParticleConfig {
+	return p.config
+}
+
+// 设置发射配置，已经存在的粒子不受影响
+func (p *ParticleEmitter) SetConfig(config ParticleConfig) {
+	if config.MaxParticles <= 0 {
+		config.MaxParticles = 256
+	}
+	config.LifetimeMax = max(config.LifetimeMax, config.LifetimeMin)
+	config.SpeedMax = max(config.SpeedMax, config.SpeedMin)
+	p.config = config
+	p.texture = nil
+	if config.Texture != "" {
+		p.texture = core.CreateTexture(config.Texture)
+	}
+}
+
+// 开始发射，先一次发射Burst个粒子，然后按Rate持续发射
+func (p *ParticleEmitter) Start() {
+	p.emitTimer = 0.0
+	p.emitAccumulator = 0.0
+	p.emitting = p.config.Rate > 0.0
+	p.Emit(p.config.Burst)
+}
+
+// 停止持续发射，已经发射的粒子继续存在到生命周期结束
+func (p *ParticleEmitter) Stop() {
+	p.emitting = false
+}
+
+// 获取是否正在持续发射
+func (p *ParticleEmitter) GetEmitting() bool {
+	return p.emitting
+}
+
+// 设置是否持续发射，和Start不同，不会触发一次发射
+func (p *ParticleEmitter) SetEmitting(emitting bool) {
+	if emitting && !p.emitting {
+		p.emitTimer = 0.0
+	}
+	p.emitting = emitting
+}
+
+// 获取存活的粒子数量
+func (p *ParticleEmitter) GetParticleCount() int {
+	return len(p.particles)
+}
+
+// 获取是否播放完毕，不再发射并且没有存活的粒子
+func (p *ParticleEmitter) GetFinish() bool {
+	return !p.emitting && len(p.particles) == 0
+}
+
+// 立即发射count个粒子，超过最大数量的部分不发射
+func (p *ParticleEmitter) Emit(count int) {
+	count = min(count, p.config.MaxParticles-len(p.particles))
+	if count <= 0 {
+		return
+	}
+	random := p.Game().GetRand(core.RandStreamVisual)
+	origin := mgl32.Vec2{0.0, 0.0}
+	if p.config.Space == ParticleSpaceWorld {
+		origin = p.getWorldPosition()
+	}
+	for range count {
+		angle := mgl32.DegToRad(p.config.Direction + random.RandFloat32(-p.config.Spread/2.0, p.config.Spread/2.0))
+		direction := mgl32.Vec2{float32(math.Cos(float64(angle))), float32(math.Sin(float64(angle)))}
+		position := origin
+		if p.config.Radius > 0.0 {
+			// 在圆内均匀分布，半径取平方根，否则粒子会聚在中心
+			offsetAngle := random.RandFloat32(0.0, 2.0*math.Pi)
+			offsetRadius := p.config.Radius * float32(math.Sqrt(float64(random.RandFloat32(0.0, 1.0))))
+			position = position.Add(mgl32.Vec2{float32(math.Cos(float64(offsetAngle))), float32(math.Sin(float64(offsetAngle)))}.Mul(offsetRadius))
+		}
+		p.particles = append(p.particles, particle{
+			position: position,
+			velocity: direction.Mul(random.RandFloat32(p.config.SpeedMin, p.config.SpeedMax)),
+			lifetime: max(random.RandFloat32(p.config.LifetimeMin, p.config.LifetimeMax), 0.001),
+		})
+	}
+}
+
+// 获取发射器的世界位置，父节点不是世界对象时从渲染位置换算
+func (p *ParticleEmitter) getWorldPosition() mgl32.Vec2 {
+	if p.Parent == nil {
+		return p.Offset
+	}
+	if parent, ok := p.Parent.(core.IObjectWorld); ok {
+		return parent.GetPosition().Add(p.Offset)
+	}
+	return p.Game().GetCurrentScene().ScreenToWorld(p.Parent.GetRenderPosition().Add(p.Offset))
+}
+
+// 颜色线性插值
+func lerpColor(from, to sdl.FColor, t float32) sdl.FColor {
+	return sdl.FColor{
+		R: from.R + (to.R-from.R)*t,
+		G: from.G + (to.G-from.G)*t,
+		B: from.B + (to.B-from.B)*t,
+		A: from.A + (to.A-from.A)*t,
+	}
+}
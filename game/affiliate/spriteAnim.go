@@ -3,6 +3,7 @@ package affiliate
 import (
 	"ghost_escape/game/core"
 
+	"github.com/SunshineZzzz/purego-sdl3/sdl"
 	"github.com/go-gl/mathgl/mgl32"
 )
 
@@ -22,6 +23,18 @@ type SpriteAnim struct {
 	loop bool
 	// 是否播放完毕
 	isFinish bool
+	// 精灵表，为nil时图片是一行正方形的帧
+	sheet *core.SpriteSheet
+	// 当前播放的动画
+	clip *core.SpriteClip
+	// 当前动画第一帧的原始大小，和Size一起计算缩放
+	clipSize mgl32.Vec2
+	// 帧事件，帧索引到事件名字
+	frameEvents map[int][]string
+	// 帧事件回调，事件名字到回调
+	eventCallbacks map[string][]core.FrameEventFunc
+	// 上一次触发事件的帧，-1表示需要重新触发
+	eventFrame int
 }
 
 var _ core.IObject = (*SpriteAnim)(nil)
@@ -29,11 +42,31 @@ var _ ISprite = (*SpriteAnim)(nil)
 var _ core.IObjectAnima = (*SpriteAnim)(nil)
 var _ core.IObjectAffiliate = (*SpriteAnim)(nil)
 
-// 添加精灵图动画组件到父对象中
+// 添加精灵图动画组件到父对象中，图片有同名json精灵表时使用精灵表中的帧和动画
 func AddSpriteAnimChild(parent core.IObjectScreen, filePath string, scale float32, anchorType core.AnchorType) *SpriteAnim {
 	child := &SpriteAnim{}
 	child.Init()
 	child.SetTexture(core.CreateTexture(filePath))
+	if sheet := core.GetInstance().GetAssetStore().FindSpriteSheet(filePath); sheet != nil {
+		child.SetSpriteSheet(sheet)
+	}
+	child.SetScale(scale)
+	child.SetParent(parent)
+	child.SetAnchorType(anchorType)
+	parent.AddChild(child)
+	return child
+}
+
+// 根据精灵表json添加精灵图动画组件，图片路径由精灵表指定
+func AddSpriteSheetAnimChild(parent core.IObjectScreen, sheetPath string, scale float32, anchorType core.AnchorType) *SpriteAnim {
+	sheet, err := core.GetInstance().GetAssetStore().GetSpriteSheet(sheetPath)
+	if err != nil {
+		panic(err)
+	}
+	child := &SpriteAnim{}
+	child.Init()
+	child.SetTexture(core.CreateTexture(sheet.Image))
+	child.SetSpriteSheet(sheet)
 	child.SetScale(scale)
 	child.SetParent(parent)
 	child.SetAnchorType(anchorType)
@@ -47,6 +80,9 @@ func (s *SpriteAnim) Init() {
 	s.fps = 10.0
 	s.loop = true
 	s.isFinish = false
+	s.frameEvents = make(map[int][]string)
+	s.eventCallbacks = make(map[string][]core.FrameEventFunc)
+	s.eventFrame = -1
 }
 
 // 更新
@@ -58,11 +94,13 @@ func (s *SpriteAnim) Update(dt float32) {
 	s.Sprite.Update(dt)
 	s.frameTimer += dt
 	// 动画帧计时器超过播放一帧所需的时间时，切换到下一帧
-	if s.frameTimer >= 1.0/s.fps {
+	if s.frameTimer >= s.getFrameDuration() {
 		s.currentFrame++
 		// 当前帧超过总帧数时，重置当前帧为0
 		if s.currentFrame >= s.totalFrame {
 			s.currentFrame = 0.0
+			// 循环播放时第一帧的事件要重新触发
+			s.eventFrame = -1
 			// 如果不是循环播放，标记为播放完毕
 			if !s.loop {
 				s.isFinish = true
@@ -72,7 +110,25 @@ func (s *SpriteAnim) Update(dt float32) {
 		// 重置动画帧计时器
 		s.frameTimer = 0.0
 	}
-	s.Texture.SrcRect.X = s.Texture.SrcRect.W * s.currentFrame
+	s.updateFrame()
+	s.fireFrameEvents()
+}
+
+// 渲染，精灵表的帧可能裁掉了透明边，按原始大小中的偏移绘制
+func (s *SpriteAnim) Render() {
+	frame := s.getFrame()
+	if frame == nil || s.Texture == nil || s.Parent == nil {
+		s.Sprite.Render()
+		return
+	}
+	scale := s.getClipScale()
+	trim := frame.TrimOffset
+	if s.Texture.IsFlip {
+		trim = mgl32.Vec2{frame.SourceSize.X() - trim.X() - frame.Rect.W, trim.Y()}
+	}
+	pos := s.Parent.GetRenderPosition().Add(s.Offset).Add(mgl32.Vec2{trim.X() * scale.X(), trim.Y() * scale.Y()})
+	size := mgl32.Vec2{frame.Rect.W * scale.X(), frame.Rect.H * scale.Y()}
+	core.GetInstance().RenderTexture(s.Texture, pos, size, s.GetPercent())
 }
 
 // 获取当前帧
@@ -80,9 +136,16 @@ func (s *SpriteAnim) GetCurrentFrame() float32 {
 	return s.currentFrame
 }
 
-// 设置当前帧
+// 设置当前帧，下次更新时重新触发该帧的事件
 func (s *SpriteAnim) SetCurrentFrame(frame float32) {
 	s.currentFrame = frame
+	s.eventFrame = -1
+}
+
+// 接着其他动画的进度设置当前帧，当作该帧已经播放过，不重新触发该帧的事件
+func (s *SpriteAnim) SyncCurrentFrame(frame float32) {
+	s.currentFrame = frame
+	s.eventFrame = int(frame)
 }
 
 // 获取总帧数
@@ -135,8 +198,135 @@ func (s *SpriteAnim) SetFinish(finish bool) {
 	s.isFinish = finish
 }
 
+// 添加帧事件，播放到该帧时触发，帧为当前动画中的帧索引
+func (s *SpriteAnim) AddFrameEvent(frame int, name string) {
+	s.frameEvents[frame] = append(s.frameEvents[frame], name)
+}
+
+// 监听帧事件
+func (s *SpriteAnim) OnFrameEvent(name string, callback core.FrameEventFunc) {
+	s.eventCallbacks[name] = append(s.eventCallbacks[name], callback)
+}
+
 // 非接口实现
 
+// 清除所有帧事件，监听不受影响
+func (s *SpriteAnim) ClearFrameEvents() {
+	s.frameEvents = make(map[int][]string)
+	s.eventFrame = -1
+}
+
+// 触发当前帧的事件，每一帧只在进入时触发一次
+func (s *SpriteAnim) fireFrameEvents() {
+	frame := int(s.currentFrame)
+	if frame == s.eventFrame {
+		return
+	}
+	s.eventFrame = frame
+	for _, name := range s.frameEvents[frame] {
+		for _, callback := range s.eventCallbacks[name] {
+			callback(name, frame)
+		}
+	}
+}
+
+// 获取一帧的持续时间，精灵表中没有指定时使用帧率
+func (s *SpriteAnim) getFrameDuration() float32 {
+	if frame := s.getFrame(); frame != nil && frame.Duration > 0.0 {
+		return frame.Duration
+	}
+	return 1.0 / s.fps
+}
+
+// 获取当前动画的缩放比例，还没有播放动画时为1
+func (s *SpriteAnim) getClipScale() mgl32.Vec2 {
+	if s.clipSize.X() <= 0.0 || s.clipSize.Y() <= 0.0 {
+		return mgl32.Vec2{1.0, 1.0}
+	}
+	return mgl32.Vec2{s.Size.X() / s.clipSize.X(), s.Size.Y() / s.clipSize.Y()}
+}
+
+// 获取精灵表中的当前帧，没有精灵表时返回nil
+func (s *SpriteAnim) getFrame() *core.SpriteFrame {
+	if s.sheet == nil || s.clip == nil || len(s.clip.Frames) == 0 {
+		return nil
+	}
+	index := min(int(s.currentFrame), len(s.clip.Frames)-1)
+	return &s.sheet.Frames[s.clip.Frames[index]]
+}
+
+// 根据当前帧更新纹理源矩形，帧从图片区域开始计算，图片可能打包在图集中
+func (s *SpriteAnim) updateFrame() {
+	frame := s.getFrame()
+	if frame == nil {
+		s.Texture.SrcRect.X = s.Texture.Region.X + s.Texture.SrcRect.W*s.currentFrame
+		return
+	}
+	s.Texture.SrcRect = sdl.FRect{
+		X: s.Texture.Region.X + frame.Rect.X,
+		Y: s.Texture.Region.Y + frame.Rect.Y,
+		W: frame.Rect.W,
+		H: frame.Rect.H,
+	}
+	// 指定了轴心时，父节点位于轴心
+	if frame.HasPivot {
+		pivot := frame.Pivot
+		if s.Texture.IsFlip {
+			pivot = mgl32.Vec2{1.0 - pivot.X(), pivot.Y()}
+		}
+		s.Offset = mgl32.Vec2{-pivot.X() * s.Size.X(), -pivot.Y() * s.Size.Y()}
+	}
+}
+
+// 获取精灵表
+func (s *SpriteAnim) GetSpriteSheet() *core.SpriteSheet {
+	return s.sheet
+}
+
+// 设置精灵表，使用默认动画，大小为帧的原始大小
+func (s *SpriteAnim) SetSpriteSheet(sheet *core.SpriteSheet) {
+	s.sheet = sheet
+	s.clip = nil
+	if sheet == nil {
+		return
+	}
+	// 不沿用之前图片的缩放
+	s.clipSize = mgl32.Vec2{}
+	s.Play("")
+}
+
+// 播放精灵表中的动画，名字为空时播放默认动画，动画不存在返回false
+func (s *SpriteAnim) Play(name string) bool {
+	if s.sheet == nil {
+		return false
+	}
+	clip := s.sheet.GetClip(name)
+	if clip == nil || len(clip.Frames) == 0 {
+		return false
+	}
+	// 动画之间原始大小可能不同，保持缩放比例，大小和锚点偏移跟着变化
+	scale := s.getClipScale()
+	s.clipSize = s.sheet.Frames[clip.Frames[0]].SourceSize
+	s.Size = mgl32.Vec2{s.clipSize.X() * scale.X(), s.clipSize.Y() * scale.Y()}
+	s.SetOffsetByAnchorType(s.AnchorType)
+	s.clip = clip
+	s.totalFrame = float32(len(clip.Frames))
+	s.currentFrame = 0.0
+	s.frameTimer = 0.0
+	s.isFinish = false
+	s.eventFrame = -1
+	s.updateFrame()
+	return true
+}
+
+// 获取当前动画名字
+func (s *SpriteAnim) GetClipName() string {
+	if s.clip == nil {
+		return ""
+	}
+	return s.clip.Name
+}
+
 // 设置纹理
 func (s *SpriteAnim) SetTexture(texture *core.Texture) {
 	s.Texture = texture
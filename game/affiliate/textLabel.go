@@ -37,7 +37,7 @@ func AddTextLabelChild(parent core.IObjectScreen, text string, fontPath string,
 // 清除
 func (t *TextLabel) Clear() {
 	if t.ttfText != nil {
-		ttf.DestroyText(t.ttfText)
+		t.Game().DestroyTTFText(t.ttfText)
 		t.ttfText = nil
 	}
 }
@@ -48,7 +48,10 @@ func (t *TextLabel) Render() {
 	if t.ttfText == nil {
 		return
 	}
-	pos := t.Parent.GetRenderPosition().Add(t.Offset)
+	// 世界视图中只变换位置，文字大小不跟着缩放
+	pos := t.Game().ViewPoint(t.Parent.GetRenderPosition().Add(t.Offset))
+	// 文字直接绘制，先提交之前的命令，保证绘制顺序
+	t.Game().FlushRender()
 	ttf.DrawRendererText(t.ttfText, pos.X(), pos.Y())
 }
 
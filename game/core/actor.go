@@ -10,6 +10,8 @@ type IActor interface {
 	GetSkillPercent() float32
 	// 被伤害
 	TakeDamage(float32)
+	// 获取是否活着
+	GetAlive() bool
 }
 
 // 基础角色
@@ -64,12 +66,12 @@ func (a *Actor) GetMaxSpeed() float32 {
 	return a.MaxSpeed
 }
 
-// 移动
+// 移动，保留上一次tick的位置用于渲染插值
 func (a *Actor) Move(dt float32) {
 	newPos := a.Position.Add(a.Velocity.Mul(dt))
 	a.Position[0] = mgl32.Clamp(newPos.X(), 0.0, a.Game().GetWorldSize().X())
 	a.Position[1] = mgl32.Clamp(newPos.Y(), 0.0, a.Game().GetWorldSize().Y())
-	a.SetPosition(a.Position)
+	a.RenderPosition = a.Game().GetCurrentScene().WorldToScreen(a.Position)
 }
 
 // 获取角色属性
@@ -0,0 +1,186 @@
+package core
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// 素材根目录名，用于查找素材所在的目录
+const AssetRootName = "assets"
+
+// 挂载点
+type assetMount struct {
+	// 挂载前缀，空字符串表示挂载到根目录
+	prefix string
+	// 文件系统
+	fsys fs.FS
+}
+
+// 素材虚拟文件系统，素材路径在所有挂载点中查找，后挂载的优先
+// 可以挂载系统目录、编译进程序的embed.FS和zip素材包，mod或补丁目录挂载在最后用于覆盖单个文件
+type AssetFS struct {
+	// 挂载点，按挂载顺序排列
+	mounts []assetMount
+	// 需要关闭的文件系统，比如zip素材包
+	closers []io.Closer
+	// 可写文件所在目录，存档等文件不放在素材包中
+	baseDir string
+}
+
+var _ fs.FS = (*AssetFS)(nil)
+var _ fs.ReadFileFS = (*AssetFS)(nil)
+var _ fs.StatFS = (*AssetFS)(nil)
+
+// 创建素材文件系统，baseDir为可写文件所在目录
+func CreateAssetFS(baseDir string) *AssetFS {
+	return &AssetFS{
+		mounts:  make([]assetMount, 0),
+		closers: make([]io.Closer, 0),
+		baseDir: baseDir,
+	}
+}
+
+// 创建默认素材文件系统，挂载素材所在的系统目录
+func CreateDefaultAssetFS() *AssetFS {
+	baseDir := FindAssetBaseDir()
+	a := CreateAssetFS(baseDir)
+	a.Mount("", os.DirFS(baseDir))
+	return a
+}
+
+// 查找素材所在目录，优先当前工作目录，其次可执行文件所在目录，都找不到时使用当前工作目录
+func FindAssetBaseDir() string {
+	wd, err := os.Getwd()
+	if err != nil {
+		wd = "."
+	}
+	if isDir(filepath.Join(wd, AssetRootName)) {
+		return wd
+	}
+	if exe, err := os.Executable(); err == nil {
+		exeDir := filepath.Dir(exe)
+		if isDir(filepath.Join(exeDir, AssetRootName)) {
+			return exeDir
+		}
+	}
+	return wd
+}
+
+// 路径是否是目录
+func isDir(dir string) bool {
+	info, err := os.Stat(dir)
+	return err == nil && info.IsDir()
+}
+
+// 清理，关闭所有素材包
+func (a *AssetFS) Clean() {
+	for _, closer := range a.closers {
+		closer.Close()
+	}
+	a.closers = make([]io.Closer, 0)
+	a.mounts = make([]assetMount, 0)
+}
+
+// 挂载文件系统，prefix为挂载前缀，比如"assets/UI"，空字符串表示根目录
+func (a *AssetFS) Mount(prefix string, fsys fs.FS) {
+	a.mounts = append(a.mounts, assetMount{prefix: cleanAssetPath(prefix), fsys: fsys})
+}
+
+// 挂载系统目录
+func (a *AssetFS) MountDir(prefix string, dir string) error {
+	if !isDir(dir) {
+		return fmt.Errorf("mount dir error,%s is not a directory", dir)
+	}
+	a.Mount(prefix, os.DirFS(dir))
+	return nil
+}
+
+// 挂载zip素材包
+func (a *AssetFS) MountZip(prefix string, zipPath string) error {
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return fmt.Errorf("mount zip error,%v", err)
+	}
+	a.closers = append(a.closers, reader)
+	a.Mount(prefix, reader)
+	return nil
+}
+
+// 打开文件，从最后挂载的文件系统开始查找
+func (a *AssetFS) Open(name string) (fs.File, error) {
+	name = cleanAssetPath(name)
+	for i := len(a.mounts) - 1; i >= 0; i-- {
+		rel, ok := a.mounts[i].resolve(name)
+		if !ok {
+			continue
+		}
+		file, err := a.mounts[i].fsys.Open(rel)
+		if err == nil {
+			return file, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// 读取整个文件
+func (a *AssetFS) ReadFile(name string) ([]byte, error) {
+	file, err := a.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+// 获取文件信息
+func (a *AssetFS) Stat(name string) (fs.FileInfo, error) {
+	file, err := a.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return file.Stat()
+}
+
+// 获取可写文件所在目录
+func (a *AssetFS) GetBaseDir() string {
+	return a.baseDir
+}
+
+// 获取可写文件的系统路径，存档、录像等需要写入的文件使用
+func (a *AssetFS) GetWritePath(name string) string {
+	return filepath.Join(a.baseDir, filepath.FromSlash(cleanAssetPath(name)))
+}
+
+// 把挂载后的路径转换为挂载文件系统中的路径
+func (m *assetMount) resolve(name string) (string, bool) {
+	if m.prefix == "." {
+		return name, true
+	}
+	if name == m.prefix {
+		return ".", true
+	}
+	if rel, ok := strings.CutPrefix(name, m.prefix+"/"); ok {
+		return rel, true
+	}
+	return "", false
+}
+
+// 规范化素材路径，io/fs只接受不带"./"和开头"/"的斜杠路径
+func cleanAssetPath(name string) string {
+	name = strings.TrimLeft(path.Clean(filepath.ToSlash(name)), "/")
+	if name == "" {
+		return "."
+	}
+	return name
+}
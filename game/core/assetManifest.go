@@ -0,0 +1,210 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// 素材清单目录，每个场景一个清单
+const AssetManifestDir = "assets/manifest"
+
+// 字体素材，同一个字体不同字号算不同的素材
+type FontAsset struct {
+	// 字体路径
+	Path string `json:"path"`
+	// 字号
+	Size float32 `json:"size"`
+}
+
+// 素材清单
+type AssetManifest struct {
+	// 清单名字，清单文件名去掉扩展名，也是图集的名字
+	Name string `json:"-"`
+	// 纹理
+	Textures []string `json:"textures"`
+	// 打包进图集的图片，加载时打包成一个图集
+	Atlas []string `json:"atlas"`
+	// 音乐
+	Music []string `json:"music"`
+	// 音效
+	Sounds []string `json:"sounds"`
+	// 字体
+	Fonts []FontAsset `json:"fonts"`
+	// 其他文件，只检查是否存在，不预加载
+	Files []string `json:"files"`
+}
+
+// 从文件加载素材清单
+func LoadAssetManifest(fsys fs.FS, filePath string) (*AssetManifest, error) {
+	data, err := fs.ReadFile(fsys, filePath)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &AssetManifest{}
+	if err = json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("load asset manifest error,%s,%v", filePath, err)
+	}
+	manifest.Name = strings.TrimSuffix(path.Base(filePath), path.Ext(filePath))
+	return manifest, nil
+}
+
+// 获取需要预加载的素材数量
+func (m *AssetManifest) GetCount() int {
+	count := len(m.Textures) + len(m.Music) + len(m.Sounds) + len(m.Fonts)
+	// 图集整体算一个
+	if len(m.Atlas) > 0 {
+		count++
+	}
+	return count
+}
+
+// 获取清单中的所有文件路径
+func (m *AssetManifest) GetFiles() []string {
+	files := make([]string, 0, m.GetCount()+len(m.Files))
+	files = append(files, m.Textures...)
+	files = append(files, m.Atlas...)
+	files = append(files, m.Music...)
+	files = append(files, m.Sounds...)
+	for _, font := range m.Fonts {
+		files = append(files, font.Path)
+	}
+	files = append(files, m.Files...)
+	return files
+}
+
+// 检查清单中的文件是否都存在，返回所有缺失的文件
+func (m *AssetManifest) Validate(fsys fs.FS) error {
+	errs := make([]error, 0)
+	for _, file := range m.GetFiles() {
+		if _, err := fs.Stat(fsys, file); err != nil {
+			errs = append(errs, fmt.Errorf("missing asset %q", file))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// 检查目录下所有素材清单，启动时调用，提前报告缺失的文件
+func ValidateAssetManifests(fsys fs.FS, dir string) error {
+	paths, err := fs.Glob(fsys, path.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	errs := make([]error, 0)
+	for _, manifestPath := range paths {
+		manifest, err := LoadAssetManifest(fsys, manifestPath)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err = manifest.Validate(fsys); err != nil {
+			errs = append(errs, fmt.Errorf("%s:\n%v", manifestPath, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// 素材预加载器，每次只加载一个素材，方便加载界面显示进度
+type AssetPreloader struct {
+	// 资源管理器
+	store *AssetStore
+	// 素材持有者，一般是加载完成后进入的场景
+	owner any
+	// 加载任务
+	tasks []func() error
+	// 下一个任务索引
+	index int
+	// 加载失败的错误
+	errs []error
+}
+
+// 创建素材预加载器，加载的素材属于owner，owner为nil表示常驻
+func CreateAssetPreloader(store *AssetStore, manifest *AssetManifest, owner any) *AssetPreloader {
+	p := &AssetPreloader{
+		store: store,
+		owner: owner,
+		tasks: make([]func() error, 0, manifest.GetCount()),
+		errs:  make([]error, 0),
+	}
+	for _, path := range manifest.Textures {
+		p.tasks = append(p.tasks, func() error {
+			_, err := store.GetImage(path)
+			return err
+		})
+	}
+	if len(manifest.Atlas) > 0 {
+		p.tasks = append(p.tasks, func() error {
+			_, err := store.LoadAtlas(manifest.Name, manifest.Atlas)
+			return err
+		})
+	}
+	for _, path := range manifest.Music {
+		p.tasks = append(p.tasks, func() error {
+			_, err := store.GetSound(path, SoundTypeMusic)
+			return err
+		})
+	}
+	for _, path := range manifest.Sounds {
+		p.tasks = append(p.tasks, func() error {
+			_, err := store.GetSound(path, SoundTypeEffect)
+			return err
+		})
+	}
+	for _, font := range manifest.Fonts {
+		p.tasks = append(p.tasks, func() error {
+			_, err := store.GetFont(font.Path, font.Size)
+			return err
+		})
+	}
+	return p
+}
+
+// 加载下一个素材，返回是否全部加载完成，每次tick数量固定，保证录像回放一致
+func (p *AssetPreloader) Step() bool {
+	if p.GetFinish() {
+		return true
+	}
+	if err := p.store.LoadFor(p.owner, p.tasks[p.index]); err != nil {
+		p.errs = append(p.errs, err)
+	}
+	p.index++
+	return p.GetFinish()
+}
+
+// 加载所有剩余素材
+func (p *AssetPreloader) LoadAll() error {
+	for !p.Step() {
+	}
+	return p.GetError()
+}
+
+// 获取是否全部加载完成
+func (p *AssetPreloader) GetFinish() bool {
+	return p.index >= len(p.tasks)
+}
+
+// 获取加载进度，范围[0,1]
+func (p *AssetPreloader) GetProgress() float32 {
+	if len(p.tasks) == 0 {
+		return 1.0
+	}
+	return float32(p.index) / float32(len(p.tasks))
+}
+
+// 获取已经加载的数量
+func (p *AssetPreloader) GetLoadedCount() int {
+	return p.index
+}
+
+// 获取素材总数
+func (p *AssetPreloader) GetTotalCount() int {
+	return len(p.tasks)
+}
+
+// 获取加载失败的错误，没有失败返回nil
+func (p *AssetPreloader) GetError() error {
+	return errors.Join(p.errs...)
+}
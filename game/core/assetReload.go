@@ -0,0 +1,279 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/SunshineZzzz/purego-sdl3/img"
+	"github.com/SunshineZzzz/purego-sdl3/sdl"
+	"github.com/SunshineZzzz/purego-sdl3/ttf"
+)
+
+// 热重载默认检查间隔，单位秒
+const AssetReloadInterval = 0.5
+
+// 文件状态，修改时间或者大小变化就认为文件被修改了
+type assetStamp struct {
+	// 修改时间
+	modTime time.Time
+	// 文件大小
+	size int64
+}
+
+// 获取是否开启热重载
+func (a *AssetStore) GetHotReload() bool {
+	return a.hotReload
+}
+
+// 设置是否开启热重载，开发时使用，开启时监视所有已经加载的素材
+func (a *AssetStore) SetHotReload(hotReload bool) {
+	a.hotReload = hotReload
+	a.reloadTimer = 0.0
+	a.stamps = make(map[string]assetStamp)
+	if !hotReload {
+		return
+	}
+	for filePath := range a.getLoadedFiles() {
+		a.watch(filePath)
+	}
+}
+
+// 获取热重载检查间隔
+func (a *AssetStore) GetReloadInterval() float32 {
+	return a.reloadInterval
+}
+
+// 设置热重载检查间隔
+func (a *AssetStore) SetReloadInterval(interval float32) {
+	a.reloadInterval = max(interval, 0.0)
+}
+
+// 更新热重载计时，elapsed为真实经过的时间，和tick无关，素材重载不影响游戏逻辑
+func (a *AssetStore) Update(elapsed float32) {
+	if !a.hotReload {
+		return
+	}
+	a.reloadTimer += elapsed
+	if a.reloadTimer < a.reloadInterval {
+		return
+	}
+	a.reloadTimer = 0.0
+	a.CheckReload()
+}
+
+// 检查监视的文件，重新加载被修改的素材，返回重新加载的文件数量，失败只打印日志
+func (a *AssetStore) CheckReload() int {
+	loaded := a.getLoadedFiles()
+	count := 0
+	for filePath, stamp := range a.stamps {
+		if _, ok := loaded[filePath]; !ok {
+			// 素材已经卸载
+			delete(a.stamps, filePath)
+			continue
+		}
+		info, err := a.fsys.Stat(filePath)
+		if err != nil {
+			// 编辑器保存时文件可能短暂不存在，下次再检查
+			continue
+		}
+		current := assetStamp{modTime: info.ModTime(), size: info.Size()}
+		if current == stamp {
+			continue
+		}
+		a.stamps[filePath] = current
+		if err = a.Reload(filePath); err != nil {
+			fmt.Printf("hot reload error,%v\n", err)
+			continue
+		}
+		fmt.Printf("hot reload %s\n", filePath)
+		count++
+	}
+	return count
+}
+
+// 重新加载文件对应的所有素材，原来的纹理、声音和文本对象继续有效
+func (a *AssetStore) Reload(filePath string) error {
+	errs := make([]error, 0)
+	if _, ok := a.textures[filePath]; ok {
+		if err := a.reloadImage(filePath); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if name, ok := a.atlasImages[filePath]; ok {
+		if err := a.atlases[name].reloadImage(a.fsys, filePath); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if _, ok := a.sounds[filePath]; ok {
+		if err := a.reloadSound(filePath); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for name, font := range a.fontAssets {
+		if font.Path != filePath {
+			continue
+		}
+		if err := a.reloadFont(name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// 获取热重载替换后的纹理，纹理没有被替换时返回原纹理
+func (a *AssetStore) ResolveTexture(texture *sdl.Texture) *sdl.Texture {
+	if len(a.replacedTextures) == 0 {
+		return texture
+	}
+	for {
+		next, ok := a.replacedTextures[texture]
+		if !ok {
+			return texture
+		}
+		texture = next
+	}
+}
+
+// 开始监视文件
+func (a *AssetStore) watch(filePath string) {
+	if !a.hotReload {
+		return
+	}
+	if _, ok := a.stamps[filePath]; ok {
+		return
+	}
+	info, err := a.fsys.Stat(filePath)
+	if err != nil {
+		return
+	}
+	a.stamps[filePath] = assetStamp{modTime: info.ModTime(), size: info.Size()}
+}
+
+// 获取所有已加载素材的文件路径
+func (a *AssetStore) getLoadedFiles() map[string]struct{} {
+	files := make(map[string]struct{}, len(a.textures)+len(a.sounds)+len(a.fontAssets))
+	for filePath := range a.textures {
+		files[filePath] = struct{}{}
+	}
+	for filePath := range a.sounds {
+		files[filePath] = struct{}{}
+	}
+	for filePath := range a.atlasImages {
+		files[filePath] = struct{}{}
+	}
+	for _, font := range a.fontAssets {
+		files[font.Path] = struct{}{}
+	}
+	return files
+}
+
+// 重新加载纹理，大小不变时直接更新像素，大小变化时创建新纹理，使用时通过ResolveTexture替换
+func (a *AssetStore) reloadImage(filePath string) error {
+	data, err := a.fsys.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("reload image error,%v", err)
+	}
+	surface := loadFromMemory(data, func(ioStream *sdl.IOStream) *sdl.Surface {
+		return img.LoadIO(ioStream, true)
+	})
+	if surface == nil {
+		return fmt.Errorf("reload image error,%s,%s", filePath, sdl.GetError())
+	}
+	defer sdl.DestroySurface(surface)
+
+	old := a.textures[filePath]
+	if surface.W == old.W && surface.H == old.H {
+		converted := sdl.ConvertSurface(surface, old.Format)
+		if converted == nil {
+			return fmt.Errorf("reload image error,%s,%s", filePath, sdl.GetError())
+		}
+		defer sdl.DestroySurface(converted)
+		if !sdl.UpdateTexture(old, nil, converted.Pixels, converted.Pitch) {
+			return fmt.Errorf("reload image error,%s,%s", filePath, sdl.GetError())
+		}
+		return nil
+	}
+
+	texture := sdl.CreateTextureFromSurface(a.sdlRenderer, surface)
+	if texture == nil {
+		return fmt.Errorf("reload image error,%s,%s", filePath, sdl.GetError())
+	}
+	copyTextureState(old, texture)
+	a.textures[filePath] = texture
+	a.replacedTextures[old] = texture
+	a.staleTextures[filePath] = append(a.staleTextures[filePath], old)
+	return nil
+}
+
+// 复制纹理的颜色、透明度和混合模式
+func copyTextureState(src, dst *sdl.Texture) {
+	var r, g, b, alpha float32
+	if sdl.GetTextureColorModFloat(src, &r, &g, &b) {
+		sdl.SetTextureColorModFloat(dst, r, g, b)
+	}
+	if sdl.GetTextureAlphaModFloat(src, &alpha) {
+		sdl.SetTextureAlphaModFloat(dst, alpha)
+	}
+	var blendMode sdl.BlendMode
+	if sdl.GetTextureBlendMode(src, &blendMode) {
+		sdl.SetTextureBlendMode(dst, blendMode)
+	}
+}
+
+// 销毁热重载替换下来的旧纹理
+func (a *AssetStore) destroyStaleTextures(filePath string) {
+	for _, texture := range a.staleTextures[filePath] {
+		delete(a.replacedTextures, texture)
+		sdl.DestroyTexture(texture)
+	}
+	delete(a.staleTextures, filePath)
+}
+
+// 重新加载声音，池子中的每个声音都换成新数据
+func (a *AssetStore) reloadSound(filePath string) error {
+	for _, s := range a.sounds[filePath] {
+		sound, err := NewSound(a.fsys, filePath, s.GetSoundType())
+		if err != nil {
+			return fmt.Errorf("reload sound error,%v", err)
+		}
+		if err = s.reload(sound); err != nil {
+			sound.Close()
+			return fmt.Errorf("reload sound error,%s,%v", filePath, err)
+		}
+	}
+	return nil
+}
+
+// 重新加载字体，用这个字体创建的文本切换到新字体
+func (a *AssetStore) reloadFont(name string) error {
+	asset := a.fontAssets[name]
+	data, err := a.fsys.ReadFile(asset.Path)
+	if err != nil {
+		return fmt.Errorf("reload font error,%v", err)
+	}
+	font := ttf.OpenFontIO(sdl.IOFromConstMem(data), true, asset.Size)
+	if font == nil {
+		return fmt.Errorf("reload font error,%s,%s", asset.Path, sdl.GetError())
+	}
+	for text, fontName := range a.texts {
+		if fontName == name {
+			ttf.SetTextFont(text, font)
+		}
+	}
+	ttf.CloseFont(a.fonts[name])
+	a.fonts[name] = font
+	a.fontData[name] = data
+	return nil
+}
+
+// 记录使用字体创建的文本
+func (a *AssetStore) trackText(text *ttf.Text, fontPath string, fontSize float32) {
+	a.texts[text] = fontPath + strconv.Itoa(int(fontSize))
+}
+
+// 不再记录文本，文本销毁前调用
+func (a *AssetStore) untrackText(text *ttf.Text) {
+	delete(a.texts, text)
+}
@@ -2,6 +2,7 @@ package core
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 
 	"github.com/SunshineZzzz/purego-sdl3/img"
@@ -9,33 +10,286 @@ import (
 	"github.com/SunshineZzzz/purego-sdl3/ttf"
 )
 
+// 素材类型
+type AssetKind int
+
+const (
+	// 纹理
+	AssetKindTexture AssetKind = iota
+	// 声音
+	AssetKindSound
+	// 字体
+	AssetKindFont
+	// 纹理图集
+	AssetKindAtlas
+	// 精灵表
+	AssetKindSpriteSheet
+)
+
+// 素材类型名字
+func (k AssetKind) String() string {
+	switch k {
+	case AssetKindTexture:
+		return "texture"
+	case AssetKindSound:
+		return "sound"
+	case AssetKindFont:
+		return "font"
+	case AssetKindAtlas:
+		return "atlas"
+	case AssetKindSpriteSheet:
+		return "sheet"
+	}
+	return "unknown"
+}
+
+// 素材索引，字体的名字带上字号
+type assetKey struct {
+	// 素材类型
+	kind AssetKind
+	// 素材名字
+	name string
+}
+
+// 素材信息，用于调试报告
+type AssetInfo struct {
+	// 素材类型
+	Kind AssetKind
+	// 素材名字，字体带字号
+	Name string
+	// 占用内存，单位字节，纹理按RGBA估算，声音为解码后的PCM，字体为文件大小
+	Size int
+	// 持有者数量，0表示常驻
+	Owners int
+}
+
 // 资源管理器
+// 素材按持有者计数，持有者一般是场景，场景退出时释放它持有的素材，没有持有者的素材会被卸载
 type AssetStore struct {
 	// SDL渲染器
 	sdlRenderer *sdl.Renderer
+	// 素材文件系统
+	fsys *AssetFS
 	// 存储所有加载的纹理
 	textures map[string]*sdl.Texture
 	// 存储所有加载的字体
 	fonts map[string]*ttf.Font
 	// 存储所有加载的声音
 	sounds map[string][]ISound
+	// 存储所有纹理图集
+	atlases map[string]*TextureAtlas
+	// 打包进图集的图片所在的图集
+	atlasImages map[string]string
+	// 图集每一页白色块的纹理坐标，纯色四边形用
+	atlasWhites map[*sdl.Texture]sdl.FRect
+	// 图集导出目录，为空时不导出
+	atlasDumpDir string
+	// 存储所有加载的精灵表
+	sheets map[string]*SpriteSheet
+	// 没有精灵表的图片，避免每次创建动画都查找文件
+	missingSheets map[string]struct{}
+	// 字体文件数据，SDL_ttf打开字体后还会读取，字体关闭前不能释放
+	fontData map[string][]byte
+	// 字体的路径和字号，热重载时重新打开
+	fontAssets map[string]FontAsset
+	// 使用字体创建的文本，热重载字体时切换到新字体
+	texts map[*ttf.Text]string
+	// 热重载后大小变化的纹理，旧纹理到新纹理
+	replacedTextures map[*sdl.Texture]*sdl.Texture
+	// 热重载替换下来的旧纹理，还可能被引用，素材卸载时再销毁
+	staleTextures map[string][]*sdl.Texture
+	// 是否开启热重载
+	hotReload bool
+	// 热重载检查间隔，单位秒
+	reloadInterval float32
+	// 距离上次检查经过的时间
+	reloadTimer float32
+	// 监视的文件状态
+	stamps map[string]assetStamp
+	// 每个素材的持有者，没有记录的素材是常驻素材
+	owners map[assetKey]map[any]struct{}
+	// 获取当前持有者，返回nil表示常驻
+	ownerFunc func() any
+	// 指定的持有者，预加载时素材属于目标场景
+	ownerOverride any
 }
 
 // 创建资源管理器
-func CreateAssetStore(sdlRenderer *sdl.Renderer) *AssetStore {
+func CreateAssetStore(sdlRenderer *sdl.Renderer, fsys *AssetFS) *AssetStore {
 	return &AssetStore{
 		sdlRenderer: sdlRenderer,
+		fsys:        fsys,
 		textures:    make(map[string]*sdl.Texture),
 		fonts:       make(map[string]*ttf.Font),
 		sounds:      make(map[string][]ISound),
+		atlases:     make(map[string]*TextureAtlas),
+		atlasImages: make(map[string]string),
+		atlasWhites: make(map[*sdl.Texture]sdl.FRect),
+		sheets:      make(map[string]*SpriteSheet),
+		fontData:    make(map[string][]byte),
+		fontAssets:  make(map[string]FontAsset),
+		texts:       make(map[*ttf.Text]string),
+		owners:      make(map[assetKey]map[any]struct{}),
+
+		missingSheets:    make(map[string]struct{}),
+		replacedTextures: make(map[*sdl.Texture]*sdl.Texture),
+		staleTextures:    make(map[string][]*sdl.Texture),
+		reloadInterval:   AssetReloadInterval,
+		stamps:           make(map[string]assetStamp),
 	}
 }
 
-// 清理
+// 设置获取当前持有者的函数
+func (a *AssetStore) SetOwnerFunc(ownerFunc func() any) {
+	a.ownerFunc = ownerFunc
+}
+
+// 以指定持有者执行加载，fn中获取的素材都属于owner
+func (a *AssetStore) LoadFor(owner any, fn func() error) error {
+	last := a.ownerOverride
+	a.ownerOverride = owner
+	defer func() { a.ownerOverride = last }()
+	return fn()
+}
+
+// 获取当前持有者
+func (a *AssetStore) getOwner() any {
+	if a.ownerOverride != nil {
+		return a.ownerOverride
+	}
+	if a.ownerFunc != nil {
+		return a.ownerFunc()
+	}
+	return nil
+}
+
+// 当前持有者持有素材
+func (a *AssetStore) retain(key assetKey) {
+	owner := a.getOwner()
+	if owner == nil {
+		return
+	}
+	owners, ok := a.owners[key]
+	if !ok {
+		owners = make(map[any]struct{})
+		a.owners[key] = owners
+	}
+	owners[owner] = struct{}{}
+}
+
+// 释放持有者持有的所有素材，没有其他持有者的素材会被卸载，返回卸载数量
+func (a *AssetStore) ReleaseOwner(owner any) int {
+	count := 0
+	for key, owners := range a.owners {
+		if _, ok := owners[owner]; !ok {
+			continue
+		}
+		delete(owners, owner)
+		if len(owners) > 0 {
+			continue
+		}
+		delete(a.owners, key)
+		a.unload(key)
+		count++
+	}
+	return count
+}
+
+// 卸载素材
+func (a *AssetStore) unload(key assetKey) {
+	switch key.kind {
+	case AssetKindTexture:
+		if texture, ok := a.textures[key.name]; ok {
+			sdl.DestroyTexture(texture)
+			delete(a.textures, key.name)
+		}
+		a.destroyStaleTextures(key.name)
+	case AssetKindSound:
+		for _, s := range a.sounds[key.name] {
+			s.Close()
+		}
+		delete(a.sounds, key.name)
+	case AssetKindFont:
+		if font, ok := a.fonts[key.name]; ok {
+			ttf.CloseFont(font)
+			delete(a.fonts, key.name)
+			delete(a.fontData, key.name)
+			delete(a.fontAssets, key.name)
+		}
+	case AssetKindSpriteSheet:
+		delete(a.sheets, key.name)
+	case AssetKindAtlas:
+		if atlas, ok := a.atlases[key.name]; ok {
+			for i := 0; i < atlas.GetPageCount(); i++ {
+				delete(a.atlasWhites, atlas.GetPage(i))
+			}
+			atlas.Clean()
+			delete(a.atlases, key.name)
+			for path, name := range a.atlasImages {
+				if name == key.name {
+					delete(a.atlasImages, path)
+				}
+			}
+		}
+	}
+}
+
+// 获取已加载素材的报告，按占用内存从大到小排序
+func (a *AssetStore) GetReport() []AssetInfo {
+	report := make([]AssetInfo, 0, len(a.textures)+len(a.sounds)+len(a.fonts))
+	for name, texture := range a.textures {
+		var w, h float32
+		sdl.GetTextureSize(texture, &w, &h)
+		report = append(report, AssetInfo{Kind: AssetKindTexture, Name: name, Size: int(w) * int(h) * 4,
+			Owners: len(a.owners[assetKey{AssetKindTexture, name}])})
+	}
+	for name, sound := range a.sounds {
+		size := 0
+		for _, s := range sound {
+			size += s.GetMemorySize()
+		}
+		report = append(report, AssetInfo{Kind: AssetKindSound, Name: name, Size: size,
+			Owners: len(a.owners[assetKey{AssetKindSound, name}])})
+	}
+	for name, atlas := range a.atlases {
+		report = append(report, AssetInfo{Kind: AssetKindAtlas, Name: name, Size: atlas.GetMemorySize(),
+			Owners: len(a.owners[assetKey{AssetKindAtlas, name}])})
+	}
+	for name := range a.fonts {
+		report = append(report, AssetInfo{Kind: AssetKindFont, Name: name, Size: len(a.fontData[name]),
+			Owners: len(a.owners[assetKey{AssetKindFont, name}])})
+	}
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].Size != report[j].Size {
+			return report[i].Size > report[j].Size
+		}
+		return report[i].Name < report[j].Name
+	})
+	return report
+}
+
+// 打印已加载素材的报告
+func (a *AssetStore) PrintReport() {
+	report := a.GetReport()
+	total := 0
+	for _, info := range report {
+		total += info.Size
+		fmt.Printf("%-8s %10d  owners: %d  %s\n", info.Kind, info.Size, info.Owners, info.Name)
+	}
+	fmt.Printf("assets: %d, total: %d bytes\n", len(report), total)
+}
+
+// 清理，卸载所有素材
 func (a *AssetStore) Clean() {
 	for _, texture := range a.textures {
 		sdl.DestroyTexture(texture)
 	}
+	for filePath := range a.staleTextures {
+		a.destroyStaleTextures(filePath)
+	}
+	for _, atlas := range a.atlases {
+		atlas.Clean()
+	}
 	for _, font := range a.fonts {
 		ttf.CloseFont(font)
 	}
@@ -47,21 +301,38 @@ func (a *AssetStore) Clean() {
 	a.textures = make(map[string]*sdl.Texture)
 	a.fonts = make(map[string]*ttf.Font)
 	a.sounds = make(map[string][]ISound)
+	a.atlases = make(map[string]*TextureAtlas)
+	a.atlasImages = make(map[string]string)
+	a.atlasWhites = make(map[*sdl.Texture]sdl.FRect)
+	a.sheets = make(map[string]*SpriteSheet)
+	a.missingSheets = make(map[string]struct{})
+	a.fontData = make(map[string][]byte)
+	a.fontAssets = make(map[string]FontAsset)
+	a.texts = make(map[*ttf.Text]string)
+	a.stamps = make(map[string]assetStamp)
+	a.owners = make(map[assetKey]map[any]struct{})
 }
 
 // 载入图片素材
 func (a *AssetStore) loadImage(filePath string) error {
-	texture := img.LoadTexture(a.sdlRenderer, filePath)
+	data, err := a.fsys.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("load image error,%v", err)
+	}
+	texture := loadFromMemory(data, func(ioStream *sdl.IOStream) *sdl.Texture {
+		return img.LoadTextureIO(a.sdlRenderer, ioStream, true)
+	})
 	if texture == nil {
 		return fmt.Errorf("load image error,%s", sdl.GetError())
 	}
 	a.textures[filePath] = texture
+	a.watch(filePath)
 	return nil
 }
 
 // 载入声音素材
 func (a *AssetStore) loadSound(filePath string, soundType SoundType) error {
-	sound, err := NewSound(filePath, soundType)
+	sound, err := NewSound(a.fsys, filePath, soundType)
 	if err != nil {
 		return fmt.Errorf("load sound error,%s", err.Error())
 	}
@@ -69,16 +340,25 @@ func (a *AssetStore) loadSound(filePath string, soundType SoundType) error {
 		a.sounds[filePath] = make([]ISound, 0)
 	}
 	a.sounds[filePath] = append(a.sounds[filePath], sound)
+	a.watch(filePath)
 	return nil
 }
 
 // 载入字体素材
 func (a *AssetStore) loadFont(filePath string, fontSize float32) error {
-	font := ttf.OpenFont(filePath, fontSize)
+	data, err := a.fsys.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("load font error,%v", err)
+	}
+	font := ttf.OpenFontIO(sdl.IOFromConstMem(data), true, fontSize)
 	if font == nil {
 		return fmt.Errorf("load font error,%s", sdl.GetError())
 	}
-	a.fonts[filePath+strconv.Itoa(int(fontSize))] = font
+	name := filePath + strconv.Itoa(int(fontSize))
+	a.fonts[name] = font
+	a.fontData[name] = data
+	a.fontAssets[name] = FontAsset{Path: filePath, Size: fontSize}
+	a.watch(filePath)
 	return nil
 }
 
@@ -86,25 +366,124 @@ func (a *AssetStore) loadFont(filePath string, fontSize float32) error {
 func (a *AssetStore) GetImage(filePath string) (*sdl.Texture, error) {
 	t, ok := a.textures[filePath]
 	if ok {
+		a.retain(assetKey{AssetKindTexture, filePath})
 		return t, nil
 	}
 	err := a.loadImage(filePath)
 	if err != nil {
 		return nil, err
 	}
+	a.retain(assetKey{AssetKindTexture, filePath})
 	return a.textures[filePath], nil
 }
 
+// 打包图片生成纹理图集，同名图集已经存在时直接使用
+func (a *AssetStore) LoadAtlas(name string, paths []string) (*TextureAtlas, error) {
+	if atlas, ok := a.atlases[name]; ok {
+		a.retain(assetKey{AssetKindAtlas, name})
+		return atlas, nil
+	}
+	atlas, err := BuildTextureAtlas(a.sdlRenderer, a.fsys, name, paths, a.atlasDumpDir)
+	if err != nil {
+		return nil, err
+	}
+	a.atlases[name] = atlas
+	for _, path := range atlas.GetImages() {
+		a.atlasImages[path] = name
+		a.watch(path)
+	}
+	for i := 0; i < atlas.GetPageCount(); i++ {
+		if uv, ok := atlas.GetWhiteUV(i); ok {
+			a.atlasWhites[atlas.GetPage(i)] = uv
+		}
+	}
+	a.retain(assetKey{AssetKindAtlas, name})
+	return atlas, nil
+}
+
+// 获取图片在图集中的纹理和区域，图片没有打包进图集时返回false
+func (a *AssetStore) GetAtlasRegion(filePath string) (*sdl.Texture, sdl.FRect, bool) {
+	name, ok := a.atlasImages[filePath]
+	if !ok {
+		return nil, sdl.FRect{}, false
+	}
+	texture, region, ok := a.atlases[name].GetRegion(filePath)
+	if !ok {
+		return nil, sdl.FRect{}, false
+	}
+	a.retain(assetKey{AssetKindAtlas, name})
+	return texture, region.GetRect(), true
+}
+
+// 获取图集页中白色块的纹理坐标，texture不是图集页时返回false
+func (a *AssetStore) GetAtlasWhiteUV(texture *sdl.Texture) (sdl.FRect, bool) {
+	uv, ok := a.atlasWhites[texture]
+	return uv, ok
+}
+
+// 获取图集
+func (a *AssetStore) GetAtlas(name string) *TextureAtlas {
+	return a.atlases[name]
+}
+
+// 获取图集导出目录
+func (a *AssetStore) GetAtlasDumpDir() string {
+	return a.atlasDumpDir
+}
+
+// 设置图集导出目录，之后生成的图集会把每一页和布局导出到该目录，用于检查打包结果
+func (a *AssetStore) SetAtlasDumpDir(dir string) {
+	a.atlasDumpDir = dir
+}
+
+// 获取精灵表
+func (a *AssetStore) GetSpriteSheet(filePath string) (*SpriteSheet, error) {
+	sheet, ok := a.sheets[filePath]
+	if !ok {
+		var err error
+		sheet, err = LoadSpriteSheet(a.fsys, filePath)
+		if err != nil {
+			return nil, err
+		}
+		a.sheets[filePath] = sheet
+	}
+	a.retain(assetKey{AssetKindSpriteSheet, filePath})
+	return sheet, nil
+}
+
+// 查找图片对应的精灵表，没有同名json时返回nil，解析失败只打印日志
+func (a *AssetStore) FindSpriteSheet(imagePath string) *SpriteSheet {
+	sheetPath := GetSpriteSheetPath(imagePath)
+	if _, ok := a.missingSheets[sheetPath]; ok {
+		return nil
+	}
+	if _, ok := a.sheets[sheetPath]; !ok {
+		if _, err := a.fsys.Stat(sheetPath); err != nil {
+			a.missingSheets[sheetPath] = struct{}{}
+			return nil
+		}
+	}
+	sheet, err := a.GetSpriteSheet(sheetPath)
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		a.missingSheets[sheetPath] = struct{}{}
+		return nil
+	}
+	return sheet
+}
+
 // 获取声音素材
 func (a *AssetStore) GetSound(filePath string, soundType SoundType) ([]ISound, error) {
 	sound, ok := a.sounds[filePath]
 	if ok {
+		a.retain(assetKey{AssetKindSound, filePath})
 		return sound, nil
 	}
-	err := a.loadSound(filePath, SoundTypeEffect)
+	err := a.loadSound(filePath, soundType)
 	if err != nil {
 		return nil, err
 	}
+	a.retain(assetKey{AssetKindSound, filePath})
 	return a.sounds[filePath], nil
 }
 
@@ -141,15 +520,29 @@ func (a *AssetStore) ResumeAllSound(soundType SoundType) {
 	}
 }
 
+// 设置某一类声音的音量
+func (a *AssetStore) SetAllSoundVolume(soundType SoundType, volume float32) {
+	for _, sound := range a.sounds {
+		for _, s := range sound {
+			if s.GetSoundType() == soundType {
+				s.SetVolume(volume)
+			}
+		}
+	}
+}
+
 // 获取字体素材
 func (a *AssetStore) GetFont(filePath string, fontSize float32) (*ttf.Font, error) {
-	font, ok := a.fonts[filePath+strconv.Itoa(int(fontSize))]
+	name := filePath + strconv.Itoa(int(fontSize))
+	font, ok := a.fonts[name]
 	if ok {
+		a.retain(assetKey{AssetKindFont, name})
 		return font, nil
 	}
 	err := a.loadFont(filePath, fontSize)
 	if err != nil {
 		return nil, err
 	}
-	return a.fonts[filePath+strconv.Itoa(int(fontSize))], nil
+	a.retain(assetKey{AssetKindFont, name})
+	return a.fonts[name], nil
 }
@@ -0,0 +1,393 @@
+package core
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// 相机边界默认留出的边距，可以看到一点世界外面
+const CameraBoundsMargin = 30.0
+
+// 相机最小缩放，防止除0
+const CameraMinZoom = 0.05
+
+// 相机跟随方式
+type CameraFollowMode int
+
+const (
+	// 按固定比例靠近目标，越远移动越快
+	CameraFollowModeLerp CameraFollowMode = iota
+	// 弹簧，有速度，快速移动时会稍微滞后再追上
+	CameraFollowModeSpring
+)
+
+// 相机，属于场景，跟随目标、限制在边界内并且叠加震屏偏移
+type Camera struct {
+	// 所属场景
+	scene *Scene
+	// 相机位置(世界坐标系)，屏幕左上角，不包括震屏偏移
+	position mgl32.Vec2
+	// 跟随目标，为nil时相机不自动移动
+	target IObjectWorld
+	// 目标上一次tick的位置，目标没有速度时用于计算速度
+	targetPrevPosition mgl32.Vec2
+	// 跟随方式
+	followMode CameraFollowMode
+	// 插值跟随速度，每秒靠近剩余距离的比例系数，0表示直接对准
+	followSpeed float32
+	// 弹簧刚度
+	springStiffness float32
+	// 弹簧阻尼
+	springDamping float32
+	// 弹簧速度
+	springVelocity mgl32.Vec2
+	// 死区大小，目标在屏幕中心的死区内移动时相机不动
+	deadzone mgl32.Vec2
+	// 前瞻时间，相机看向目标移动方向前方，单位秒
+	lookAheadTime float32
+	// 最大前瞻距离
+	lookAheadMax float32
+	// 当前前瞻偏移，平滑变化
+	lookAhead mgl32.Vec2
+	// 是否限制在边界内
+	boundsEnabled bool
+	// 是否使用自定义边界，否则使用世界大小加上边距
+	customBounds bool
+	// 自定义边界，相机位置的最小和最大值
+	boundsMin, boundsMax mgl32.Vec2
+	// 震屏强度，0到1，偏移和强度的平方成正比
+	trauma float32
+	// 每秒衰减的震屏强度
+	traumaDecay float32
+	// 最大震屏偏移，单位像素
+	shakeMaxOffset float32
+	// 震屏频率
+	shakeFrequency float32
+	// 震屏计时，用于计算偏移
+	shakeTimer float32
+	// 当前震屏偏移
+	shakeOffset mgl32.Vec2
+	// 缩放，绕屏幕中心，大于1放大
+	zoom float32
+	// 目标缩放，平滑靠近
+	zoomTarget float32
+	// 缩放速度，每秒靠近剩余差值的比例系数，0表示直接设置
+	zoomSpeed float32
+	// 旋转，单位度，绕屏幕中心顺时针
+	rotation float32
+}
+
+// 创建相机
+func CreateCamera(scene *Scene) *Camera {
+	return &Camera{
+		scene:           scene,
+		followMode:      CameraFollowModeLerp,
+		followSpeed:     0.0,
+		springStiffness: 60.0,
+		springDamping:   2.0 * float32(math.Sqrt(60.0)),
+		boundsEnabled:   true,
+		traumaDecay:     1.5,
+		shakeMaxOffset:  20.0,
+		shakeFrequency:  25.0,
+		zoom:            1.0,
+		zoomTarget:      1.0,
+	}
+}
+
+// 更新，缩放、跟随目标、限制边界、计算震屏偏移
+func (c *Camera) Update(dt float32) {
+	c.updateZoom(dt)
+	if c.target != nil {
+		c.follow(dt)
+	}
+	c.updateShake(dt)
+}
+
+// 获取相机最终位置，包括震屏偏移
+func (c *Camera) GetFinalPosition() mgl32.Vec2 {
+	return c.position.Add(c.shakeOffset)
+}
+
+// 获取震屏偏移
+func (c *Camera) GetShakeOffset() mgl32.Vec2 {
+	return c.shakeOffset
+}
+
+// 获取相机位置，不包括震屏偏移
+func (c *Camera) GetPosition() mgl32.Vec2 {
+	return c.position
+}
+
+// 设置相机位置，瞬移，会限制在边界内
+func (c *Camera) SetPosition(pos mgl32.Vec2) {
+	c.position = c.clamp(pos)
+	c.springVelocity = mgl32.Vec2{0.0, 0.0}
+}
+
+// 对准世界坐标，瞬移
+func (c *Camera) LookAt(pos mgl32.Vec2) {
+	c.SetPosition(pos.Sub(c.scene.Game().GetScreenSize().Mul(0.5)))
+}
+
+// 获取缩放
+func (c *Camera) GetZoom() float32 {
+	return c.zoom
+}
+
+// 设置缩放，立即生效
+func (c *Camera) SetZoom(zoom float32) {
+	c.zoom = max(zoom, CameraMinZoom)
+	c.zoomTarget = c.zoom
+}
+
+// 平滑缩放到目标值，speed为每秒靠近剩余差值的比例系数，0表示立即生效
+func (c *Camera) ZoomTo(zoom, speed float32) {
+	c.zoomTarget = max(zoom, CameraMinZoom)
+	c.zoomSpeed = max(speed, 0.0)
+}
+
+// 获取目标缩放
+func (c *Camera) GetZoomTarget() float32 {
+	return c.zoomTarget
+}
+
+// 获取旋转，单位度
+func (c *Camera) GetRotation() float32 {
+	return c.rotation
+}
+
+// 设置旋转，单位度，绕屏幕中心顺时针
+func (c *Camera) SetRotation(rotation float32) {
+	c.rotation = rotation
+}
+
+// 获取视图变换，相机空间(世界坐标-相机位置)到屏幕
+func (c *Camera) GetView() ViewTransform {
+	return ViewTransform{
+		Center:   c.scene.Game().GetScreenSize().Mul(0.5),
+		Zoom:     c.zoom,
+		Rotation: c.rotation,
+	}
+}
+
+// 获取跟随目标
+func (c *Camera) GetTarget() IObjectWorld {
+	return c.target
+}
+
+// 设置跟随目标，为nil时停止跟随
+func (c *Camera) SetTarget(target IObjectWorld) {
+	c.target = target
+	c.lookAhead = mgl32.Vec2{0.0, 0.0}
+	if target != nil {
+		c.targetPrevPosition = target.GetPosition()
+	}
+}
+
+// 获取跟随方式
+func (c *Camera) GetFollowMode() CameraFollowMode {
+	return c.followMode
+}
+
+// 设置跟随方式
+func (c *Camera) SetFollowMode(mode CameraFollowMode) {
+	c.followMode = mode
+	c.springVelocity = mgl32.Vec2{0.0, 0.0}
+}
+
+// 获取插值跟随速度
+func (c *Camera) GetFollowSpeed() float32 {
+	return c.followSpeed
+}
+
+// 设置插值跟随速度，0表示直接对准
+func (c *Camera) SetFollowSpeed(speed float32) {
+	c.followSpeed = max(speed, 0.0)
+}
+
+// 设置弹簧参数，damping为0时使用临界阻尼，不会来回晃
+func (c *Camera) SetSpring(stiffness, damping float32) {
+	c.springStiffness = max(stiffness, 0.0)
+	if damping <= 0.0 {
+		damping = 2.0 * float32(math.Sqrt(float64(c.springStiffness)))
+	}
+	c.springDamping = damping
+}
+
+// 获取死区大小
+func (c *Camera) GetDeadzone() mgl32.Vec2 {
+	return c.deadzone
+}
+
+// 设置死区大小，以屏幕中心为中心
+func (c *Camera) SetDeadzone(size mgl32.Vec2) {
+	c.deadzone = mgl32.Vec2{max(size.X(), 0.0), max(size.Y(), 0.0)}
+}
+
+// 设置前瞻，time为看向多少秒后的位置，maxDistance为最大前瞻距离
+func (c *Camera) SetLookAhead(time, maxDistance float32) {
+	c.lookAheadTime = max(time, 0.0)
+	c.lookAheadMax = max(maxDistance, 0.0)
+}
+
+// 获取是否限制在边界内
+func (c *Camera) GetBoundsEnabled() bool {
+	return c.boundsEnabled
+}
+
+// 设置是否限制在边界内
+func (c *Camera) SetBoundsEnabled(enabled bool) {
+	c.boundsEnabled = enabled
+}
+
+// 设置自定义边界，min和max是相机位置(屏幕左上角)的范围
+func (c *Camera) SetBounds(min, max mgl32.Vec2) {
+	c.customBounds = true
+	c.boundsMin = min
+	c.boundsMax = max
+}
+
+// 清除自定义边界，使用世界大小加上边距
+func (c *Camera) ClearBounds() {
+	c.customBounds = false
+}
+
+// 获取边界，相机位置的最小和最大值，默认边界考虑缩放后实际看到的范围，旋转时不考虑
+func (c *Camera) GetBounds() (mgl32.Vec2, mgl32.Vec2) {
+	if c.customBounds {
+		return c.boundsMin, c.boundsMax
+	}
+	margin := mgl32.Vec2{CameraBoundsMargin, CameraBoundsMargin}
+	screenSize := c.scene.Game().GetScreenSize()
+	// 相机位置是未缩放时的屏幕左上角，缩放绕屏幕中心，实际看到的范围是屏幕大小/缩放
+	halfScreen := screenSize.Mul(0.5)
+	halfVisible := halfScreen.Mul(1.0 / c.zoom)
+	boundsMin := halfVisible.Sub(margin).Sub(halfScreen)
+	boundsMax := c.scene.WorldSize.Add(margin).Sub(halfVisible).Sub(halfScreen)
+	return boundsMin, boundsMax
+}
+
+// 增加震屏强度，强度在0到1之间
+func (c *Camera) AddTrauma(amount float32) {
+	c.trauma = mgl32.Clamp(c.trauma+amount, 0.0, 1.0)
+}
+
+// 获取震屏强度
+func (c *Camera) GetTrauma() float32 {
+	return c.trauma
+}
+
+// 设置震屏参数，decay为每秒衰减的强度，maxOffset为最大偏移，frequency为震动频率
+func (c *Camera) SetShake(decay, maxOffset, frequency float32) {
+	c.traumaDecay = max(decay, 0.0)
+	c.shakeMaxOffset = max(maxOffset, 0.0)
+	c.shakeFrequency = max(frequency, 0.0)
+}
+
+// 跟随目标
+func (c *Camera) follow(dt float32) {
+	screenSize := c.scene.Game().GetScreenSize()
+	targetPos := c.target.GetPosition()
+	c.updateLookAhead(targetPos, dt)
+	focus := targetPos.Add(c.lookAhead)
+
+	// 目标在死区内时相机中心不动，超出时刚好把目标拉回死区边缘
+	center := c.position.Add(screenSize.Mul(0.5))
+	desired := center
+	for i := range 2 {
+		half := c.deadzone[i] / 2.0
+		if focus[i] > center[i]+half {
+			desired[i] = focus[i] - half
+		} else if focus[i] < center[i]-half {
+			desired[i] = focus[i] + half
+		}
+	}
+	desired = c.clamp(desired.Sub(screenSize.Mul(0.5)))
+
+	switch c.followMode {
+	case CameraFollowModeSpring:
+		force := desired.Sub(c.position).Mul(c.springStiffness).Sub(c.springVelocity.Mul(c.springDamping))
+		c.springVelocity = c.springVelocity.Add(force.Mul(dt))
+		c.position = c.clamp(c.position.Add(c.springVelocity.Mul(dt)))
+	default:
+		if c.followSpeed <= 0.0 {
+			c.position = desired
+			return
+		}
+		// 按指数靠近，与tick频率无关
+		t := 1.0 - float32(math.Exp(float64(-c.followSpeed*dt)))
+		c.position = c.position.Add(desired.Sub(c.position).Mul(t))
+	}
+}
+
+// 更新前瞻偏移，目标是角色时使用角色速度，否则根据位置变化计算
+func (c *Camera) updateLookAhead(targetPos mgl32.Vec2, dt float32) {
+	var velocity mgl32.Vec2
+	if actor, ok := c.target.(interface{ GetVelocity() mgl32.Vec2 }); ok {
+		velocity = actor.GetVelocity()
+	} else if dt > 0.0 {
+		velocity = targetPos.Sub(c.targetPrevPosition).Mul(1.0 / dt)
+	}
+	c.targetPrevPosition = targetPos
+	if c.lookAheadTime <= 0.0 {
+		c.lookAhead = mgl32.Vec2{0.0, 0.0}
+		return
+	}
+	desired := velocity.Mul(c.lookAheadTime)
+	if desired.Len() > c.lookAheadMax {
+		desired = desired.Normalize().Mul(c.lookAheadMax)
+	}
+	// 前瞻平滑变化，转向时不会突然跳
+	t := 1.0 - float32(math.Exp(float64(-4.0*dt)))
+	c.lookAhead = c.lookAhead.Add(desired.Sub(c.lookAhead).Mul(t))
+}
+
+// 更新缩放，按指数靠近目标，与tick频率无关
+func (c *Camera) updateZoom(dt float32) {
+	if c.zoom == c.zoomTarget {
+		return
+	}
+	if c.zoomSpeed <= 0.0 {
+		c.zoom = c.zoomTarget
+		return
+	}
+	t := 1.0 - float32(math.Exp(float64(-c.zoomSpeed*dt)))
+	c.zoom += (c.zoomTarget - c.zoom) * t
+	if math.Abs(float64(c.zoomTarget-c.zoom)) < 0.0001 {
+		c.zoom = c.zoomTarget
+	}
+}
+
+// 限制在边界内，边界比屏幕小时居中
+func (c *Camera) clamp(pos mgl32.Vec2) mgl32.Vec2 {
+	if !c.boundsEnabled {
+		return pos
+	}
+	boundsMin, boundsMax := c.GetBounds()
+	for i := range 2 {
+		if boundsMax[i] < boundsMin[i] {
+			pos[i] = (boundsMin[i] + boundsMax[i]) / 2.0
+			continue
+		}
+		pos[i] = mgl32.Clamp(pos[i], boundsMin[i], boundsMax[i])
+	}
+	return pos
+}
+
+// 更新震屏偏移，强度随时间衰减，偏移用不同频率的正弦叠加，结果确定，回放一致
+func (c *Camera) updateShake(dt float32) {
+	c.trauma = max(c.trauma-c.traumaDecay*dt, 0.0)
+	if c.trauma <= 0.0 {
+		c.shakeTimer = 0.0
+		c.shakeOffset = mgl32.Vec2{0.0, 0.0}
+		return
+	}
+	c.shakeTimer += dt
+	amount := c.trauma * c.trauma * c.shakeMaxOffset
+	t := float64(c.shakeTimer * c.shakeFrequency)
+	c.shakeOffset = mgl32.Vec2{
+		amount * float32(math.Sin(t)*0.6+math.Sin(t*2.3+1.7)*0.4),
+		amount * float32(math.Sin(t*1.3+0.5)*0.6+math.Sin(t*2.9+2.9)*0.4),
+	}
+}
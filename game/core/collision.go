@@ -0,0 +1,265 @@
+package core
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// 碰撞形状(世界坐标系)，圆形和胶囊是线段加半径，矩形、旋转矩形和多边形是凸多边形
+type ColliderShape struct {
+	// 形状类型
+	Type ColliderType
+	// 线段两端，圆形时两端都是圆心，胶囊时是中轴线两端
+	A, B mgl32.Vec2
+	// 半径，圆形和胶囊使用
+	Radius float32
+	// 凸多边形顶点，按顺序排列，矩形、旋转矩形和多边形使用
+	Points []mgl32.Vec2
+}
+
+// 是否是圆形或者胶囊
+func (s *ColliderShape) IsRound() bool {
+	return s.Type == ColliderTypeCircle || s.Type == ColliderTypeCapsule
+}
+
+// 获取包围盒
+func (s *ColliderShape) GetBounds() (mgl32.Vec2, mgl32.Vec2) {
+	if s.IsRound() {
+		radius := mgl32.Vec2{s.Radius, s.Radius}
+		boundsMin := mgl32.Vec2{min(s.A.X(), s.B.X()), min(s.A.Y(), s.B.Y())}
+		boundsMax := mgl32.Vec2{max(s.A.X(), s.B.X()), max(s.A.Y(), s.B.Y())}
+		return boundsMin.Sub(radius), boundsMax.Add(radius)
+	}
+	if len(s.Points) == 0 {
+		return mgl32.Vec2{}, mgl32.Vec2{}
+	}
+	boundsMin, boundsMax := s.Points[0], s.Points[0]
+	for _, p := range s.Points[1:] {
+		boundsMin = mgl32.Vec2{min(boundsMin.X(), p.X()), min(boundsMin.Y(), p.Y())}
+		boundsMax = mgl32.Vec2{max(boundsMax.X(), p.X()), max(boundsMax.Y(), p.Y())}
+	}
+	return boundsMin, boundsMax
+}
+
+// 检测两个形状是否相交，接触不算相交
+func CollideShapes(a, b *ColliderShape) bool {
+	switch {
+	case a.IsRound() && b.IsRound():
+		// 两条线段的距离小于半径和
+		return segmentSegmentDistance(a.A, a.B, b.A, b.B) < a.Radius+b.Radius
+	case a.IsRound():
+		return segmentPolygonDistance(a.A, a.B, b.Points) < a.Radius
+	case b.IsRound():
+		return segmentPolygonDistance(b.A, b.B, a.Points) < b.Radius
+	}
+	return polygonsOverlap(a.Points, b.Points)
+}
+
+// 点到线段的距离
+func pointSegmentDistance(p, a, b mgl32.Vec2) float32 {
+	ab := b.Sub(a)
+	lengthSqr := ab.Dot(ab)
+	if lengthSqr <= 0.0 {
+		return p.Sub(a).Len()
+	}
+	t := mgl32.Clamp(p.Sub(a).Dot(ab)/lengthSqr, 0.0, 1.0)
+	return p.Sub(a.Add(ab.Mul(t))).Len()
+}
+
+// 二维叉积
+func cross2D(a, b mgl32.Vec2) float32 {
+	return a.X()*b.Y() - a.Y()*b.X()
+}
+
+// 两条线段是否相交，包括端点接触
+func segmentsIntersect(a, b, c, d mgl32.Vec2) bool {
+	d1 := cross2D(b.Sub(a), c.Sub(a))
+	d2 := cross2D(b.Sub(a), d.Sub(a))
+	d3 := cross2D(d.Sub(c), a.Sub(c))
+	d4 := cross2D(d.Sub(c), b.Sub(c))
+	if ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0)) {
+		return true
+	}
+	// 共线或者端点在另一条线段上
+	return (d1 == 0 && pointSegmentDistance(c, a, b) == 0) ||
+		(d2 == 0 && pointSegmentDistance(d, a, b) == 0) ||
+		(d3 == 0 && pointSegmentDistance(a, c, d) == 0) ||
+		(d4 == 0 && pointSegmentDistance(b, c, d) == 0)
+}
+
+// 两条线段的距离，线段退化成点时是点到线段的距离
+func segmentSegmentDistance(a, b, c, d mgl32.Vec2) float32 {
+	if a != b && c != d && segmentsIntersect(a, b, c, d) {
+		return 0.0
+	}
+	return min(
+		pointSegmentDistance(a, c, d),
+		pointSegmentDistance(b, c, d),
+		pointSegmentDistance(c, a, b),
+		pointSegmentDistance(d, a, b),
+	)
+}
+
+// 点是否在凸多边形内，两种顶点顺序都可以
+func pointInPolygon(p mgl32.Vec2, points []mgl32.Vec2) bool {
+	if len(points) < 3 {
+		return false
+	}
+	sign := float32(0.0)
+	for i := range points {
+		c := cross2D(points[(i+1)%len(points)].Sub(points[i]), p.Sub(points[i]))
+		if c == 0 {
+			continue
+		}
+		if sign == 0 {
+			sign = c
+		} else if (c > 0) != (sign > 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// 线段到凸多边形的距离，线段在多边形内或者相交时为0
+func segmentPolygonDistance(a, b mgl32.Vec2, points []mgl32.Vec2) float32 {
+	if len(points) == 0 {
+		return float32(math.Inf(1))
+	}
+	if pointInPolygon(a, points) {
+		return 0.0
+	}
+	distance := float32(math.Inf(1))
+	for i := range points {
+		distance = min(distance, segmentSegmentDistance(a, b, points[i], points[(i+1)%len(points)]))
+	}
+	return distance
+}
+
+// 两个凸多边形是否重叠，分离轴定理，任意一条边的法线上投影不重叠就是分离的
+func polygonsOverlap(a, b []mgl32.Vec2) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return false
+	}
+	return !hasSeparatingAxis(a, b) && !hasSeparatingAxis(b, a)
+}
+
+// 以多边形a的边法线为轴，检查是否能分开a和b
+func hasSeparatingAxis(a, b []mgl32.Vec2) bool {
+	for i := range a {
+		edge := a[(i+1)%len(a)].Sub(a[i])
+		axis := mgl32.Vec2{-edge.Y(), edge.X()}
+		if axis.Len() == 0 {
+			continue
+		}
+		minA, maxA := projectPolygon(a, axis)
+		minB, maxB := projectPolygon(b, axis)
+		if maxA <= minB || maxB <= minA {
+			return true
+		}
+	}
+	return false
+}
+
+// 多边形在轴上的投影范围
+func projectPolygon(points []mgl32.Vec2, axis mgl32.Vec2) (float32, float32) {
+	lo := points[0].Dot(axis)
+	hi := lo
+	for _, p := range points[1:] {
+		d := p.Dot(axis)
+		lo = min(lo, d)
+		hi = max(hi, d)
+	}
+	return lo, hi
+}
+
+// 点到形状的距离，点在形状内时为0
+func ShapeDistance(s *ColliderShape, p mgl32.Vec2) float32 {
+	if s.IsRound() {
+		return max(pointSegmentDistance(p, s.A, s.B)-s.Radius, 0.0)
+	}
+	if pointInPolygon(p, s.Points) {
+		return 0.0
+	}
+	distance := float32(math.Inf(1))
+	for i := range s.Points {
+		distance = min(distance, pointSegmentDistance(p, s.Points[i], s.Points[(i+1)%len(s.Points)]))
+	}
+	return distance
+}
+
+// 射线和形状求交，direction必须是单位向量，返回命中距离，起点在形状内时距离为0
+func RaycastShape(s *ColliderShape, origin, direction mgl32.Vec2, maxDistance float32) (float32, bool) {
+	best := float32(math.Inf(1))
+	if s.IsRound() {
+		if pointSegmentDistance(origin, s.A, s.B) <= s.Radius {
+			return 0.0, true
+		}
+		if t, ok := raycastCircle(origin, direction, s.A, s.Radius); ok {
+			best = min(best, t)
+		}
+		if s.B != s.A {
+			if t, ok := raycastCircle(origin, direction, s.B, s.Radius); ok {
+				best = min(best, t)
+			}
+			// 胶囊中间的矩形部分
+			axis := s.B.Sub(s.A).Normalize()
+			normal := mgl32.Vec2{-axis.Y(), axis.X()}.Mul(s.Radius)
+			side := []mgl32.Vec2{s.A.Add(normal), s.B.Add(normal), s.B.Sub(normal), s.A.Sub(normal)}
+			if t, ok := raycastPolygon(origin, direction, side); ok {
+				best = min(best, t)
+			}
+		}
+	} else {
+		if pointInPolygon(origin, s.Points) {
+			return 0.0, true
+		}
+		if t, ok := raycastPolygon(origin, direction, s.Points); ok {
+			best = t
+		}
+	}
+	// 没有命中时best还是无穷大，maxDistance是无穷大时也要返回false
+	if math.IsInf(float64(best), 1) || best > maxDistance {
+		return 0.0, false
+	}
+	return best, true
+}
+
+// 射线和圆求交
+func raycastCircle(origin, direction, center mgl32.Vec2, radius float32) (float32, bool) {
+	m := origin.Sub(center)
+	b := m.Dot(direction)
+	c := m.Dot(m) - radius*radius
+	// 起点在圆外并且背离圆心
+	if c > 0.0 && b > 0.0 {
+		return 0.0, false
+	}
+	discriminant := b*b - c
+	if discriminant < 0.0 {
+		return 0.0, false
+	}
+	return max(-b-float32(math.Sqrt(float64(discriminant))), 0.0), true
+}
+
+// 射线和多边形的边求交，取最近的交点
+func raycastPolygon(origin, direction mgl32.Vec2, points []mgl32.Vec2) (float32, bool) {
+	best := float32(math.Inf(1))
+	hit := false
+	for i := range points {
+		p := points[i]
+		edge := points[(i+1)%len(points)].Sub(p)
+		denominator := cross2D(direction, edge)
+		// 平行时不相交
+		if denominator == 0.0 {
+			continue
+		}
+		toEdge := p.Sub(origin)
+		t := cross2D(toEdge, edge) / denominator
+		u := cross2D(toEdge, direction) / denominator
+		if t >= 0.0 && u >= 0.0 && u <= 1.0 && t < best {
+			best = t
+			hit = true
+		}
+	}
+	return best, hit
+}
@@ -0,0 +1,114 @@
+package core
+
+// 碰撞开始回调，碰撞器的父节点实现，第一次检测到重叠时调用
+type ICollisionEnter interface {
+	// collider是自己的碰撞器，other是对方的碰撞器
+	OnCollisionEnter(collider, other IObjectCollider)
+}
+
+// 碰撞持续回调，碰撞器的父节点实现，开始之后每次tick仍然重叠时调用
+type ICollisionStay interface {
+	// collider是自己的碰撞器，other是对方的碰撞器
+	OnCollisionStay(collider, other IObjectCollider)
+}
+
+// 碰撞结束回调，碰撞器的父节点实现，不再重叠或者对方被移除时调用
+type ICollisionExit interface {
+	// collider是自己的碰撞器，other是对方的碰撞器
+	OnCollisionExit(collider, other IObjectCollider)
+}
+
+// 碰撞接触，collider的父节点关心other
+type collisionContact struct {
+	// 自己的碰撞器
+	collider IObjectCollider
+	// 对方的碰撞器
+	other IObjectCollider
+}
+
+// 碰撞系统，属于场景，每次tick在世界对象更新完后检测空间哈希里的碰撞器，给父节点派发开始、持续和结束回调
+// 碰撞器的掩码包含对方的层时才通知自己的父节点，触发器只通知自己，不会通知对方
+type CollisionSystem struct {
+	// 空间哈希
+	spatialHash *SpatialHash
+	// 这次tick的接触，按检测顺序
+	contacts []collisionContact
+	// 上一次tick的接触，按检测顺序
+	prevContacts []collisionContact
+	// 这次tick的接触集合
+	contactSet map[collisionContact]bool
+	// 上一次tick的接触集合
+	prevContactSet map[collisionContact]bool
+}
+
+// 创建碰撞系统
+func CreateCollisionSystem(spatialHash *SpatialHash) *CollisionSystem {
+	return &CollisionSystem{
+		spatialHash:    spatialHash,
+		contactSet:     make(map[collisionContact]bool),
+		prevContactSet: make(map[collisionContact]bool),
+	}
+}
+
+// 更新，检测接触并派发回调，场景在空间哈希刷新后调用
+func (c *CollisionSystem) Update() {
+	c.detect()
+	for _, contact := range c.contacts {
+		if c.prevContactSet[contact] {
+			if handler, ok := contact.collider.GetParent().(ICollisionStay); ok {
+				handler.OnCollisionStay(contact.collider, contact.other)
+			}
+		} else if handler, ok := contact.collider.GetParent().(ICollisionEnter); ok {
+			handler.OnCollisionEnter(contact.collider, contact.other)
+		}
+	}
+	for _, contact := range c.prevContacts {
+		if c.contactSet[contact] {
+			continue
+		}
+		// 自己已经被移除时不再通知
+		if !isColliderAlive(contact.collider) {
+			continue
+		}
+		if handler, ok := contact.collider.GetParent().(ICollisionExit); ok {
+			handler.OnCollisionExit(contact.collider, contact.other)
+		}
+	}
+	// 这次的接触变成上一次的，复用内存
+	c.contacts, c.prevContacts = c.prevContacts, c.contacts
+	c.contactSet, c.prevContactSet = c.prevContactSet, c.contactSet
+	clear(c.contacts)
+	c.contacts = c.contacts[:0]
+	clear(c.contactSet)
+}
+
+// 清空接触，不派发回调
+func (c *CollisionSystem) Clear() {
+	clear(c.contacts)
+	c.contacts = c.contacts[:0]
+	clear(c.prevContacts)
+	c.prevContacts = c.prevContacts[:0]
+	clear(c.contactSet)
+	clear(c.prevContactSet)
+}
+
+// 检测这次tick的接触
+func (c *CollisionSystem) detect() {
+	c.spatialHash.ForEach(func(collider IObjectCollider) {
+		mask := collider.GetCollisionMask()
+		if mask == CollisionLayerNone || !isColliderAlive(collider) {
+			return
+		}
+		for _, other := range c.spatialHash.OverlapCollider(collider, LayerFilter(mask)) {
+			contact := collisionContact{collider: collider, other: other}
+			c.contacts = append(c.contacts, contact)
+			c.contactSet[contact] = true
+		}
+	})
+}
+
+// 碰撞器和父节点是否都是激活的
+func isColliderAlive(collider IObjectCollider) bool {
+	parent := collider.GetParent()
+	return collider.GetActive() && parent != nil && parent.GetActive() && !parent.GetNeedRemove()
+}
@@ -0,0 +1,142 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// 记录碰撞回调的父节点
+type testHandler struct {
+	testOwner
+	// 回调记录
+	events *[]string
+}
+
+var _ ICollisionEnter = (*testHandler)(nil)
+var _ ICollisionStay = (*testHandler)(nil)
+var _ ICollisionExit = (*testHandler)(nil)
+
+// 碰撞开始
+func (h *testHandler) OnCollisionEnter(collider, other IObjectCollider) {
+	h.record("enter", other)
+}
+
+// 碰撞持续
+func (h *testHandler) OnCollisionStay(collider, other IObjectCollider) {
+	h.record("stay", other)
+}
+
+// 碰撞结束
+func (h *testHandler) OnCollisionExit(collider, other IObjectCollider) {
+	h.record("exit", other)
+}
+
+// 记录一次回调
+func (h *testHandler) record(kind string, other IObjectCollider) {
+	*h.events = append(*h.events, h.name+" "+kind+" "+ownerName(other))
+}
+
+// 创建父节点会记录回调的碰撞器
+func newTestHandlerCollider(name string, pos mgl32.Vec2, layer, mask CollisionLayer, events *[]string) *ObjectCollider {
+	owner := &testHandler{events: events}
+	owner.name = name
+	owner.Init()
+	owner.Position = pos
+	collider := &ObjectCollider{}
+	collider.Init()
+	collider.SetAnchorType(AnchorTypeCenter)
+	collider.SetSize(mgl32.Vec2{10.0, 10.0})
+	collider.SetParent(owner)
+	collider.SetCollisionLayer(layer)
+	collider.SetCollisionMask(mask)
+	return collider
+}
+
+// 模拟一次tick，注册所有碰撞器，刷新并检测
+func stepCollision(h *SpatialHash, system *CollisionSystem, colliders ...*ObjectCollider) {
+	for _, collider := range colliders {
+		if collider.GetParent().GetActive() {
+			h.Insert(collider)
+		}
+	}
+	h.Refresh()
+	system.Update()
+}
+
+func TestCollisionSystemEnterStayExit(t *testing.T) {
+	var events []string
+	enemy := newTestHandlerCollider("enemy", mgl32.Vec2{0.0, 0.0}, CollisionLayerEnemy, CollisionLayerPlayer, &events)
+	player := newTestHandlerCollider("player", mgl32.Vec2{100.0, 0.0}, CollisionLayerPlayer, CollisionLayerNone, &events)
+	h := CreateSpatialHash(32.0)
+	system := CreateCollisionSystem(h)
+	playerOwner := player.GetParent().(*testHandler)
+
+	steps := []struct {
+		name string
+		move mgl32.Vec2
+		want []string
+	}{
+		{"apart", mgl32.Vec2{100.0, 0.0}, nil},
+		{"enter", mgl32.Vec2{5.0, 0.0}, []string{"enemy enter player"}},
+		{"stay", mgl32.Vec2{6.0, 0.0}, []string{"enemy stay player"}},
+		{"exit", mgl32.Vec2{50.0, 0.0}, []string{"enemy exit player"}},
+		{"apart again", mgl32.Vec2{60.0, 0.0}, nil},
+	}
+	for _, step := range steps {
+		events = nil
+		playerOwner.Position = step.move
+		stepCollision(h, system, enemy, player)
+		if !reflect.DeepEqual(events, step.want) {
+			t.Errorf("%s: events %v, want %v", step.name, events, step.want)
+		}
+	}
+}
+
+func TestCollisionSystemMaskAndTrigger(t *testing.T) {
+	var events []string
+	// 互相关心的两个碰撞器都收到回调
+	a := newTestHandlerCollider("a", mgl32.Vec2{0.0, 0.0}, CollisionLayerDefault, CollisionLayerDefault, &events)
+	b := newTestHandlerCollider("b", mgl32.Vec2{5.0, 0.0}, CollisionLayerDefault, CollisionLayerDefault|CollisionLayerSpell, &events)
+	// 不在掩码内的层不通知
+	pickup := newTestHandlerCollider("pickup", mgl32.Vec2{2.0, 0.0}, CollisionLayerPickup, CollisionLayerNone, &events)
+	// 触发器只通知自己，b的掩码包含法术层也不会收到
+	spell := newTestHandlerCollider("spell", mgl32.Vec2{3.0, 0.0}, CollisionLayerSpell, CollisionLayerDefault, &events)
+	spell.SetTrigger(true)
+	h := CreateSpatialHash(32.0)
+	system := CreateCollisionSystem(h)
+
+	stepCollision(h, system, a, b, pickup, spell)
+	want := []string{"a enter b", "b enter a", "spell enter a", "spell enter b"}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("events %v, want %v", events, want)
+	}
+}
+
+func TestCollisionSystemRemovedOwner(t *testing.T) {
+	var events []string
+	a := newTestHandlerCollider("a", mgl32.Vec2{0.0, 0.0}, CollisionLayerDefault, CollisionLayerDefault, &events)
+	b := newTestHandlerCollider("b", mgl32.Vec2{5.0, 0.0}, CollisionLayerDefault, CollisionLayerDefault, &events)
+	h := CreateSpatialHash(32.0)
+	system := CreateCollisionSystem(h)
+	stepCollision(h, system, a, b)
+
+	// b被移除后，a收到结束回调，b不再收到回调
+	events = nil
+	b.GetParent().SetActive(false)
+	stepCollision(h, system, a, b)
+	if want := []string{"a exit b"}; !reflect.DeepEqual(events, want) {
+		t.Errorf("events %v, want %v", events, want)
+	}
+
+	// 清空后不派发回调
+	events = nil
+	b.GetParent().SetActive(true)
+	stepCollision(h, system, a, b)
+	system.Clear()
+	stepCollision(h, system, a, b)
+	if want := []string{"a enter b", "b enter a", "a enter b", "b enter a"}; !reflect.DeepEqual(events, want) {
+		t.Errorf("events %v, want %v", events, want)
+	}
+}
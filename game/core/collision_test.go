@@ -0,0 +1,177 @@
+package core
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// 创建圆形
+func circleShape(center mgl32.Vec2, radius float32) ColliderShape {
+	return ColliderShape{Type: ColliderTypeCircle, A: center, B: center, Radius: radius}
+}
+
+// 创建胶囊
+func capsuleShape(a, b mgl32.Vec2, radius float32) ColliderShape {
+	return ColliderShape{Type: ColliderTypeCapsule, A: a, B: b, Radius: radius}
+}
+
+// 创建和坐标轴对齐的矩形
+func rectShape(pos, size mgl32.Vec2) ColliderShape {
+	return ColliderShape{Type: ColliderTypeRect, Points: []mgl32.Vec2{
+		pos,
+		{pos.X() + size.X(), pos.Y()},
+		pos.Add(size),
+		{pos.X(), pos.Y() + size.Y()},
+	}}
+}
+
+func TestRaycastShape(t *testing.T) {
+	inf := float32(math.Inf(1))
+	right := mgl32.Vec2{1.0, 0.0}
+	tests := []struct {
+		name        string
+		shape       ColliderShape
+		origin      mgl32.Vec2
+		direction   mgl32.Vec2
+		maxDistance float32
+		hit         bool
+		distance    float32
+	}{
+		{"circle hit", circleShape(mgl32.Vec2{10.0, 0.0}, 2.0), mgl32.Vec2{}, right, 100.0, true, 8.0},
+		{"circle behind", circleShape(mgl32.Vec2{-10.0, 0.0}, 2.0), mgl32.Vec2{}, right, 100.0, false, 0.0},
+		{"circle miss above", circleShape(mgl32.Vec2{10.0, 5.0}, 2.0), mgl32.Vec2{}, right, 100.0, false, 0.0},
+		{"circle too far", circleShape(mgl32.Vec2{10.0, 0.0}, 2.0), mgl32.Vec2{}, right, 5.0, false, 0.0},
+		{"circle inside", circleShape(mgl32.Vec2{1.0, 0.0}, 2.0), mgl32.Vec2{}, right, 100.0, true, 0.0},
+		{"circle inf hit", circleShape(mgl32.Vec2{10.0, 0.0}, 2.0), mgl32.Vec2{}, right, inf, true, 8.0},
+		{"circle inf miss", circleShape(mgl32.Vec2{10.0, 5.0}, 2.0), mgl32.Vec2{}, right, inf, false, 0.0},
+		{"capsule side", capsuleShape(mgl32.Vec2{10.0, -5.0}, mgl32.Vec2{10.0, 5.0}, 1.0), mgl32.Vec2{}, right, 100.0, true, 9.0},
+		{"capsule cap", capsuleShape(mgl32.Vec2{5.0, 0.0}, mgl32.Vec2{15.0, 0.0}, 1.0), mgl32.Vec2{}, right, 100.0, true, 4.0},
+		{"capsule inf miss", capsuleShape(mgl32.Vec2{5.0, 3.0}, mgl32.Vec2{15.0, 3.0}, 1.0), mgl32.Vec2{}, right, inf, false, 0.0},
+		{"rect hit", rectShape(mgl32.Vec2{10.0, -2.0}, mgl32.Vec2{4.0, 4.0}), mgl32.Vec2{}, right, 100.0, true, 10.0},
+		{"rect diagonal", rectShape(mgl32.Vec2{10.0, 10.0}, mgl32.Vec2{4.0, 4.0}), mgl32.Vec2{}, mgl32.Vec2{1.0, 1.0}.Normalize(), 100.0, true, 10.0 * float32(math.Sqrt2)},
+		{"rect inside", rectShape(mgl32.Vec2{-2.0, -2.0}, mgl32.Vec2{4.0, 4.0}), mgl32.Vec2{}, right, 100.0, true, 0.0},
+		{"rect parallel miss", rectShape(mgl32.Vec2{10.0, 1.0}, mgl32.Vec2{4.0, 4.0}), mgl32.Vec2{}, right, 100.0, false, 0.0},
+		{"rect inf miss", rectShape(mgl32.Vec2{10.0, 10.0}, mgl32.Vec2{4.0, 4.0}), mgl32.Vec2{}, right, inf, false, 0.0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			distance, hit := RaycastShape(&tt.shape, tt.origin, tt.direction, tt.maxDistance)
+			if hit != tt.hit {
+				t.Fatalf("hit = %v, want %v (distance %v)", hit, tt.hit, distance)
+			}
+			if hit && math.Abs(float64(distance-tt.distance)) > 1e-4 {
+				t.Errorf("distance = %v, want %v", distance, tt.distance)
+			}
+		})
+	}
+}
+
+func TestShapeDistance(t *testing.T) {
+	tests := []struct {
+		name     string
+		shape    ColliderShape
+		point    mgl32.Vec2
+		distance float32
+	}{
+		{"circle outside", circleShape(mgl32.Vec2{}, 2.0), mgl32.Vec2{5.0, 0.0}, 3.0},
+		{"circle inside", circleShape(mgl32.Vec2{}, 2.0), mgl32.Vec2{1.0, 0.0}, 0.0},
+		{"capsule side", capsuleShape(mgl32.Vec2{0.0, -5.0}, mgl32.Vec2{0.0, 5.0}, 1.0), mgl32.Vec2{4.0, 2.0}, 3.0},
+		{"rect edge", rectShape(mgl32.Vec2{}, mgl32.Vec2{4.0, 4.0}), mgl32.Vec2{6.0, 2.0}, 2.0},
+		{"rect corner", rectShape(mgl32.Vec2{}, mgl32.Vec2{4.0, 4.0}), mgl32.Vec2{7.0, 8.0}, 5.0},
+		{"rect inside", rectShape(mgl32.Vec2{}, mgl32.Vec2{4.0, 4.0}), mgl32.Vec2{1.0, 1.0}, 0.0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if distance := ShapeDistance(&tt.shape, tt.point); math.Abs(float64(distance-tt.distance)) > 1e-4 {
+				t.Errorf("distance = %v, want %v", distance, tt.distance)
+			}
+		})
+	}
+}
+
+// 创建凸多边形
+func polygonShape(points ...mgl32.Vec2) ColliderShape {
+	return ColliderShape{Type: ColliderTypePolygon, Points: points}
+}
+
+func TestCollideShapes(t *testing.T) {
+	// 绕原点旋转45度、边长2的正方形
+	diamond := polygonShape(
+		mgl32.Vec2{0.0, -float32(math.Sqrt2)},
+		mgl32.Vec2{float32(math.Sqrt2), 0.0},
+		mgl32.Vec2{0.0, float32(math.Sqrt2)},
+		mgl32.Vec2{-float32(math.Sqrt2), 0.0},
+	)
+	triangle := polygonShape(mgl32.Vec2{0.0, 0.0}, mgl32.Vec2{4.0, 0.0}, mgl32.Vec2{0.0, 4.0})
+	tests := []struct {
+		name    string
+		a, b    ColliderShape
+		collide bool
+	}{
+		{"circles overlap", circleShape(mgl32.Vec2{}, 2.0), circleShape(mgl32.Vec2{3.0, 0.0}, 2.0), true},
+		{"circles apart", circleShape(mgl32.Vec2{}, 2.0), circleShape(mgl32.Vec2{5.0, 0.0}, 2.0), false},
+		{"circles touching", circleShape(mgl32.Vec2{}, 2.0), circleShape(mgl32.Vec2{4.0, 0.0}, 2.0), false},
+		{"capsules crossing", capsuleShape(mgl32.Vec2{-5.0, 0.0}, mgl32.Vec2{5.0, 0.0}, 0.5), capsuleShape(mgl32.Vec2{0.0, -5.0}, mgl32.Vec2{0.0, 5.0}, 0.5), true},
+		{"capsules parallel", capsuleShape(mgl32.Vec2{-5.0, 0.0}, mgl32.Vec2{5.0, 0.0}, 1.0), capsuleShape(mgl32.Vec2{-5.0, 3.0}, mgl32.Vec2{5.0, 3.0}, 1.0), false},
+		{"capsule end circle", capsuleShape(mgl32.Vec2{-5.0, 0.0}, mgl32.Vec2{5.0, 0.0}, 1.0), circleShape(mgl32.Vec2{6.5, 0.0}, 1.0), true},
+		{"rects overlap", rectShape(mgl32.Vec2{}, mgl32.Vec2{4.0, 4.0}), rectShape(mgl32.Vec2{3.0, 3.0}, mgl32.Vec2{4.0, 4.0}), true},
+		{"rects touching", rectShape(mgl32.Vec2{}, mgl32.Vec2{4.0, 4.0}), rectShape(mgl32.Vec2{4.0, 0.0}, mgl32.Vec2{4.0, 4.0}), false},
+		{"rect contains rect", rectShape(mgl32.Vec2{}, mgl32.Vec2{10.0, 10.0}), rectShape(mgl32.Vec2{4.0, 4.0}, mgl32.Vec2{1.0, 1.0}), true},
+		{"diamond rect corner gap", diamond, rectShape(mgl32.Vec2{0.8, 0.8}, mgl32.Vec2{2.0, 2.0}), false},
+		{"diamond rect overlap", diamond, rectShape(mgl32.Vec2{0.5, 0.5}, mgl32.Vec2{2.0, 2.0}), true},
+		{"triangle hypotenuse gap", triangle, rectShape(mgl32.Vec2{2.5, 2.5}, mgl32.Vec2{2.0, 2.0}), false},
+		{"triangle overlap", triangle, rectShape(mgl32.Vec2{1.0, 1.0}, mgl32.Vec2{2.0, 2.0}), true},
+		{"circle rect edge", circleShape(mgl32.Vec2{5.0, 2.0}, 1.5), rectShape(mgl32.Vec2{}, mgl32.Vec2{4.0, 4.0}), true},
+		{"circle rect corner gap", circleShape(mgl32.Vec2{5.0, 5.0}, 1.0), rectShape(mgl32.Vec2{}, mgl32.Vec2{4.0, 4.0}), false},
+		{"circle inside rect", circleShape(mgl32.Vec2{2.0, 2.0}, 0.5), rectShape(mgl32.Vec2{}, mgl32.Vec2{4.0, 4.0}), true},
+		{"capsule through rect", capsuleShape(mgl32.Vec2{-10.0, 2.0}, mgl32.Vec2{10.0, 2.0}, 0.1), rectShape(mgl32.Vec2{}, mgl32.Vec2{4.0, 4.0}), true},
+		{"capsule beside triangle", capsuleShape(mgl32.Vec2{3.0, 3.0}, mgl32.Vec2{6.0, 6.0}, 0.5), triangle, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CollideShapes(&tt.a, &tt.b); got != tt.collide {
+				t.Errorf("CollideShapes(a, b) = %v, want %v", got, tt.collide)
+			}
+			// 交换顺序结果相同
+			if got := CollideShapes(&tt.b, &tt.a); got != tt.collide {
+				t.Errorf("CollideShapes(b, a) = %v, want %v", got, tt.collide)
+			}
+		})
+	}
+}
+
+func TestColliderGetShape(t *testing.T) {
+	// 横向胶囊，沿较长的x轴，半径是y的一半
+	capsule := newTestCollider("capsule", mgl32.Vec2{10.0, 20.0}, mgl32.Vec2{30.0, 10.0}, ColliderTypeCapsule)
+	shape := capsule.GetShape()
+	if shape.A != (mgl32.Vec2{0.0, 20.0}) || shape.B != (mgl32.Vec2{20.0, 20.0}) || shape.Radius != 5.0 {
+		t.Errorf("capsule shape = %+v", shape)
+	}
+	boundsMin, boundsMax := shape.GetBounds()
+	if boundsMin != (mgl32.Vec2{-5.0, 15.0}) || boundsMax != (mgl32.Vec2{25.0, 25.0}) {
+		t.Errorf("capsule bounds = %v %v", boundsMin, boundsMax)
+	}
+
+	// 旋转90度的旋转矩形，包围盒宽高交换
+	obb := newTestCollider("obb", mgl32.Vec2{}, mgl32.Vec2{20.0, 10.0}, ColliderTypeOBB)
+	obb.SetAngle(90.0)
+	shape = obb.GetShape()
+	boundsMin, boundsMax = shape.GetBounds()
+	if !boundsMin.ApproxEqualThreshold(mgl32.Vec2{-5.0, -10.0}, 1e-4) || !boundsMax.ApproxEqualThreshold(mgl32.Vec2{5.0, 10.0}, 1e-4) {
+		t.Errorf("obb bounds = %v %v", boundsMin, boundsMax)
+	}
+
+	// 多边形顶点按包围盒居中，大小是包围盒
+	polygon := newTestCollider("polygon", mgl32.Vec2{100.0, 100.0}, mgl32.Vec2{}, ColliderTypePolygon)
+	polygon.SetPoints([]mgl32.Vec2{{0.0, 0.0}, {8.0, 0.0}, {0.0, 4.0}})
+	if polygon.GetSize() != (mgl32.Vec2{8.0, 4.0}) {
+		t.Errorf("polygon size = %v", polygon.GetSize())
+	}
+	shape = polygon.GetShape()
+	boundsMin, boundsMax = shape.GetBounds()
+	if boundsMin != (mgl32.Vec2{96.0, 98.0}) || boundsMax != (mgl32.Vec2{104.0, 102.0}) {
+		t.Errorf("polygon bounds = %v %v", boundsMin, boundsMax)
+	}
+}
@@ -39,3 +39,45 @@ const (
 	// 底部右，绘制完成后，父亲位于绘制矩形底部右
 	AnchorTypeBottomRight
 )
+
+// 渲染层，场景按层从小到大渲染，同一层内按z序和y排序
+type RenderLayer int
+
+const (
+	// 背景层，星空、网格等
+	RenderLayerBackground RenderLayer = iota
+	// 地面层，贴在地面上的特效，比如敌人出生的法阵
+	RenderLayerGround
+	// 角色层，玩家和敌人
+	RenderLayerActors
+	// 特效层，技能和粒子特效
+	RenderLayerEffects
+	// HUD层，界面
+	RenderLayerHUD
+	// 鼠标层，最上面
+	RenderLayerCursor
+	// 渲染层数量
+	RenderLayerCount
+)
+
+// 碰撞层，按位组合，碰撞器的层表示自己是什么，掩码表示关心哪些层
+type CollisionLayer uint32
+
+const (
+	// 默认层
+	CollisionLayerDefault CollisionLayer = 1 << iota
+	// 玩家
+	CollisionLayerPlayer
+	// 敌人
+	CollisionLayerEnemy
+	// 法术
+	CollisionLayerSpell
+	// 拾取物
+	CollisionLayerPickup
+)
+
+// 不属于任何层，作为掩码时不关心任何层
+const CollisionLayerNone CollisionLayer = 0
+
+// 所有层
+const CollisionLayerAll CollisionLayer = ^CollisionLayer(0)
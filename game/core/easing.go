@@ -0,0 +1,185 @@
+package core
+
+import (
+	"math"
+)
+
+// 缓动函数，输入输出范围都是[0,1]
+type EaseFunc func(t float32) float32
+
+// 线性
+func EaseLinear(t float32) float32 {
+	return t
+}
+
+// 二次缓入
+func EaseInQuad(t float32) float32 {
+	return t * t
+}
+
+// 二次缓出
+func EaseOutQuad(t float32) float32 {
+	return t * (2.0 - t)
+}
+
+// 二次缓入缓出
+func EaseInOutQuad(t float32) float32 {
+	if t < 0.5 {
+		return 2.0 * t * t
+	}
+	return -1.0 + (4.0-2.0*t)*t
+}
+
+// 三次缓入缓出
+func EaseInOutCubic(t float32) float32 {
+	if t < 0.5 {
+		return 4.0 * t * t * t
+	}
+	f := 2.0*t - 2.0
+	return 0.5*f*f*f + 1.0
+}
+
+// 正弦缓入
+func EaseInSine(t float32) float32 {
+	return 1.0 - float32(math.Cos(float64(t)*math.Pi/2.0))
+}
+
+// 正弦缓出
+func EaseOutSine(t float32) float32 {
+	return float32(math.Sin(float64(t) * math.Pi / 2.0))
+}
+
+// 正弦缓入缓出
+func EaseInOutSine(t float32) float32 {
+	return 0.5 - 0.5*float32(math.Cos(float64(t)*math.Pi))
+}
+
+// 三次缓入
+func EaseInCubic(t float32) float32 {
+	return t * t * t
+}
+
+// 三次缓出
+func EaseOutCubic(t float32) float32 {
+	f := t - 1.0
+	return f*f*f + 1.0
+}
+
+// 四次缓入
+func EaseInQuart(t float32) float32 {
+	return t * t * t * t
+}
+
+// 四次缓出
+func EaseOutQuart(t float32) float32 {
+	f := t - 1.0
+	return 1.0 - f*f*f*f
+}
+
+// 四次缓入缓出
+func EaseInOutQuart(t float32) float32 {
+	if t < 0.5 {
+		return 8.0 * t * t * t * t
+	}
+	f := t - 1.0
+	return 1.0 - 8.0*f*f*f*f
+}
+
+// 指数缓入
+func EaseInExpo(t float32) float32 {
+	if t <= 0.0 {
+		return 0.0
+	}
+	return float32(math.Pow(2.0, 10.0*float64(t)-10.0))
+}
+
+// 指数缓出
+func EaseOutExpo(t float32) float32 {
+	if t >= 1.0 {
+		return 1.0
+	}
+	return 1.0 - float32(math.Pow(2.0, -10.0*float64(t)))
+}
+
+// 指数缓入缓出
+func EaseInOutExpo(t float32) float32 {
+	if t <= 0.0 || t >= 1.0 {
+		return t
+	}
+	if t < 0.5 {
+		return float32(math.Pow(2.0, 20.0*float64(t)-10.0)) / 2.0
+	}
+	return (2.0 - float32(math.Pow(2.0, -20.0*float64(t)+10.0))) / 2.0
+}
+
+// 回退系数，越大回退越多
+const easeBackOvershoot = 1.70158
+
+// 回退缓入，先反向一点再加速
+func EaseInBack(t float32) float32 {
+	return t * t * ((easeBackOvershoot+1.0)*t - easeBackOvershoot)
+}
+
+// 回退缓出，超过终点一点再回来
+func EaseOutBack(t float32) float32 {
+	f := t - 1.0
+	return f*f*((easeBackOvershoot+1.0)*f+easeBackOvershoot) + 1.0
+}
+
+// 回退缓入缓出
+func EaseInOutBack(t float32) float32 {
+	s := float32(easeBackOvershoot * 1.525)
+	if t < 0.5 {
+		f := 2.0 * t
+		return 0.5 * f * f * ((s+1.0)*f - s)
+	}
+	f := 2.0*t - 2.0
+	return 0.5*(f*f*((s+1.0)*f+s)) + 1.0
+}
+
+// 弹性缓入
+func EaseInElastic(t float32) float32 {
+	if t <= 0.0 || t >= 1.0 {
+		return t
+	}
+	return -float32(math.Pow(2.0, 10.0*float64(t)-10.0) * math.Sin((float64(t)*10.0-10.75)*2.0*math.Pi/3.0))
+}
+
+// 弹性缓出
+func EaseOutElastic(t float32) float32 {
+	if t <= 0.0 || t >= 1.0 {
+		return t
+	}
+	return float32(math.Pow(2.0, -10.0*float64(t))*math.Sin((float64(t)*10.0-0.75)*2.0*math.Pi/3.0)) + 1.0
+}
+
+// 弹跳缓入
+func EaseInBounce(t float32) float32 {
+	return 1.0 - EaseOutBounce(1.0-t)
+}
+
+// 弹跳缓出，像球落地弹几下
+func EaseOutBounce(t float32) float32 {
+	const n, d = 7.5625, 2.75
+	switch {
+	case t < 1.0/d:
+		return n * t * t
+	case t < 2.0/d:
+		t -= 1.5 / d
+		return n*t*t + 0.75
+	case t < 2.5/d:
+		t -= 2.25 / d
+		return n*t*t + 0.9375
+	default:
+		t -= 2.625 / d
+		return n*t*t + 0.984375
+	}
+}
+
+// 弹跳缓入缓出
+func EaseInOutBounce(t float32) float32 {
+	if t < 0.5 {
+		return (1.0 - EaseOutBounce(1.0-2.0*t)) / 2.0
+	}
+	return (1.0 + EaseOutBounce(2.0*t-1.0)) / 2.0
+}
@@ -0,0 +1,110 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// 所有缓动函数
+var testEases = []struct {
+	name string
+	ease EaseFunc
+	// 中间是否会超出[0,1]
+	overshoot bool
+}{
+	{"Linear", EaseLinear, false},
+	{"InQuad", EaseInQuad, false},
+	{"OutQuad", EaseOutQuad, false},
+	{"InOutQuad", EaseInOutQuad, false},
+	{"InCubic", EaseInCubic, false},
+	{"OutCubic", EaseOutCubic, false},
+	{"InOutCubic", EaseInOutCubic, false},
+	{"InSine", EaseInSine, false},
+	{"OutSine", EaseOutSine, false},
+	{"InOutSine", EaseInOutSine, false},
+	{"InQuart", EaseInQuart, false},
+	{"OutQuart", EaseOutQuart, false},
+	{"InOutQuart", EaseInOutQuart, false},
+	{"InExpo", EaseInExpo, false},
+	{"OutExpo", EaseOutExpo, false},
+	{"InOutExpo", EaseInOutExpo, false},
+	{"InBack", EaseInBack, true},
+	{"OutBack", EaseOutBack, true},
+	{"InOutBack", EaseInOutBack, true},
+	{"InElastic", EaseInElastic, true},
+	{"OutElastic", EaseOutElastic, true},
+	{"InBounce", EaseInBounce, false},
+	{"OutBounce", EaseOutBounce, false},
+	{"InOutBounce", EaseInOutBounce, false},
+}
+
+func TestEaseEndpoints(t *testing.T) {
+	for _, tt := range testEases {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.ease(0.0); !mgl32.FloatEqualThreshold(got, 0.0, 1e-3) {
+				t.Errorf("ease(0) = %v", got)
+			}
+			if got := tt.ease(1.0); !mgl32.FloatEqualThreshold(got, 1.0, 1e-3) {
+				t.Errorf("ease(1) = %v", got)
+			}
+		})
+	}
+}
+
+func TestEaseRange(t *testing.T) {
+	for _, tt := range testEases {
+		if tt.overshoot {
+			continue
+		}
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i <= 100; i++ {
+				x := float32(i) / 100.0
+				if got := tt.ease(x); got < -1e-4 || got > 1.0+1e-4 {
+					t.Errorf("ease(%v) = %v out of [0,1]", x, got)
+				}
+			}
+		})
+	}
+}
+
+func TestEaseShape(t *testing.T) {
+	// 缓入开始慢，缓出开始快，缓入缓出中点是一半
+	tests := []struct {
+		name string
+		got  float32
+		want func(v float32) bool
+	}{
+		{"InQuad slow start", EaseInQuad(0.25), func(v float32) bool { return v < 0.25 }},
+		{"OutQuad fast start", EaseOutQuad(0.25), func(v float32) bool { return v > 0.25 }},
+		{"InOutQuad middle", EaseInOutQuad(0.5), func(v float32) bool { return mgl32.FloatEqual(v, 0.5) }},
+		{"InOutCubic middle", EaseInOutCubic(0.5), func(v float32) bool { return mgl32.FloatEqual(v, 0.5) }},
+		{"InOutSine middle", EaseInOutSine(0.5), func(v float32) bool { return mgl32.FloatEqual(v, 0.5) }},
+		{"InOutBounce middle", EaseInOutBounce(0.5), func(v float32) bool { return mgl32.FloatEqual(v, 0.5) }},
+		// 回退先反向，再超过终点
+		{"InBack undershoot", EaseInBack(0.2), func(v float32) bool { return v < 0.0 }},
+		{"OutBack overshoot", EaseOutBack(0.8), func(v float32) bool { return v > 1.0 }},
+		{"OutElastic overshoot", EaseOutElastic(0.1), func(v float32) bool { return v > 1.0 }},
+	}
+	for _, tt := range tests {
+		if !tt.want(tt.got) {
+			t.Errorf("%s: got %v", tt.name, tt.got)
+		}
+	}
+}
+
+func TestEaseInOutSymmetric(t *testing.T) {
+	for _, tt := range testEases {
+		if len(tt.name) < 5 || tt.name[:5] != "InOut" {
+			continue
+		}
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i <= 50; i++ {
+				x := float32(i) / 100.0
+				if a, b := tt.ease(x), 1.0-tt.ease(1.0-x); a-b > 1e-4 || b-a > 1e-4 {
+					t.Errorf("ease(%v) = %v, 1-ease(%v) = %v", x, a, 1.0-x, b)
+				}
+			}
+		})
+	}
+}
@@ -2,9 +2,10 @@ package core
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
-	"math/rand"
-	"os"
+	"io/fs"
+	"slices"
 	"sync"
 	"time"
 
@@ -16,6 +17,10 @@ import (
 const (
 	// 游戏帧率
 	FPS = 60
+	// 逻辑更新频率，每秒tick次数
+	TPS = 60
+	// 单帧最多追赶的tick次数，防止卡顿后陷入死循环
+	MaxCatchUpSteps = 5
 )
 
 var (
@@ -26,28 +31,45 @@ var (
 func GetInstance() *Game {
 	once.Do(func() {
 		instance = &Game{
-			fps:         FPS,
-			dt:          0.0,
-			frameDelay:  1e9 / FPS,
-			isRunning:   false,
-			sdlWindow:   nil,
-			sdlRenderer: nil,
+			fps:             FPS,
+			tickRate:        TPS,
+			dt:              1.0 / TPS,
+			maxCatchUpSteps: MaxCatchUpSteps,
+			musicVolume:     1.0,
+			frameDelay:      1e9 / FPS,
+			isRunning:       false,
+			sdlWindow:       nil,
+			sdlRenderer:     nil,
 		}
 	})
 	return instance
 }
 
 type Game struct {
-	// 随机数生成器
-	rand *rand.Rand
+	// 随机种子
+	seed int64
+	// 是否由外部指定了种子，指定后每一局都使用同一个种子
+	fixedSeed bool
+	// 各个随机数流
+	rands [randStreamCount]*Random
 	// 资源管理器
 	assetStore *AssetStore
+	// 素材文件系统
+	assetFS *AssetFS
 	// 屏幕大小
 	screenSize mgl32.Vec2
 	// 游戏帧率
 	fps uint64
-	// 帧间隔，单位秒
+	// 逻辑更新频率，每秒tick次数
+	tickRate uint64
+	// 固定逻辑步长，单位秒
 	dt float32
+	// 尚未消耗的逻辑时间，单位秒
+	accumulator float32
+	// 单帧最多追赶的tick次数
+	maxCatchUpSteps int
+	// 渲染插值系数，[0,1)，表示当前渲染时刻处于上一次tick和当前tick之间的位置
+	interpolation float32
 	// 帧延迟，单位纳秒
 	frameDelay float32
 	// 是否运行中
@@ -56,9 +78,19 @@ type Game struct {
 	sdlWindow *sdl.Window
 	// SDL渲染器
 	sdlRenderer *sdl.Renderer
+	// 无头模式下软件渲染器的离屏表面
+	sdlSurface *sdl.Surface
+	// 是否无头模式
+	headless bool
+	// 是否渲染，无头模式默认不渲染
+	renderEnabled bool
+	// 已经执行的tick数
+	tick uint64
 	// 字体引擎
 	ttfEngine *ttf.TextEngine
-	// 当前场景
+	// 场景栈，只有栈顶场景接收事件
+	sceneStack []IScene
+	// 当前正在处理的场景，更新和渲染下层场景时指向下层场景，其余时间为栈顶场景
 	currentScene IScene
 	// 鼠标位置
 	// 物理窗口中鼠标坐标，它的范围是从 (0, 0) 到 (WindowWidth, WindowHeight)。如果您把窗口从 1000x800 拉伸到 2000x1600，这些坐标的最大值也会随之变化。
@@ -69,20 +101,70 @@ type Game struct {
 	mousePosition mgl32.Vec2
 	// 鼠标按钮状态
 	mouseButtons sdl.MouseButtonFlags
+	// 键盘状态，按扫描码索引
+	keyboardState []bool
+	// 输入动作映射
+	input *InputMap
+	// 手柄管理器
+	gamepad *GamepadManager
+	// 录像录制器
+	replayRecorder *ReplayRecorder
+	// 录像回放器
+	replayPlayer *ReplayPlayer
 	// 分数
 	score int
 	// 最高分
 	highScore int
-	// 下一个场景
-	nextScene IScene
+	// 等待执行的场景操作，在两次tick之间执行
+	sceneCommands []sceneCommand
+	// 正在播放的转场
+	transition *Transition
+	// 音乐音量
+	musicVolume float32
+	// 世界视图变换，渲染世界对象时使用
+	worldView ViewTransform
+	// 是否在世界视图中，绘制函数把相机空间坐标经过世界视图变换后绘制
+	worldViewActive bool
+	// 是否绘制调试信息，比如碰撞器
+	debugDraw bool
+	// 渲染队列，绘制函数把命令加入队列，合批后提交
+	renderQueue *RenderQueue
+	// 绘制点和线时复用的缓冲
+	pointBuffer []sdl.FPoint
+}
+
+// 场景操作类型
+type sceneOp int
+
+const (
+	// 清空场景栈后切换到新场景
+	sceneOpChange sceneOp = iota
+	// 压入场景
+	sceneOpPush
+	// 弹出栈顶场景
+	sceneOpPop
+	// 替换栈顶场景
+	sceneOpReplace
+)
+
+// 场景操作
+type sceneCommand struct {
+	// 操作类型
+	op sceneOp
+	// 目标场景，弹出时为nil
+	scene IScene
+	// 转场效果，为nil时立即执行
+	transition *Transition
 }
 
 func (g *Game) Init(title string, width, height int32, scene IScene) error {
 	g.screenSize = mgl32.Vec2{float32(width), float32(height)}
-	g.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	g.ResetRand()
+	g.headless = false
+	g.renderEnabled = true
 
 	// 初始化 SDL
-	if !sdl.Init(sdl.InitVideo | sdl.InitAudio | sdl.InitEvents) {
+	if !sdl.Init(sdl.InitVideo | sdl.InitAudio | sdl.InitEvents | sdl.InitGamepad) {
 		return fmt.Errorf("sdl init error,%s", sdl.GetError())
 	}
 
@@ -91,6 +173,40 @@ func (g *Game) Init(title string, width, height int32, scene IScene) error {
 		return fmt.Errorf("sdl create window and renderer error,%s", sdl.GetError())
 	}
 
+	return g.initRenderer(width, height, scene)
+}
+
+// 无头模式初始化，不创建窗口，使用dummy视频/音频驱动和离屏软件渲染器
+// 用于CI和批量模拟，资源照常加载，默认跳过渲染，通过Step推进逻辑
+func (g *Game) InitHeadless(width, height int32, scene IScene) error {
+	g.screenSize = mgl32.Vec2{float32(width), float32(height)}
+	g.ResetRand()
+	g.headless = true
+	g.renderEnabled = false
+
+	sdl.SetHint(sdl.HintVideoDriver, "dummy")
+	sdl.SetHint(sdl.HintAudioDriver, "dummy")
+
+	// 初始化 SDL
+	if !sdl.Init(sdl.InitVideo | sdl.InitAudio | sdl.InitEvents | sdl.InitGamepad) {
+		return fmt.Errorf("sdl init error,%s", sdl.GetError())
+	}
+
+	// 创建离屏表面与软件渲染器
+	g.sdlSurface = sdl.CreateSurface(width, height, sdl.PixelFormatRGBA8888)
+	if g.sdlSurface == nil {
+		return fmt.Errorf("sdl create surface error,%s", sdl.GetError())
+	}
+	g.sdlRenderer = sdl.CreateSoftwareRenderer(g.sdlSurface)
+	if g.sdlRenderer == nil {
+		return fmt.Errorf("sdl create software renderer error,%s", sdl.GetError())
+	}
+
+	return g.initRenderer(width, height, scene)
+}
+
+// 渲染器创建后的公共初始化
+func (g *Game) initRenderer(width, height int32, scene IScene) error {
 	// 设置渲染器的逻辑尺寸
 	// sdl.LogicalPresentationLetterbox
 	// 它会把游戏画面放大到窗口允许的最大尺寸，同时不改变画面的比例。
@@ -107,36 +223,207 @@ func (g *Game) Init(title string, width, height int32, scene IScene) error {
 	// 创建字体引擎
 	g.ttfEngine = ttf.CreateRendererTextEngine(g.sdlRenderer)
 
+	// 创建渲染队列
+	g.renderQueue = CreateRenderQueue(g.sdlRenderer)
+
 	// 创建资源管理器
-	g.assetStore = CreateAssetStore(g.sdlRenderer)
+	g.assetStore = CreateAssetStore(g.sdlRenderer, g.GetAssetFS())
+	// 素材属于正在处理的场景，场景退出时释放
+	g.assetStore.SetOwnerFunc(func() any {
+		if g.currentScene == nil {
+			return nil
+		}
+		return g.currentScene
+	})
 
-	g.currentScene = scene
-	g.currentScene.Init()
+	if g.keyboardState == nil {
+		g.keyboardState = sdl.GetKeyboardState()
+	}
+
+	// 创建手柄管理器，已连接的手柄会在初始化后通过事件加入
+	g.gamepad = CreateGamepadManager()
+
+	// 创建输入动作映射，配置文件不存在时使用默认绑定，并写出默认配置方便修改
+	g.input = CreateInputMap()
+	if err := g.LoadInputConfig(); errors.Is(err, fs.ErrNotExist) {
+		if err = g.SaveInputConfig(); err != nil {
+			fmt.Println(err)
+		}
+	} else if err != nil {
+		fmt.Printf("load input config error,%v\n", err)
+	}
+
+	g.ChangeScene(scene)
 
 	g.isRunning = true
 	return nil
 }
 
 func (g *Game) Run() {
+	last := sdl.GetTicksNS()
 	// 主循环
 	for g.isRunning {
 		start := sdl.GetTicksNS()
-		if g.nextScene != nil {
-			g.ChangeScene(g.nextScene)
-			g.nextScene = nil
+		elapsed := float32(start-last) / 1e9
+		last = start
+		if g.replayPlayer != nil {
+			g.updatePlayback(elapsed)
+		} else {
+			g.updateFrame(elapsed)
 		}
-		g.handleEvent()
-		g.update(g.dt)
-		g.render()
-		end := sdl.GetTicksNS()
-		elapsed := float32(end - start)
-		if elapsed < g.frameDelay {
-			sdl.DelayNS(uint64(g.frameDelay - elapsed))
-			g.dt = g.frameDelay / 1e9
+		if g.renderEnabled {
+			g.render()
+		}
+		// 热重载按真实时间检查文件
+		g.assetStore.Update(elapsed)
+		frameTime := float32(sdl.GetTicksNS() - start)
+		if frameTime < g.frameDelay {
+			sdl.DelayNS(uint64(g.frameDelay - frameTime))
+		}
+	}
+}
+
+// 不等待真实时间，直接推进n次tick，用于无头模式和自动化测试
+func (g *Game) Step(n int) {
+	for i := 0; i < n && g.isRunning; {
+		if g.replayPlayer != nil {
+			frame := g.replayPlayer.NextFrame()
+			if frame == nil {
+				return
+			}
+			g.handleEvent()
+			g.playFrame(frame)
+			i += len(frame.Ticks)
 		} else {
-			g.dt = elapsed / 1e9
+			g.checkNextScene()
+			g.handleEvent()
+			if !g.isRunning {
+				return
+			}
+			g.update(g.dt)
+			g.checkNextScene()
+			g.endFrame()
+			i++
+		}
+		// 逻辑和渲染同步，直接渲染当前tick
+		g.interpolation = 1.0
+		if g.renderEnabled {
+			g.render()
 		}
-		// fmt.Printf("dt: %f\n", g.dt)
+	}
+}
+
+// 按真实流逝的时间推进一帧
+func (g *Game) updateFrame(elapsed float32) {
+	g.accumulator += elapsed
+	// 卡顿后最多追赶maxCatchUpSteps次，多出的时间直接丢弃
+	maxAccumulator := g.dt * float32(g.maxCatchUpSteps)
+	if g.accumulator > maxAccumulator {
+		g.accumulator = maxAccumulator
+	}
+	g.checkNextScene()
+	g.handleEvent()
+	// 固定步长更新逻辑
+	for g.isRunning && g.accumulator >= g.dt {
+		g.update(g.dt)
+		g.accumulator -= g.dt
+		g.checkNextScene()
+	}
+	g.interpolation = g.accumulator / g.dt
+	g.endFrame()
+}
+
+// 按回放速度推进录像帧，暂停时只响应单步
+func (g *Game) updatePlayback(elapsed float32) {
+	p := g.replayPlayer
+	g.handleEvent()
+	if p.GetPause() {
+		if p.consumeStep() {
+			// 单步执行到下一个有tick的帧
+			for frame := p.NextFrame(); frame != nil; frame = p.NextFrame() {
+				g.playFrame(frame)
+				if len(frame.Ticks) > 0 {
+					break
+				}
+			}
+		}
+		g.interpolation = 1.0
+		return
+	}
+	p.advanceClock(elapsed, g.dt, g.maxCatchUpSteps)
+	for g.isRunning {
+		frame := p.nextDueFrame(g.dt)
+		if frame == nil {
+			break
+		}
+		g.playFrame(frame)
+	}
+	g.interpolation = mgl32.Clamp(p.clock/g.dt, 0.0, 1.0)
+}
+
+// 回放一帧录像，顺序和实时运行保持一致
+func (g *Game) playFrame(frame *ReplayFrame) {
+	g.checkNextScene()
+	for i := range frame.Events {
+		g.dispatchEvent(&frame.Events[i])
+	}
+	for _, t := range frame.Ticks {
+		if !g.isRunning {
+			return
+		}
+		g.mousePosition = t.MousePosition
+		g.mouseButtons = t.MouseButtons
+		clear(g.keyboardState)
+		for _, k := range t.Keys {
+			if int(k) < len(g.keyboardState) {
+				g.keyboardState[k] = true
+			}
+		}
+		g.update(t.Dt)
+		g.checkNextScene()
+	}
+}
+
+// 结束一帧，录制中则提交本帧输入
+func (g *Game) endFrame() {
+	if g.replayRecorder != nil {
+		g.replayRecorder.EndFrame()
+	}
+}
+
+// 检查是否需要切换场景，场景Init中发起的操作也会在这里一并执行
+func (g *Game) checkNextScene() {
+	for len(g.sceneCommands) > 0 {
+		// 转场切换场景之前，新的场景操作等待转场
+		if g.transition != nil && !g.transition.isSwitched {
+			return
+		}
+		command := g.sceneCommands[0]
+		g.sceneCommands = g.sceneCommands[1:]
+		if command.transition == nil {
+			g.runSceneCommand(command)
+			continue
+		}
+		// 正在转场时忽略新的转场
+		if g.transition != nil {
+			continue
+		}
+		g.transition = command.transition
+		g.transition.start(g, sceneCommand{op: command.op, scene: command.scene})
+	}
+}
+
+// 执行场景操作
+func (g *Game) runSceneCommand(command sceneCommand) {
+	switch command.op {
+	case sceneOpChange:
+		g.ChangeScene(command.scene)
+	case sceneOpPush:
+		g.PushScene(command.scene)
+	case sceneOpPop:
+		g.PopScene()
+	case sceneOpReplace:
+		g.ReplaceScene(command.scene)
 	}
 }
 
@@ -148,14 +435,116 @@ func (g *Game) handleEvent() {
 			g.isRunning = false
 			return
 		}
-		g.currentScene.HandleEvent(&event)
+		// 回放中真实输入只用于控制回放
+		if g.replayPlayer != nil {
+			g.replayPlayer.HandleEvent(&event)
+			continue
+		}
+		if g.replayRecorder != nil {
+			g.replayRecorder.RecordEvent(&event)
+		}
+		g.dispatchEvent(&event)
+	}
+}
+
+// 分发事件给手柄管理器、输入动作映射和栈顶场景
+func (g *Game) dispatchEvent(event *sdl.Event) {
+	g.gamepad.HandleEvent(event)
+	g.input.HandleEvent(event)
+	// 转场时屏蔽场景输入
+	if g.transition != nil {
+		return
+	}
+	if top := g.GetTopScene(); top != nil {
+		top.HandleEvent(event)
 	}
 }
 
 // 更新状态
 func (g *Game) update(dt float32) {
-	g.updateMouse()
-	g.currentScene.Update(dt)
+	g.tick++
+	// 回放时输入由录像提供
+	if g.replayPlayer == nil {
+		g.updateMouse()
+		g.updateKeyboard()
+	}
+	if g.replayRecorder != nil {
+		g.replayRecorder.RecordTick(dt, g.mousePosition, g.mouseButtons, g.keyboardState)
+	}
+	if g.transition != nil && g.transition.update(g, dt) {
+		g.transition = nil
+	}
+	g.input.SetBlocked(g.transition != nil)
+	g.input.Update(g.keyboardState, g.mouseButtons, g.gamepad)
+	g.updateScenes(dt)
+	g.input.ClearLatched()
+}
+
+// 从下往上更新场景，栈顶场景允许时下层场景继续更新
+func (g *Game) updateScenes(dt float32) {
+	scenes := slices.Clone(g.sceneStack[g.getUpdateStart():])
+	for _, scene := range scenes {
+		g.currentScene = scene
+		scene.Update(dt)
+	}
+	g.currentScene = g.GetTopScene()
+}
+
+// 从下往上渲染场景，栈顶场景允许时下层场景继续渲染
+func (g *Game) renderScenes() {
+	interpolation := g.interpolation
+	updateStart := g.getUpdateStart()
+	for i := g.getRenderStart(); i < len(g.sceneStack); i++ {
+		g.currentScene = g.sceneStack[i]
+		// 没有更新的下层场景是静止的，不需要插值
+		if i < updateStart {
+			g.interpolation = 1.0
+		} else {
+			g.interpolation = interpolation
+		}
+		g.currentScene.Render()
+	}
+	g.interpolation = interpolation
+	g.currentScene = g.GetTopScene()
+}
+
+// 把当前场景栈渲染到纹理，用于交叉淡化，不渲染时返回nil
+func (g *Game) captureScenes() *sdl.Texture {
+	if !g.renderEnabled || g.sdlRenderer == nil {
+		return nil
+	}
+	texture := sdl.CreateTexture(g.sdlRenderer, sdl.PixelFormatRGBA8888, sdl.TextureAccessTarget,
+		int32(g.screenSize.X()), int32(g.screenSize.Y()))
+	if texture == nil {
+		fmt.Printf("create transition texture error,%s\n", sdl.GetError())
+		return nil
+	}
+	// 切换渲染目标前后都要提交，命令不能画到别的目标上
+	g.FlushRender()
+	sdl.SetRenderTarget(g.sdlRenderer, texture)
+	sdl.RenderClear(g.sdlRenderer)
+	g.renderScenes()
+	g.FlushRender()
+	sdl.SetRenderTarget(g.sdlRenderer, nil)
+	return texture
+}
+
+// 获取需要更新的最下层场景索引
+func (g *Game) getUpdateStart() int {
+	i := len(g.sceneStack) - 1
+	for i > 0 && g.sceneStack[i].GetUpdateBelow() {
+		i--
+	}
+	return max(i, 0)
+}
+
+// 获取需要渲染的最下层场景索引
+func (g *Game) getRenderStart() int {
+	i := len(g.sceneStack) - 1
+	for i > 0 && g.sceneStack[i].GetRenderBelow() {
+		i--
+	}
+	return max(i, 0)
 }
 
 // 渲染
@@ -163,8 +552,16 @@ func (g *Game) render() {
 	// 清空渲染器
 	sdl.RenderClear(g.sdlRenderer)
 
-	// 渲染当前场景
-	g.currentScene.Render()
+	// 渲染场景栈
+	g.renderScenes()
+
+	// 渲染转场
+	if g.transition != nil {
+		g.transition.render(g)
+	}
+
+	// 提交渲染队列
+	g.renderQueue.EndFrame()
 
 	// 显示更新
 	sdl.RenderPresent(g.sdlRenderer)
@@ -172,9 +569,26 @@ func (g *Game) render() {
 
 // 清理资源
 func (g *Game) Clean() {
-	if g.currentScene != nil {
-		g.currentScene.Clean()
-		g.currentScene = nil
+	if err := g.StopRecording(); err != nil {
+		fmt.Printf("save replay error,%v\n", err)
+	}
+	if g.transition != nil {
+		g.transition.clean()
+		g.transition = nil
+	}
+	g.clearScenes()
+	g.sceneCommands = nil
+	if g.gamepad != nil {
+		g.gamepad.Clean()
+	}
+	// 素材要在渲染器销毁前卸载
+	if g.assetStore != nil {
+		g.assetStore.Clean()
+		g.assetStore = nil
+	}
+	if g.assetFS != nil {
+		g.assetFS.Clean()
+		g.assetFS = nil
 	}
 
 	// 清理SDL资源
@@ -186,6 +600,10 @@ func (g *Game) Clean() {
 		sdl.DestroyWindow(g.sdlWindow)
 		g.sdlWindow = nil
 	}
+	if g.sdlSurface != nil {
+		sdl.DestroySurface(g.sdlSurface)
+		g.sdlSurface = nil
+	}
 	if g.ttfEngine != nil {
 		ttf.DestroyRendererTextEngine(g.ttfEngine)
 		g.ttfEngine = nil
@@ -193,6 +611,68 @@ func (g *Game) Clean() {
 	sdl.Quit()
 }
 
+// 是否无头模式
+func (g *Game) IsHeadless() bool {
+	return g.headless
+}
+
+// 获取是否渲染
+func (g *Game) GetRenderEnabled() bool {
+	return g.renderEnabled
+}
+
+// 设置是否渲染，无头模式下开启会渲染到离屏表面
+func (g *Game) SetRenderEnabled(enabled bool) {
+	g.renderEnabled = enabled
+}
+
+// 获取离屏表面，只有无头模式才有
+func (g *Game) GetSurface() *sdl.Surface {
+	return g.sdlSurface
+}
+
+// 获取已经执行的tick数
+func (g *Game) GetTick() uint64 {
+	return g.tick
+}
+
+// 获取逻辑更新频率
+func (g *Game) GetTickRate() uint64 {
+	return g.tickRate
+}
+
+// 设置逻辑更新频率
+func (g *Game) SetTickRate(tickRate uint64) {
+	if tickRate == 0 {
+		return
+	}
+	g.tickRate = tickRate
+	g.dt = 1.0 / float32(tickRate)
+}
+
+// 获取固定逻辑步长
+func (g *Game) GetDt() float32 {
+	return g.dt
+}
+
+// 获取单帧最多追赶的tick次数
+func (g *Game) GetMaxCatchUpSteps() int {
+	return g.maxCatchUpSteps
+}
+
+// 设置单帧最多追赶的tick次数
+func (g *Game) SetMaxCatchUpSteps(steps int) {
+	if steps < 1 {
+		steps = 1
+	}
+	g.maxCatchUpSteps = steps
+}
+
+// 获取渲染插值系数
+func (g *Game) GetInterpolation() float32 {
+	return g.interpolation
+}
+
 // 获取屏幕大小
 func (g *Game) GetScreenSize() mgl32.Vec2 {
 	return g.screenSize
@@ -203,138 +683,203 @@ func (g *Game) GetWorldSize() mgl32.Vec2 {
 	return g.currentScene.GetWorldSize()
 }
 
-// 绘制网格
+// 绘制网格，所有竖线连成一条折线，所有横线连成一条折线，各一次绘制
+// 折线的连接段落在网格的边上，和边界重合
 func (g *Game) DrawGrid(topLeft, bottomRight mgl32.Vec2, gridWidth float32, fcolor sdl.FColor) {
-	sdl.SetRenderDrawColorFloat(g.sdlRenderer, fcolor.R, fcolor.G, fcolor.B, fcolor.A)
-	screenRect := sdl.FRect{
-		X: 0.0,
-		Y: 0.0,
-		W: g.screenSize.X(),
-		H: g.screenSize.Y(),
-	}
-	for x := topLeft.X(); x < bottomRight.X(); x += gridWidth {
-		if !sdl.PointInRectFloat(sdl.FPoint{X: x, Y: 0.0}, screenRect) {
-			continue
+	points := g.pointBuffer[:0]
+	for x, k := topLeft.X(), 0; x < bottomRight.X(); x, k = x+gridWidth, k+1 {
+		from, to := mgl32.Vec2{x, topLeft.Y()}, mgl32.Vec2{x, bottomRight.Y()}
+		if k%2 == 1 {
+			from, to = to, from
 		}
-		sdl.RenderLine(g.sdlRenderer, x, topLeft.Y(), x, bottomRight.Y())
+		points = append(points, g.viewFPoint(from), g.viewFPoint(to))
 	}
-	for y := topLeft.Y(); y < bottomRight.Y(); y += gridWidth {
-		if !sdl.PointInRectFloat(sdl.FPoint{X: 0.0, Y: y}, screenRect) {
-			continue
+	g.renderQueue.AddLines(points, fcolor)
+	points = points[:0]
+	for y, k := topLeft.Y(), 0; y < bottomRight.Y(); y, k = y+gridWidth, k+1 {
+		from, to := mgl32.Vec2{topLeft.X(), y}, mgl32.Vec2{bottomRight.X(), y}
+		if k%2 == 1 {
+			from, to = to, from
 		}
-		sdl.RenderLine(g.sdlRenderer, topLeft.X(), y, bottomRight.X(), y)
+		points = append(points, g.viewFPoint(from), g.viewFPoint(to))
 	}
-	sdl.SetRenderDrawColorFloat(g.sdlRenderer, 0, 0, 0, 1)
+	g.renderQueue.AddLines(points, fcolor)
+	g.pointBuffer = points[:0]
 }
 
-// 绘制边界
+// 绘制边界，边界是矩形外面的四条填充带，可以合并成一次绘制
 func (g *Game) DrawBoundary(topLeft, bottomRight mgl32.Vec2, boundaryWidth float32, fcolor sdl.FColor) {
-	sdl.SetRenderDrawColorFloat(g.sdlRenderer, fcolor.R, fcolor.G, fcolor.B, fcolor.A)
-	screenRect := sdl.FRect{
-		X: 0.0,
-		Y: 0.0,
-		W: g.screenSize.X(),
-		H: g.screenSize.Y(),
+	outerTopLeft := topLeft.Sub(mgl32.Vec2{boundaryWidth, boundaryWidth})
+	outerBottomRight := bottomRight.Add(mgl32.Vec2{boundaryWidth, boundaryWidth})
+	// 上下两条包括四个角，左右两条夹在中间
+	g.queueRect(outerTopLeft, mgl32.Vec2{outerBottomRight.X(), topLeft.Y()}, fcolor)
+	g.queueRect(mgl32.Vec2{outerTopLeft.X(), bottomRight.Y()}, outerBottomRight, fcolor)
+	g.queueRect(mgl32.Vec2{outerTopLeft.X(), topLeft.Y()}, mgl32.Vec2{topLeft.X(), bottomRight.Y()}, fcolor)
+	g.queueRect(mgl32.Vec2{bottomRight.X(), topLeft.Y()}, mgl32.Vec2{outerBottomRight.X(), bottomRight.Y()}, fcolor)
+}
+
+// 绘制填充矩形，支持半透明
+func (g *Game) DrawFillRect(topLeft, bottomRight mgl32.Vec2, fcolor sdl.FColor) {
+	if bottomRight.X() <= topLeft.X() || bottomRight.Y() <= topLeft.Y() {
+		return
 	}
-	for i := float32(0.0); i < boundaryWidth; i++ {
-		rect := sdl.FRect{
-			X: topLeft.X() - i,
-			Y: topLeft.Y() - i,
-			W: bottomRight.X() - topLeft.X() + 2*i,
-			H: bottomRight.Y() - topLeft.Y() + 2*i,
-		}
-		intersectionRect, ok := sdl.GetRectIntersectionFloat(screenRect, rect)
-		if !ok {
-			continue
-		}
-		sdl.RenderRect(g.sdlRenderer, &intersectionRect)
+	g.queueRect(topLeft, bottomRight, fcolor)
+}
+
+// 绘制多边形轮廓，首尾相连
+func (g *Game) DrawPolygon(points []mgl32.Vec2, fcolor sdl.FColor) {
+	if len(points) < 2 {
+		return
+	}
+	buffer := g.pointBuffer[:0]
+	for _, p := range points {
+		buffer = append(buffer, g.viewFPoint(p))
 	}
-	sdl.SetRenderDrawColorFloat(g.sdlRenderer, 0, 0, 0, 1)
+	buffer = append(buffer, buffer[0])
+	g.renderQueue.AddLines(buffer, fcolor)
+	g.pointBuffer = buffer[:0]
 }
 
-// 获取当前场景
+// 获取当前场景，更新和渲染下层场景时为下层场景，其余时间为栈顶场景
 func (g *Game) GetCurrentScene() IScene {
 	return g.currentScene
 }
 
+// 获取栈顶场景，场景栈为空返回nil
+func (g *Game) GetTopScene() IScene {
+	if len(g.sceneStack) == 0 {
+		return nil
+	}
+	return g.sceneStack[len(g.sceneStack)-1]
+}
+
+// 获取场景栈，从栈底到栈顶
+func (g *Game) GetSceneStack() []IScene {
+	return g.sceneStack
+}
+
 // 获取资源管理器
 func (g *Game) GetAssetStore() *AssetStore {
 	return g.assetStore
 }
 
-// 渲染纹理
+// 获取素材文件系统，没有设置时使用素材所在的系统目录
+func (g *Game) GetAssetFS() *AssetFS {
+	if g.assetFS == nil {
+		g.assetFS = CreateDefaultAssetFS()
+	}
+	return g.assetFS
+}
+
+// 设置素材文件系统，需要在初始化之前设置
+func (g *Game) SetAssetFS(assetFS *AssetFS) {
+	g.assetFS = assetFS
+}
+
+// 获取可写文件的系统路径，存档等文件不会写入素材包
+func (g *Game) GetWritePath(filePath string) string {
+	return g.GetAssetFS().GetWritePath(filePath)
+}
+
+// 根据素材清单创建预加载器，加载的素材属于owner场景，owner退出时释放
+func (g *Game) PreloadAssets(manifestPath string, owner IScene) (*AssetPreloader, error) {
+	manifest, err := LoadAssetManifest(g.GetAssetFS(), manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	var assetOwner any
+	if owner != nil {
+		assetOwner = owner
+	}
+	return CreateAssetPreloader(g.assetStore, manifest, assetOwner), nil
+}
+
+// 打印已加载素材的报告，用于调试
+func (g *Game) PrintAssetReport() {
+	if g.assetStore == nil {
+		return
+	}
+	g.assetStore.PrintReport()
+}
+
+// 渲染纹理，目标矩形不裁剪，超出屏幕的部分由SDL裁剪，屏幕边缘的图片不会被压扁
 func (g *Game) RenderTexture(texture *Texture, pos mgl32.Vec2, size mgl32.Vec2, percent mgl32.Vec2) {
+	texture.resolve(g.assetStore)
 	srcRect := sdl.FRect{
 		X: texture.SrcRect.X,
 		Y: texture.SrcRect.Y,
 		W: texture.SrcRect.W * percent.X(),
 		H: texture.SrcRect.H * percent.Y(),
 	}
-	dstRect := sdl.FRect{
-		X: pos.X(),
-		Y: pos.Y(),
-		W: size.X() * percent.X(),
-		H: size.Y() * percent.Y(),
-	}
-	screenRect := sdl.FRect{
-		X: 0.0,
-		Y: 0.0,
-		W: g.screenSize.X(),
-		H: g.screenSize.Y(),
+	size = mgl32.Vec2{size.X() * percent.X(), size.Y() * percent.Y()}
+	angle := texture.Angle
+	if g.inWorldView() {
+		// 世界视图中绕纹理中心缩放，旋转叠加到纹理角度上
+		pos, size = g.worldView.ApplyRect(pos, size)
+		angle += float64(g.worldView.Rotation)
 	}
-	intersectionRect, ok := sdl.GetRectIntersectionFloat(screenRect, dstRect)
-	if !ok {
-		return
-	}
-	flipMode := sdl.FlipNone
-	if texture.IsFlip {
-		flipMode = sdl.FlipHorizontal
-	}
-	sdl.RenderTextureRotated(g.sdlRenderer, texture.Texture, &srcRect, &intersectionRect, texture.Angle, nil, flipMode)
+	// 图集中的图片共用底层纹理，颜色放在顶点里，不修改底层纹理
+	g.queueTexture(texture.Texture, srcRect, pos, size, angle, texture.IsFlip, texture.Color)
 }
 
 // 绘制填充圆，并不是画圆，而是用绘制圆形纹理，目的是可视化碰撞器
 func (g *Game) RenderFillCircle(pos mgl32.Vec2, size mgl32.Vec2, alpha float32) {
-	dstRect := sdl.FRect{
-		X: pos.X(),
-		Y: pos.Y(),
-		W: size.X(),
-		H: size.Y(),
-	}
-	screenRect := sdl.FRect{
-		X: 0.0,
-		Y: 0.0,
-		W: g.screenSize.X(),
-		H: g.screenSize.Y(),
-	}
-	intersectionRect, ok := sdl.GetRectIntersectionFloat(screenRect, dstRect)
-	if !ok {
-		return
+	if g.inWorldView() {
+		pos, size = g.worldView.ApplyRect(pos, size)
 	}
 	texture, err := g.assetStore.GetImage("assets/UI/circle.png")
 	if err != nil {
 		return
 	}
-	sdl.SetTextureAlphaModFloat(texture, alpha)
-	sdl.RenderTexture(g.sdlRenderer, texture, nil, &intersectionRect)
+	srcRect := sdl.FRect{X: 0.0, Y: 0.0, W: float32(texture.W), H: float32(texture.H)}
+	g.queueTexture(texture, srcRect, pos, size, 0.0, false, sdl.FColor{R: 1.0, G: 1.0, B: 1.0, A: alpha})
+}
+
+// 设置随机种子，之后每一局都使用这个种子，需要在Init之前调用
+func (g *Game) SetSeed(seed int64) {
+	g.seed = seed
+	g.fixedSeed = true
+	g.resetRandStreams()
+}
+
+// 获取随机种子
+func (g *Game) GetSeed() int64 {
+	return g.seed
+}
+
+// 重新开始一局的随机数，指定了种子时用同一个种子重置所有随机数流，否则生成新种子
+func (g *Game) ResetRand() {
+	if !g.fixedSeed {
+		g.seed = time.Now().UnixNano()
+	}
+	g.resetRandStreams()
+}
+
+// 用当前种子重置所有随机数流
+func (g *Game) resetRandStreams() {
+	for i := range g.rands {
+		g.rands[i] = CreateRandom(g.seed, RandStream(i))
+	}
+}
+
+// 获取随机数流
+func (g *Game) GetRand(stream RandStream) *Random {
+	return g.rands[stream]
 }
 
 // 随机min和max范围的浮点数
 func (g *Game) RandFloat32(min, max float32) float32 {
-	return min + g.rand.Float32()*(max-min)
+	return g.rands[RandStreamDefault].RandFloat32(min, max)
 }
 
-// 随机min和max范围的整数
+// 随机[min,max)范围的整数
 func (g *Game) RandInt(min, max int) int {
-	return min + g.rand.Int()*(max-min)
+	return g.rands[RandStreamDefault].RandInt(min, max)
 }
 
 // 随机min和max范围的Vec2
 func (g *Game) RandVec2(min, max mgl32.Vec2) mgl32.Vec2 {
-	return mgl32.Vec2{
-		g.RandFloat32(min.X(), max.X()),
-		g.RandFloat32(min.Y(), max.Y()),
-	}
+	return g.rands[RandStreamDefault].RandVec2(min, max)
 }
 
 // 获取鼠标位置
@@ -347,19 +892,85 @@ func (g *Game) GetMouseButtons() sdl.MouseButtonFlags {
 	return g.mouseButtons
 }
 
+// 获取键盘状态，按扫描码索引，回放时返回录像中的状态
+func (g *Game) GetKeyboardState() []bool {
+	return g.keyboardState
+}
+
+// 获取输入动作映射
+func (g *Game) GetInput() *InputMap {
+	return g.input
+}
+
+// 加载按键绑定，可写目录中保存的配置优先于素材中的默认配置
+func (g *Game) LoadInputConfig() error {
+	return g.input.LoadConfig(g.GetAssetFS())
+}
+
+// 保存按键绑定到可写目录，素材可能在素材包或者程序内
+func (g *Game) SaveInputConfig() error {
+	return g.input.SaveConfig(g.GetAssetFS())
+}
+
+// 获取手柄管理器
+func (g *Game) GetGamepad() *GamepadManager {
+	return g.gamepad
+}
+
+// 动作是否按下
+func (g *Game) IsActionPressed(action Action) bool {
+	return g.input.IsActionPressed(action)
+}
+
+// 动作是否在这次tick刚刚按下
+func (g *Game) WasActionJustPressed(action Action) bool {
+	return g.input.WasActionJustPressed(action)
+}
+
+// 动作是否在这次tick刚刚松开
+func (g *Game) WasActionJustReleased(action Action) bool {
+	return g.input.WasActionJustReleased(action)
+}
+
+// 开始录制，需要在Init之后调用，会固定当前种子保证回放一致
+func (g *Game) StartRecording(filePath string) {
+	g.fixedSeed = true
+	g.replayRecorder = CreateReplayRecorder(filePath, g.seed, g.tickRate)
+}
+
+// 停止录制并保存录像
+func (g *Game) StopRecording() error {
+	if g.replayRecorder == nil {
+		return nil
+	}
+	err := g.replayRecorder.Save()
+	g.replayRecorder = nil
+	return err
+}
+
+// 开始回放，需要在Init之前调用，使用录像中的种子和逻辑更新频率
+func (g *Game) StartPlayback(filePath string) error {
+	replay, err := LoadReplay(filePath)
+	if err != nil {
+		return err
+	}
+	g.SetSeed(replay.Seed)
+	g.SetTickRate(replay.TickRate)
+	g.replayPlayer = CreateReplayPlayer(replay)
+	g.keyboardState = make([]bool, sdl.ScancodeCount)
+	return nil
+}
+
+// 获取录像回放器，没有回放时返回nil
+func (g *Game) GetReplayPlayer() *ReplayPlayer {
+	return g.replayPlayer
+}
+
 // 绘制水平进度条
 func (g *Game) RenderHBar(pos mgl32.Vec2, size mgl32.Vec2, percent mgl32.Vec2, color sdl.FColor) {
-	boundaryRect := sdl.FRect{
-		X: pos.X(),
-		Y: pos.Y(),
-		W: size.X(),
-		H: size.Y(),
-	}
-	fillRect := sdl.FRect{
-		X: pos.X(),
-		Y: pos.Y(),
-		W: size.X() * percent.X(),
-		H: size.Y() * percent.Y(),
+	if g.inWorldView() {
+		// 进度条保持水平，只跟着缩放
+		pos, size = g.worldView.ApplyRect(pos, size)
 	}
 	screenRect := sdl.FRect{
 		X: 0.0,
@@ -367,19 +978,20 @@ func (g *Game) RenderHBar(pos mgl32.Vec2, size mgl32.Vec2, percent mgl32.Vec2, c
 		W: g.screenSize.X(),
 		H: g.screenSize.Y(),
 	}
-	intersectionRect1, ok1 := sdl.GetRectIntersectionFloat(screenRect, boundaryRect)
-	intersectionRect2, ok2 := sdl.GetRectIntersectionFloat(screenRect, fillRect)
-	if !ok1 && !ok2 {
+	if !sdl.HasRectIntersectionFloat(screenRect, sdl.FRect{X: pos.X(), Y: pos.Y(), W: size.X(), H: size.Y()}) {
 		return
 	}
-	sdl.SetRenderDrawColorFloat(g.sdlRenderer, color.R, color.G, color.B, color.A)
-	if ok1 {
-		sdl.RenderRect(g.sdlRenderer, &intersectionRect1)
-	}
-	if ok2 {
-		sdl.RenderFillRect(g.sdlRenderer, &intersectionRect2)
+	// 已经变换过，直接加入屏幕坐标的四边形，边框是一个像素宽的四条边，和填充、精灵合并成一次绘制
+	bottomRight := pos.Add(size)
+	g.queueScreenRect(pos, mgl32.Vec2{bottomRight.X() + 1.0, pos.Y() + 1.0}, color)
+	g.queueScreenRect(mgl32.Vec2{pos.X(), bottomRight.Y()}, bottomRight.Add(mgl32.Vec2{1.0, 1.0}), color)
+	g.queueScreenRect(mgl32.Vec2{pos.X(), pos.Y() + 1.0}, mgl32.Vec2{pos.X() + 1.0, bottomRight.Y()}, color)
+	g.queueScreenRect(mgl32.Vec2{bottomRight.X(), pos.Y() + 1.0}, mgl32.Vec2{bottomRight.X() + 1.0, bottomRight.Y()}, color)
+	fill := mgl32.Vec2{size.X() * percent.X(), size.Y() * percent.Y()}
+	if fill.X() <= 0.0 || fill.Y() <= 0.0 {
+		return
 	}
-	sdl.SetRenderDrawColorFloat(g.sdlRenderer, 0.0, 0.0, 0.0, 1.0)
+	g.queueScreenRect(pos, pos.Add(fill), color)
 }
 
 // 创建TTF文本
@@ -388,7 +1000,23 @@ func (g *Game) CreateTTFText(text string, fontPath string, fontSize float32) *tt
 	if err != nil {
 		return nil
 	}
-	return ttf.CreateText(g.ttfEngine, font, text, 0)
+	ttfText := ttf.CreateText(g.ttfEngine, font, text, 0)
+	if ttfText != nil {
+		// 记录下来，字体热重载时切换到新字体
+		g.assetStore.trackText(ttfText, fontPath, fontSize)
+	}
+	return ttfText
+}
+
+// 销毁TTF文本，CreateTTFText创建的文本都要用这个销毁
+func (g *Game) DestroyTTFText(text *ttf.Text) {
+	if text == nil {
+		return
+	}
+	if g.assetStore != nil {
+		g.assetStore.untrackText(text)
+	}
+	ttf.DestroyText(text)
 }
 
 // 设置分数
@@ -427,6 +1055,7 @@ func (g *Game) PlayMusic(musicPath string, loop bool) {
 	}
 	for _, m := range music {
 		m.SetLoop(loop)
+		m.SetVolume(g.musicVolume)
 		if m.Play() {
 			return
 		}
@@ -442,9 +1071,23 @@ func (g *Game) PlayMusic(musicPath string, loop bool) {
 		return
 	}
 	// fmt.Printf("play new music %s\n", musicPath)
+	music[len(music)-1].SetVolume(g.musicVolume)
 	music[len(music)-1].Play()
 }
 
+// 获取音乐音量
+func (g *Game) GetMusicVolume() float32 {
+	return g.musicVolume
+}
+
+// 设置音乐音量，范围[0,1]，对正在播放的音乐立即生效
+func (g *Game) SetMusicVolume(volume float32) {
+	g.musicVolume = clampVolume(volume)
+	if g.assetStore != nil {
+		g.assetStore.SetAllSoundVolume(SoundTypeMusic, g.musicVolume)
+	}
+}
+
 // 播放音效
 func (g *Game) PlaySound(soundPath string, loop bool) {
 	sound, err := g.assetStore.GetSound(soundPath, SoundTypeEffect)
@@ -506,6 +1149,11 @@ func (g *Game) Quit() {
 	g.isRunning = false
 }
 
+// 是否运行中
+func (g *Game) IsRunning() bool {
+	return g.isRunning
+}
+
 // 鼠标位置是否在矩形内
 func (g *Game) IsMouseInRect(topLeft, bottomRight mgl32.Vec2) bool {
 	if g.mousePosition.X() >= topLeft.X() && g.mousePosition.X() <= bottomRight.X() &&
@@ -517,7 +1165,7 @@ func (g *Game) IsMouseInRect(topLeft, bottomRight mgl32.Vec2) bool {
 
 // 从文件中加载文本
 func (g *Game) LoadTextFromFile(filePath string) (string, error) {
-	file, err := os.Open(filePath)
+	file, err := g.GetAssetFS().Open(filePath)
 	if err != nil {
 		return "", err
 	}
@@ -532,43 +1180,301 @@ func (g *Game) LoadTextFromFile(filePath string) (string, error) {
 	return text, nil
 }
 
-// 安全切换场景
+// 安全切换场景，清空场景栈，下一次tick前执行
 func (g *Game) SafeChangeScene(scene IScene) {
-	g.nextScene = scene
+	g.sceneCommands = append(g.sceneCommands, sceneCommand{op: sceneOpChange, scene: scene})
 }
 
-// 切换场景
+// 安全切换场景并播放转场，清空场景栈，转场期间屏蔽输入
+func (g *Game) SafeTransitionScene(scene IScene, transition *Transition) {
+	g.sceneCommands = append(g.sceneCommands, sceneCommand{op: sceneOpChange, scene: scene, transition: transition})
+}
+
+// 获取正在播放的转场，没有转场返回nil
+func (g *Game) GetTransition() *Transition {
+	return g.transition
+}
+
+// 是否屏蔽输入，转场期间屏蔽
+func (g *Game) IsInputBlocked() bool {
+	return g.transition != nil
+}
+
+// 安全压入场景，下一次tick前执行
+func (g *Game) SafePushScene(scene IScene) {
+	g.sceneCommands = append(g.sceneCommands, sceneCommand{op: sceneOpPush, scene: scene})
+}
+
+// 安全弹出栈顶场景，下一次tick前执行
+func (g *Game) SafePopScene() {
+	g.sceneCommands = append(g.sceneCommands, sceneCommand{op: sceneOpPop})
+}
+
+// 安全替换栈顶场景，下一次tick前执行
+func (g *Game) SafeReplaceScene(scene IScene) {
+	g.sceneCommands = append(g.sceneCommands, sceneCommand{op: sceneOpReplace, scene: scene})
+}
+
+// 切换场景，清空场景栈
 func (g *Game) ChangeScene(scene IScene) {
-	if g.currentScene != nil {
-		g.currentScene.Clean()
+	removed := g.clearScenes()
+	g.PushScene(scene)
+	// 新场景加载完再释放，两个场景共用的素材不需要重新加载
+	g.releaseScenes(removed)
+}
+
+// 压入场景，原栈顶场景暂停
+func (g *Game) PushScene(scene IScene) {
+	if top := g.GetTopScene(); top != nil {
+		g.currentScene = top
+		top.OnPause()
+	}
+	g.sceneStack = append(g.sceneStack, scene)
+	g.enterScene(scene)
+}
+
+// 弹出栈顶场景，下层场景恢复，栈底场景不能弹出
+func (g *Game) PopScene() {
+	if len(g.sceneStack) <= 1 {
+		return
 	}
+	removed := g.exitTopScene()
+	g.currentScene = g.GetTopScene()
+	g.currentScene.OnResume()
+	g.releaseScenes([]IScene{removed})
+}
+
+// 替换栈顶场景，下层场景不受影响
+func (g *Game) ReplaceScene(scene IScene) {
+	if len(g.sceneStack) == 0 {
+		g.PushScene(scene)
+		return
+	}
+	removed := g.exitTopScene()
+	g.sceneStack = append(g.sceneStack, scene)
+	g.enterScene(scene)
+	g.releaseScenes([]IScene{removed})
+}
+
+// 初始化并进入栈顶场景
+func (g *Game) enterScene(scene IScene) {
 	g.currentScene = scene
-	g.currentScene.Init()
+	scene.Init()
+	scene.OnEnter()
+	// 新场景渲染前至少更新一次，保证插值有上一次tick的数据
+	g.accumulator = g.dt
+}
+
+// 退出并清理栈顶场景，返回退出的场景
+func (g *Game) exitTopScene() IScene {
+	top := g.GetTopScene()
+	g.currentScene = top
+	top.OnExit()
+	top.Clean()
+	g.sceneStack[len(g.sceneStack)-1] = nil
+	g.sceneStack = g.sceneStack[:len(g.sceneStack)-1]
+	g.currentScene = g.GetTopScene()
+	return top
+}
+
+// 从栈顶开始退出并清理所有场景，返回退出的场景
+func (g *Game) clearScenes() []IScene {
+	removed := make([]IScene, 0, len(g.sceneStack))
+	for len(g.sceneStack) > 0 {
+		removed = append(removed, g.exitTopScene())
+	}
+	return removed
 }
 
-// 绘制点们
+// 释放已退出场景持有的素材，重新进入栈中的场景(比如重新开始)不释放
+func (g *Game) releaseScenes(scenes []IScene) {
+	if g.assetStore == nil {
+		return
+	}
+	for _, scene := range scenes {
+		if slices.Contains(g.sceneStack, scene) {
+			continue
+		}
+		g.assetStore.ReleaseOwner(scene)
+	}
+}
+
+// 绘制点们，所有点合并成一次绘制
 func (g *Game) DrawPoints(points *[]mgl32.Vec2, renderPos mgl32.Vec2, color sdl.FColor) {
-	sdl.SetRenderDrawColorFloat(g.sdlRenderer, color.R, color.G, color.B, color.A)
 	screenRect := sdl.FRect{
 		X: 0.0,
 		Y: 0.0,
 		W: g.screenSize.X(),
 		H: g.screenSize.Y(),
 	}
+	buffer := g.pointBuffer[:0]
 	for _, p := range *points {
-		pos := sdl.FPoint{X: p.X() + renderPos.X(), Y: p.Y() + renderPos.Y()}
+		pos := g.viewFPoint(p.Add(renderPos))
 		if !sdl.PointInRectFloat(pos, screenRect) {
 			continue
 		}
-		sdl.RenderPoint(g.sdlRenderer, pos.X, pos.Y)
+		buffer = append(buffer, pos)
+	}
+	g.renderQueue.AddPoints(buffer, color)
+	g.pointBuffer = buffer[:0]
+}
+
+// 开始世界视图，之后绘制函数的坐标都是相机空间坐标，经过视图变换后绘制到屏幕
+func (g *Game) BeginWorldView(view ViewTransform) {
+	g.worldView = view
+	g.worldViewActive = true
+}
+
+// 结束世界视图，之后绘制函数的坐标都是屏幕坐标
+func (g *Game) EndWorldView() {
+	g.worldViewActive = false
+}
+
+// 获取世界视图，第二个返回值表示是否在世界视图中
+func (g *Game) GetWorldView() (ViewTransform, bool) {
+	return g.worldView, g.worldViewActive
+}
+
+// 把绘制坐标转换到屏幕坐标，不在世界视图中时不变，用于自己绘制的对象，比如文字
+func (g *Game) ViewPoint(pos mgl32.Vec2) mgl32.Vec2 {
+	if !g.inWorldView() {
+		return pos
+	}
+	return g.worldView.Apply(pos)
+}
+
+// 获取是否绘制调试信息
+func (g *Game) GetDebugDraw() bool {
+	return g.debugDraw
+}
+
+// 设置是否绘制调试信息
+func (g *Game) SetDebugDraw(debugDraw bool) {
+	g.debugDraw = debugDraw
+}
+
+// 是否需要世界视图变换，不缩放不旋转时和屏幕坐标相同，走原来的绘制
+func (g *Game) inWorldView() bool {
+	return g.worldViewActive && !g.worldView.IsIdentity()
+}
+
+// 提交渲染队列中的绘制命令，直接使用SDL绘制之前要调用，比如绘制文字
+func (g *Game) FlushRender() {
+	if g.renderQueue != nil {
+		g.renderQueue.Flush()
+	}
+}
+
+// 开始新的渲染排序组，组之间保持绘制顺序，sortable为true时组内按纹理和混合模式排序合批
+func (g *Game) BeginRenderGroup(sortable bool) {
+	if g.renderQueue != nil {
+		g.renderQueue.BeginGroup(sortable)
+	}
+}
+
+// 绘制三角形，坐标是屏幕坐标，不经过世界视图变换
+func (g *Game) DrawGeometry(texture *sdl.Texture, vertices []sdl.Vertex, indices []int32) {
+	g.renderQueue.AddGeometry(texture, vertices, indices)
+}
+
+// 获取上一帧的绘制调用次数
+func (g *Game) GetDrawCalls() int {
+	if g.renderQueue == nil {
+		return 0
+	}
+	return g.renderQueue.GetDrawCalls()
+}
+
+// 把绘制坐标转换到屏幕上的点
+func (g *Game) viewFPoint(pos mgl32.Vec2) sdl.FPoint {
+	pos = g.ViewPoint(pos)
+	return sdl.FPoint{X: pos.X(), Y: pos.Y()}
+}
+
+// 加入填充矩形，世界视图中四个角分别变换，跟着相机旋转
+func (g *Game) queueRect(topLeft, bottomRight mgl32.Vec2, color sdl.FColor) {
+	g.queueSolidQuad([4]sdl.FPoint{
+		g.viewFPoint(topLeft),
+		g.viewFPoint(mgl32.Vec2{bottomRight.X(), topLeft.Y()}),
+		g.viewFPoint(bottomRight),
+		g.viewFPoint(mgl32.Vec2{topLeft.X(), bottomRight.Y()}),
+	}, color)
+}
+
+// 加入屏幕坐标的填充矩形，不经过世界视图变换
+func (g *Game) queueScreenRect(topLeft, bottomRight mgl32.Vec2, color sdl.FColor) {
+	g.queueSolidQuad([4]sdl.FPoint{
+		{X: topLeft.X(), Y: topLeft.Y()},
+		{X: bottomRight.X(), Y: topLeft.Y()},
+		{X: bottomRight.X(), Y: bottomRight.Y()},
+		{X: topLeft.X(), Y: bottomRight.Y()},
+	}, color)
+}
+
+// 加入纯色四边形，最近使用的纹理是图集页时采样它的白色块，和前后的图片合并成一次绘制
+func (g *Game) queueSolidQuad(corners [4]sdl.FPoint, color sdl.FColor) {
+	texture := g.renderQueue.GetLastTexture()
+	uv, ok := g.assetStore.GetAtlasWhiteUV(texture)
+	if !ok {
+		texture = nil
+	}
+	g.renderQueue.AddQuad(texture, corners, uv, color)
+}
+
+// 加入纹理四边形，pos和size是屏幕上的目标矩形，angle为绕矩形中心顺时针旋转的角度，完全在屏幕外时不绘制
+func (g *Game) queueTexture(texture *sdl.Texture, srcRect sdl.FRect, pos, size mgl32.Vec2, angle float64, flip bool, color sdl.FColor) {
+	if texture == nil || texture.W <= 0 || texture.H <= 0 || size.X() <= 0.0 || size.Y() <= 0.0 {
+		return
+	}
+	half := size.Mul(0.5)
+	center := pos.Add(half)
+	offsets := [4]mgl32.Vec2{
+		{-half.X(), -half.Y()},
+		{half.X(), -half.Y()},
+		{half.X(), half.Y()},
+		{-half.X(), half.Y()},
+	}
+	var corners [4]sdl.FPoint
+	boundsMin, boundsMax := center, center
+	for i, offset := range offsets {
+		if angle != 0.0 {
+			offset = rotateVec2(offset, float32(angle))
+		}
+		corner := center.Add(offset)
+		corners[i] = sdl.FPoint{X: corner.X(), Y: corner.Y()}
+		boundsMin = mgl32.Vec2{min(boundsMin.X(), corner.X()), min(boundsMin.Y(), corner.Y())}
+		boundsMax = mgl32.Vec2{max(boundsMax.X(), corner.X()), max(boundsMax.Y(), corner.Y())}
+	}
+	if boundsMax.X() < 0.0 || boundsMax.Y() < 0.0 || boundsMin.X() > g.screenSize.X() || boundsMin.Y() > g.screenSize.Y() {
+		return
+	}
+	// 纹理坐标是归一化的，水平翻转时左右交换
+	uv := sdl.FRect{
+		X: srcRect.X / float32(texture.W),
+		Y: srcRect.Y / float32(texture.H),
+		W: srcRect.W / float32(texture.W),
+		H: srcRect.H / float32(texture.H),
 	}
-	sdl.SetRenderDrawColorFloat(g.sdlRenderer, 0, 0, 0, 1)
+	if flip {
+		uv.X += uv.W
+		uv.W = -uv.W
+	}
+	g.renderQueue.AddQuad(texture, corners, uv, color)
+}
+
+// 更新键盘状态
+func (g *Game) updateKeyboard() {
+	g.keyboardState = sdl.GetKeyboardState()
 }
 
 // 鼠标从物理坐标转换到游戏逻辑坐标
 func (g *Game) updateMouse() {
 	// 限制比例，不要出现黑边(letterbox)
 	g.mouseButtons = sdl.GetMouseState(&g.mousePosition[0], &g.mousePosition[1])
+	// 无头模式没有窗口，不需要转换
+	if g.sdlWindow == nil {
+		return
+	}
 	// 获取当前物理窗口大小
 	w, h := int32(0), int32(0)
 	sdl.GetWindowSize(g.sdlWindow, &w, &h)
@@ -0,0 +1,155 @@
+package core
+
+import (
+	"github.com/SunshineZzzz/purego-sdl3/sdl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	// 摇杆默认死区
+	GamepadDeadzone = 0.2
+	// 手柄轴作为按键时的触发阈值
+	GamepadAxisThreshold = 0.5
+)
+
+// 手柄管理器
+// 手柄状态完全由SDL手柄事件驱动，轴和按钮事件会被录像记录，回放时状态保持一致
+type GamepadManager struct {
+	// 已连接的手柄
+	gamepads map[sdl.JoystickID]*sdl.Gamepad
+	// 当前使用的手柄，最近一次产生输入的手柄
+	active sdl.JoystickID
+	// 当前手柄轴的值
+	axes [sdl.GamepadAxisCount]int16
+	// 当前手柄按钮是否按下
+	buttons [sdl.GamepadButtonCount]bool
+	// 摇杆死区
+	deadzone float32
+}
+
+// 创建手柄管理器
+func CreateGamepadManager() *GamepadManager {
+	return &GamepadManager{
+		gamepads: make(map[sdl.JoystickID]*sdl.Gamepad),
+		deadzone: GamepadDeadzone,
+	}
+}
+
+// 清理，关闭所有手柄
+func (m *GamepadManager) Clean() {
+	for _, gamepad := range m.gamepads {
+		if gamepad != nil {
+			sdl.CloseGamepad(gamepad)
+		}
+	}
+	m.gamepads = make(map[sdl.JoystickID]*sdl.Gamepad)
+	m.reset()
+}
+
+// 处理手柄事件，包括热插拔
+func (m *GamepadManager) HandleEvent(event *sdl.Event) {
+	switch event.Type() {
+	case sdl.EventGamepadAdded:
+		which := event.GDevice().Which
+		if _, ok := m.gamepads[which]; ok {
+			return
+		}
+		// 打开失败也记录下来，状态由轴和按钮事件驱动
+		m.gamepads[which] = sdl.OpenGamepad(which)
+	case sdl.EventGamepadRemoved:
+		which := event.GDevice().Which
+		if gamepad, ok := m.gamepads[which]; ok && gamepad != nil {
+			sdl.CloseGamepad(gamepad)
+		}
+		delete(m.gamepads, which)
+		if which == m.active {
+			m.reset()
+		}
+	case sdl.EventGamepadAxisMotion:
+		axis := event.GAxis()
+		if int(axis.Axis) >= len(m.axes) {
+			return
+		}
+		m.setActive(axis.Which)
+		m.axes[axis.Axis] = axis.Value
+	case sdl.EventGamepadButtonDown, sdl.EventGamepadButtonUp:
+		button := event.GButton()
+		if int(button.Button) >= len(m.buttons) {
+			return
+		}
+		m.setActive(button.Which)
+		m.buttons[button.Button] = button.Down
+	}
+}
+
+// 切换当前使用的手柄，换手柄时清空上一个手柄的状态
+func (m *GamepadManager) setActive(which sdl.JoystickID) {
+	if which == m.active {
+		return
+	}
+	m.reset()
+	m.active = which
+}
+
+// 重置手柄状态
+func (m *GamepadManager) reset() {
+	m.active = 0
+	m.axes = [sdl.GamepadAxisCount]int16{}
+	m.buttons = [sdl.GamepadButtonCount]bool{}
+}
+
+// 是否有手柄连接
+func (m *GamepadManager) IsConnected() bool {
+	return len(m.gamepads) > 0
+}
+
+// 获取已连接手柄数量
+func (m *GamepadManager) GetCount() int {
+	return len(m.gamepads)
+}
+
+// 获取死区
+func (m *GamepadManager) GetDeadzone() float32 {
+	return m.deadzone
+}
+
+// 设置死区
+func (m *GamepadManager) SetDeadzone(deadzone float32) {
+	m.deadzone = mgl32.Clamp(deadzone, 0.0, 0.95)
+}
+
+// 获取手柄轴的值，范围[-1,1]，扳机为[0,1]
+func (m *GamepadManager) GetAxis(axis sdl.GamepadAxis) float32 {
+	if axis < 0 || axis >= sdl.GamepadAxisCount {
+		return 0.0
+	}
+	return mgl32.Clamp(float32(m.axes[axis])/32767.0, -1.0, 1.0)
+}
+
+// 手柄按钮是否按下
+func (m *GamepadManager) IsButtonDown(button sdl.GamepadButton) bool {
+	if button < 0 || button >= sdl.GamepadButtonCount {
+		return false
+	}
+	return m.buttons[button]
+}
+
+// 获取左摇杆，已经处理死区
+func (m *GamepadManager) GetLeftStick() mgl32.Vec2 {
+	return m.applyDeadzone(mgl32.Vec2{m.GetAxis(sdl.GamepadAxisLeftX), m.GetAxis(sdl.GamepadAxisLeftY)})
+}
+
+// 获取右摇杆，已经处理死区
+func (m *GamepadManager) GetRightStick() mgl32.Vec2 {
+	return m.applyDeadzone(mgl32.Vec2{m.GetAxis(sdl.GamepadAxisRightX), m.GetAxis(sdl.GamepadAxisRightY)})
+}
+
+// 径向死区，死区外的部分重新映射到[0,1]，保证推杆刚出死区时速度从0开始
+func (m *GamepadManager) applyDeadzone(stick mgl32.Vec2) mgl32.Vec2 {
+	length := stick.Len()
+	if length <= m.deadzone {
+		return mgl32.Vec2{0.0, 0.0}
+	}
+	scale := min((length-m.deadzone)/(1.0-m.deadzone), 1.0)
+	return stick.Mul(scale / length)
+}
@@ -0,0 +1,258 @@
+package core
+
+import (
+	"slices"
+	"testing"
+	"unsafe"
+
+	"github.com/SunshineZzzz/purego-sdl3/sdl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// 绑定库没有导出SDL虚拟手柄接口，这里直接构造手柄事件，和真实手柄走同样的HandleEvent流程
+
+// 创建手柄连接或者断开事件
+func makeGamepadDeviceEvent(eventType sdl.EventType, which sdl.JoystickID) sdl.Event {
+	var event sdl.Event
+	*(*sdl.GamepadDeviceEvent)(unsafe.Pointer(&event)) = sdl.GamepadDeviceEvent{
+		CommonEvent: sdl.CommonEvent{Type: eventType},
+		Which:       which,
+	}
+	return event
+}
+
+// 创建手柄轴事件
+func makeGamepadAxisEvent(which sdl.JoystickID, axis sdl.GamepadAxis, value int16) sdl.Event {
+	var event sdl.Event
+	*(*sdl.GamepadAxisEvent)(unsafe.Pointer(&event)) = sdl.GamepadAxisEvent{
+		CommonEvent: sdl.CommonEvent{Type: sdl.EventGamepadAxisMotion},
+		Which:       which,
+		Axis:        uint8(axis),
+		Value:       value,
+	}
+	return event
+}
+
+// 创建手柄按钮事件
+func makeGamepadButtonEvent(which sdl.JoystickID, button sdl.GamepadButton, down bool) sdl.Event {
+	var event sdl.Event
+	eventType := sdl.EventGamepadButtonUp
+	if down {
+		eventType = sdl.EventGamepadButtonDown
+	}
+	*(*sdl.GamepadButtonEvent)(unsafe.Pointer(&event)) = sdl.GamepadButtonEvent{
+		CommonEvent: sdl.CommonEvent{Type: eventType},
+		Which:       which,
+		Button:      uint8(button),
+		Down:        down,
+	}
+	return event
+}
+
+// 依次处理事件
+func handleGamepadEvents(m *GamepadManager, events ...sdl.Event) {
+	for i := range events {
+		m.HandleEvent(&events[i])
+	}
+}
+
+// 比较两个向量
+func vec2Equal(a, b mgl32.Vec2) bool {
+	return mgl32.FloatEqualThreshold(a[0], b[0], 1e-4) && mgl32.FloatEqualThreshold(a[1], b[1], 1e-4)
+}
+
+func TestGamepadApplyDeadzone(t *testing.T) {
+	tests := []struct {
+		name  string
+		stick mgl32.Vec2
+		want  mgl32.Vec2
+	}{
+		{"center", mgl32.Vec2{0.0, 0.0}, mgl32.Vec2{0.0, 0.0}},
+		{"inside", mgl32.Vec2{0.1, 0.1}, mgl32.Vec2{0.0, 0.0}},
+		{"edge", mgl32.Vec2{0.2, 0.0}, mgl32.Vec2{0.0, 0.0}},
+		// 死区外重新映射到[0,1]，方向不变
+		{"half", mgl32.Vec2{0.6, 0.0}, mgl32.Vec2{0.5, 0.0}},
+		{"half up", mgl32.Vec2{0.0, -0.6}, mgl32.Vec2{0.0, -0.5}},
+		{"full", mgl32.Vec2{1.0, 0.0}, mgl32.Vec2{1.0, 0.0}},
+		// 方形摇杆的角落长度超过1，限制为1
+		{"corner", mgl32.Vec2{1.0, 1.0}, mgl32.Vec2{0.70710677, 0.70710677}},
+	}
+	m := CreateGamepadManager()
+	for _, tt := range tests {
+		if got := m.applyDeadzone(tt.stick); !vec2Equal(got, tt.want) {
+			t.Errorf("%s: applyDeadzone(%v) = %v, want %v", tt.name, tt.stick, got, tt.want)
+		}
+	}
+
+	// 死区为0时原样返回
+	m.SetDeadzone(0.0)
+	if got := m.applyDeadzone(mgl32.Vec2{0.3, -0.4}); !vec2Equal(got, mgl32.Vec2{0.3, -0.4}) {
+		t.Errorf("no deadzone: %v", got)
+	}
+}
+
+func TestGamepadSetDeadzone(t *testing.T) {
+	m := CreateGamepadManager()
+	for _, tt := range []struct{ deadzone, want float32 }{{-1.0, 0.0}, {0.3, 0.3}, {2.0, 0.95}} {
+		m.SetDeadzone(tt.deadzone)
+		if got := m.GetDeadzone(); got != tt.want {
+			t.Errorf("SetDeadzone(%v): %v, want %v", tt.deadzone, got, tt.want)
+		}
+	}
+}
+
+func TestGamepadHotPlug(t *testing.T) {
+	m := CreateGamepadManager()
+	if m.IsConnected() {
+		t.Fatal("connected before any gamepad added")
+	}
+	// 重复的连接事件只记录一次
+	handleGamepadEvents(m,
+		makeGamepadDeviceEvent(sdl.EventGamepadAdded, 1),
+		makeGamepadDeviceEvent(sdl.EventGamepadAdded, 1),
+		makeGamepadDeviceEvent(sdl.EventGamepadAdded, 2),
+	)
+	if got := m.GetCount(); got != 2 {
+		t.Errorf("count = %d, want 2", got)
+	}
+
+	handleGamepadEvents(m,
+		makeGamepadAxisEvent(1, sdl.GamepadAxisLeftX, 32767),
+		makeGamepadButtonEvent(1, sdl.GamepadButtonSouth, true),
+	)
+	// 断开的不是当前手柄，状态保留
+	handleGamepadEvents(m, makeGamepadDeviceEvent(sdl.EventGamepadRemoved, 2))
+	if m.GetCount() != 1 || m.GetAxis(sdl.GamepadAxisLeftX) != 1.0 || !m.IsButtonDown(sdl.GamepadButtonSouth) {
+		t.Error("removing another gamepad changed the active state")
+	}
+	// 断开当前手柄，状态清空，按着的按钮不会卡住
+	handleGamepadEvents(m, makeGamepadDeviceEvent(sdl.EventGamepadRemoved, 1))
+	if m.IsConnected() || m.GetAxis(sdl.GamepadAxisLeftX) != 0.0 || m.IsButtonDown(sdl.GamepadButtonSouth) {
+		t.Error("state kept after the active gamepad was removed")
+	}
+	// 没有连接过的手柄断开时忽略
+	handleGamepadEvents(m, makeGamepadDeviceEvent(sdl.EventGamepadRemoved, 3))
+	if m.GetCount() != 0 {
+		t.Errorf("count = %d after unknown removal", m.GetCount())
+	}
+}
+
+func TestGamepadActiveSwitch(t *testing.T) {
+	m := CreateGamepadManager()
+	handleGamepadEvents(m,
+		makeGamepadDeviceEvent(sdl.EventGamepadAdded, 1),
+		makeGamepadDeviceEvent(sdl.EventGamepadAdded, 2),
+		makeGamepadAxisEvent(1, sdl.GamepadAxisLeftY, -32768),
+		makeGamepadButtonEvent(1, sdl.GamepadButtonStart, true),
+	)
+	// 另一个手柄产生输入时切换过去，上一个手柄的状态清空
+	handleGamepadEvents(m, makeGamepadAxisEvent(2, sdl.GamepadAxisRightX, 16384))
+	if m.GetAxis(sdl.GamepadAxisLeftY) != 0.0 || m.IsButtonDown(sdl.GamepadButtonStart) {
+		t.Error("previous gamepad state kept after switching")
+	}
+	if got := m.GetAxis(sdl.GamepadAxisRightX); !mgl32.FloatEqualThreshold(got, 0.5, 1e-3) {
+		t.Errorf("right x = %v, want 0.5", got)
+	}
+	// 同一个手柄的输入不会清空状态
+	handleGamepadEvents(m, makeGamepadButtonEvent(2, sdl.GamepadButtonSouth, true))
+	if !m.IsButtonDown(sdl.GamepadButtonSouth) || m.GetAxis(sdl.GamepadAxisRightX) == 0.0 {
+		t.Error("state lost on input from the same gamepad")
+	}
+	handleGamepadEvents(m, makeGamepadButtonEvent(2, sdl.GamepadButtonSouth, false))
+	if m.IsButtonDown(sdl.GamepadButtonSouth) {
+		t.Error("button still down after release")
+	}
+}
+
+func TestGamepadAxisAndButtonState(t *testing.T) {
+	m := CreateGamepadManager()
+	handleGamepadEvents(m,
+		makeGamepadAxisEvent(1, sdl.GamepadAxisLeftX, -32768),
+		makeGamepadAxisEvent(1, sdl.GamepadAxisLeftY, 16384),
+		makeGamepadAxisEvent(1, sdl.GamepadAxisRightTrigger, 32767),
+		// 超出范围的轴和按钮忽略
+		makeGamepadAxisEvent(1, sdl.GamepadAxisCount, 32767),
+		makeGamepadButtonEvent(1, sdl.GamepadButtonCount, true),
+	)
+	tests := []struct {
+		axis sdl.GamepadAxis
+		want float32
+	}{
+		// -32768除以32767会超过-1，限制在[-1,1]
+		{sdl.GamepadAxisLeftX, -1.0},
+		{sdl.GamepadAxisLeftY, 0.5},
+		{sdl.GamepadAxisRightTrigger, 1.0},
+		{sdl.GamepadAxisLeftTrigger, 0.0},
+		{sdl.GamepadAxisCount, 0.0},
+		{-1, 0.0},
+	}
+	for _, tt := range tests {
+		if got := m.GetAxis(tt.axis); !mgl32.FloatEqualThreshold(got, tt.want, 1e-3) {
+			t.Errorf("axis %d = %v, want %v", tt.axis, got, tt.want)
+		}
+	}
+	if m.IsButtonDown(-1) || m.IsButtonDown(sdl.GamepadButtonCount) {
+		t.Error("out of range button down")
+	}
+	// 摇杆经过死区处理
+	if got := m.GetLeftStick(); !vec2Equal(got, mgl32.Vec2{-1.0, 0.5}.Normalize()) {
+		t.Errorf("left stick = %v", got)
+	}
+	if got := m.GetRightStick(); !vec2Equal(got, mgl32.Vec2{0.0, 0.0}) {
+		t.Errorf("right stick = %v", got)
+	}
+}
+
+func TestGamepadActionBindings(t *testing.T) {
+	m := CreateInputMap()
+	gamepad := CreateGamepadManager()
+	steps := []struct {
+		name   string
+		events []sdl.Event
+		// 这次tick按下的动作
+		pressed []Action
+		// 这次tick刚刚按下的动作
+		justPressed []Action
+	}{
+		{"idle", nil, nil, nil},
+		// 扳机超过阈值才算按下
+		{"light trigger", []sdl.Event{makeGamepadAxisEvent(1, sdl.GamepadAxisRightTrigger, 10000)}, nil, nil},
+		{"full trigger", []sdl.Event{makeGamepadAxisEvent(1, sdl.GamepadAxisRightTrigger, 30000)}, []Action{ActionAttack}, []Action{ActionAttack}},
+		// 松开扳机同时按下肩键，按钮按下事件会记录为刚刚按下
+		{"shoulder", []sdl.Event{
+			makeGamepadAxisEvent(1, sdl.GamepadAxisRightTrigger, 0),
+			makeGamepadButtonEvent(1, sdl.GamepadButtonRightShoulder, true),
+			makeGamepadButtonEvent(1, sdl.GamepadButtonLeftShoulder, true),
+		}, []Action{ActionAttack, ActionSlowTime}, []Action{ActionAttack, ActionSlowTime}},
+		// 摇杆推到底用于菜单导航
+		{"stick up", []sdl.Event{
+			makeGamepadButtonEvent(1, sdl.GamepadButtonRightShoulder, false),
+			makeGamepadButtonEvent(1, sdl.GamepadButtonLeftShoulder, false),
+			makeGamepadAxisEvent(1, sdl.GamepadAxisLeftY, -30000),
+		}, []Action{ActionMenuUp}, []Action{ActionMenuUp}},
+		{"stick held", nil, []Action{ActionMenuUp}, nil},
+		// 两次tick之间按下又松开的按钮由事件记录
+		{"quick confirm", []sdl.Event{
+			makeGamepadAxisEvent(1, sdl.GamepadAxisLeftY, 0),
+			makeGamepadButtonEvent(1, sdl.GamepadButtonSouth, true),
+			makeGamepadButtonEvent(1, sdl.GamepadButtonSouth, false),
+		}, nil, []Action{ActionMenuConfirm}},
+		{"dpad", []sdl.Event{makeGamepadButtonEvent(1, sdl.GamepadButtonDpadDown, true)}, []Action{ActionMoveDown, ActionMenuDown}, []Action{ActionMoveDown, ActionMenuDown}},
+	}
+	for _, step := range steps {
+		for i := range step.events {
+			gamepad.HandleEvent(&step.events[i])
+			m.HandleEvent(&step.events[i])
+		}
+		m.Update(nil, 0, gamepad)
+		for action := range actionCount {
+			if got, want := m.IsActionPressed(action), slices.Contains(step.pressed, action); got != want {
+				t.Errorf("%s: %s pressed = %v", step.name, action, got)
+			}
+			if got, want := m.WasActionJustPressed(action), slices.Contains(step.justPressed, action); got != want {
+				t.Errorf("%s: %s just pressed = %v", step.name, action, got)
+			}
+		}
+		m.ClearLatched()
+	}
+}
@@ -0,0 +1,435 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/SunshineZzzz/purego-sdl3/sdl"
+)
+
+const (
+	// 按键绑定配置文件
+	InputConfigPath = "assets/input.json"
+	// 鼠标按钮绑定名称前缀
+	inputMousePrefix = "Mouse "
+	// 手柄绑定名称前缀
+	inputGamepadPrefix = "Gamepad "
+)
+
+// 输入动作
+type Action int
+
+const (
+	// 向上移动
+	ActionMoveUp Action = iota
+	// 向下移动
+	ActionMoveDown
+	// 向左移动
+	ActionMoveLeft
+	// 向右移动
+	ActionMoveRight
+	// 攻击
+	ActionAttack
+	// 时间减速
+	ActionSlowTime
+	// 暂停
+	ActionPause
+	// 菜单向上
+	ActionMenuUp
+	// 菜单向下
+	ActionMenuDown
+	// 菜单向左
+	ActionMenuLeft
+	// 菜单向右
+	ActionMenuRight
+	// 菜单确认
+	ActionMenuConfirm
+	// 菜单返回
+	ActionMenuBack
+	// 动作数量
+	actionCount
+)
+
+// 动作名称，用于配置文件
+var actionNames = [actionCount]string{
+	ActionMoveUp:      "MoveUp",
+	ActionMoveDown:    "MoveDown",
+	ActionMoveLeft:    "MoveLeft",
+	ActionMoveRight:   "MoveRight",
+	ActionAttack:      "Attack",
+	ActionSlowTime:    "SlowTime",
+	ActionPause:       "Pause",
+	ActionMenuUp:      "MenuUp",
+	ActionMenuDown:    "MenuDown",
+	ActionMenuLeft:    "MenuLeft",
+	ActionMenuRight:   "MenuRight",
+	ActionMenuConfirm: "MenuConfirm",
+	ActionMenuBack:    "MenuBack",
+}
+
+// 鼠标按钮名称，用于配置文件
+var mouseButtonNames = map[sdl.MouseButtonFlags]string{
+	sdl.ButtonLeft:   "Left",
+	sdl.ButtonMiddle: "Middle",
+	sdl.ButtonRight:  "Right",
+	sdl.ButtonX1:     "X1",
+	sdl.ButtonX2:     "X2",
+}
+
+// 手柄按钮名称，用于配置文件
+var gamepadButtonNames = map[sdl.GamepadButton]string{
+	sdl.GamepadButtonSouth:         "South",
+	sdl.GamepadButtonEast:          "East",
+	sdl.GamepadButtonWest:          "West",
+	sdl.GamepadButtonNorth:         "North",
+	sdl.GamepadButtonBack:          "Back",
+	sdl.GamepadButtonGuide:         "Guide",
+	sdl.GamepadButtonStart:         "Start",
+	sdl.GamepadButtonLeftStick:     "LeftStick",
+	sdl.GamepadButtonRightStick:    "RightStick",
+	sdl.GamepadButtonLeftShoulder:  "LeftShoulder",
+	sdl.GamepadButtonRightShoulder: "RightShoulder",
+	sdl.GamepadButtonDpadUp:        "DpadUp",
+	sdl.GamepadButtonDpadDown:      "DpadDown",
+	sdl.GamepadButtonDpadLeft:      "DpadLeft",
+	sdl.GamepadButtonDpadRight:     "DpadRight",
+}
+
+// 手柄轴名称，用于配置文件，摇杆需要带+/-方向前缀
+var gamepadAxisNames = map[sdl.GamepadAxis]string{
+	sdl.GamepadAxisLeftX:        "LeftX",
+	sdl.GamepadAxisLeftY:        "LeftY",
+	sdl.GamepadAxisRightX:       "RightX",
+	sdl.GamepadAxisRightY:       "RightY",
+	sdl.GamepadAxisLeftTrigger:  "LeftTrigger",
+	sdl.GamepadAxisRightTrigger: "RightTrigger",
+}
+
+// 获取动作名称
+func (a Action) String() string {
+	if a < 0 || a >= actionCount {
+		return fmt.Sprintf("Action(%d)", int(a))
+	}
+	return actionNames[a]
+}
+
+// 输入设备
+type InputDevice int
+
+const (
+	// 键盘，Code为扫描码
+	InputDeviceKeyboard InputDevice = iota
+	// 鼠标，Code为鼠标按钮
+	InputDeviceMouse
+	// 手柄按钮，Code为手柄按钮
+	InputDeviceGamepadButton
+	// 手柄轴，Code为手柄轴，超过阈值视为按下
+	InputDeviceGamepadAxis
+)
+
+// 按键绑定
+type InputBinding struct {
+	// 输入设备
+	Device InputDevice
+	// 按键编码
+	Code uint32
+	// 手柄轴方向，1为正方向，-1为负方向，其他设备为0
+	AxisDirection int8
+}
+
+// 创建键盘绑定
+func KeyBinding(scancode sdl.Scancode) InputBinding {
+	return InputBinding{Device: InputDeviceKeyboard, Code: uint32(scancode)}
+}
+
+// 创建鼠标绑定
+func MouseBinding(button sdl.MouseButtonFlags) InputBinding {
+	return InputBinding{Device: InputDeviceMouse, Code: uint32(button)}
+}
+
+// 创建手柄按钮绑定
+func GamepadButtonBinding(button sdl.GamepadButton) InputBinding {
+	return InputBinding{Device: InputDeviceGamepadButton, Code: uint32(button)}
+}
+
+// 创建手柄轴绑定，direction为1或者-1
+func GamepadAxisBinding(axis sdl.GamepadAxis, direction int8) InputBinding {
+	return InputBinding{Device: InputDeviceGamepadAxis, Code: uint32(axis), AxisDirection: direction}
+}
+
+// 绑定名称，键盘为SDL扫描码名称，鼠标为"Mouse 按钮名"，手柄为"Gamepad 按钮名"或者"Gamepad +轴名"
+func (b InputBinding) String() string {
+	switch b.Device {
+	case InputDeviceKeyboard:
+		return sdl.GetScancodeName(sdl.Scancode(b.Code))
+	case InputDeviceMouse:
+		return inputMousePrefix + mouseButtonNames[sdl.MouseButtonFlags(b.Code)]
+	case InputDeviceGamepadButton:
+		return inputGamepadPrefix + gamepadButtonNames[sdl.GamepadButton(b.Code)]
+	case InputDeviceGamepadAxis:
+		sign := "+"
+		if b.AxisDirection < 0 {
+			sign = "-"
+		}
+		return inputGamepadPrefix + sign + gamepadAxisNames[sdl.GamepadAxis(b.Code)]
+	default:
+		return ""
+	}
+}
+
+// 解析手柄绑定名称
+func parseGamepadBinding(name string) (InputBinding, error) {
+	direction := int8(0)
+	if axisName, ok := strings.CutPrefix(name, "+"); ok {
+		name, direction = axisName, 1
+	} else if axisName, ok := strings.CutPrefix(name, "-"); ok {
+		name, direction = axisName, -1
+	}
+	for axis, n := range gamepadAxisNames {
+		if strings.EqualFold(n, name) {
+			// 扳机不需要写方向
+			if direction == 0 {
+				direction = 1
+			}
+			return GamepadAxisBinding(axis, direction), nil
+		}
+	}
+	if direction != 0 {
+		return InputBinding{}, fmt.Errorf("unknown gamepad axis %q", name)
+	}
+	for button, n := range gamepadButtonNames {
+		if strings.EqualFold(n, name) {
+			return GamepadButtonBinding(button), nil
+		}
+	}
+	return InputBinding{}, fmt.Errorf("unknown gamepad button %q", name)
+}
+
+// 解析绑定名称
+func ParseInputBinding(name string) (InputBinding, error) {
+	if gamepadName, ok := strings.CutPrefix(name, inputGamepadPrefix); ok {
+		return parseGamepadBinding(gamepadName)
+	}
+	if buttonName, ok := strings.CutPrefix(name, inputMousePrefix); ok {
+		for button, n := range mouseButtonNames {
+			if strings.EqualFold(n, buttonName) {
+				return MouseBinding(button), nil
+			}
+		}
+		return InputBinding{}, fmt.Errorf("unknown mouse button %q", buttonName)
+	}
+	scancode := sdl.GetScancodeFromName(name)
+	if scancode == sdl.ScancodeUnknown {
+		return InputBinding{}, fmt.Errorf("unknown key %q", name)
+	}
+	return KeyBinding(scancode), nil
+}
+
+// 输入动作映射
+type InputMap struct {
+	// 每个动作的绑定
+	bindings [actionCount][]InputBinding
+	// 当前tick动作是否按下
+	pressed [actionCount]bool
+	// 上一次tick动作是否按下
+	prevPressed [actionCount]bool
+	// 两次tick之间通过事件按下过的动作，防止快速点击在轮询中丢失
+	latched [actionCount]bool
+	// 是否屏蔽输入，屏蔽时仍然跟踪按键状态，解除后不会产生多余的按下
+	blocked bool
+}
+
+// 创建输入动作映射，使用默认绑定
+func CreateInputMap() *InputMap {
+	m := &InputMap{}
+	m.ResetDefault()
+	return m
+}
+
+// 恢复默认绑定
+func (m *InputMap) ResetDefault() {
+	m.bindings = [actionCount][]InputBinding{
+		ActionMoveUp:      {KeyBinding(sdl.ScancodeW), KeyBinding(sdl.ScancodeUp), GamepadButtonBinding(sdl.GamepadButtonDpadUp)},
+		ActionMoveDown:    {KeyBinding(sdl.ScancodeS), KeyBinding(sdl.ScancodeDown), GamepadButtonBinding(sdl.GamepadButtonDpadDown)},
+		ActionMoveLeft:    {KeyBinding(sdl.ScancodeA), KeyBinding(sdl.ScancodeLeft), GamepadButtonBinding(sdl.GamepadButtonDpadLeft)},
+		ActionMoveRight:   {KeyBinding(sdl.ScancodeD), KeyBinding(sdl.ScancodeRight), GamepadButtonBinding(sdl.GamepadButtonDpadRight)},
+		ActionAttack:      {MouseBinding(sdl.ButtonLeft), GamepadAxisBinding(sdl.GamepadAxisRightTrigger, 1), GamepadButtonBinding(sdl.GamepadButtonRightShoulder)},
+		ActionSlowTime:    {MouseBinding(sdl.ButtonRight), GamepadAxisBinding(sdl.GamepadAxisLeftTrigger, 1), GamepadButtonBinding(sdl.GamepadButtonLeftShoulder)},
+		ActionPause:       {KeyBinding(sdl.ScancodeEscape), KeyBinding(sdl.ScancodeP), GamepadButtonBinding(sdl.GamepadButtonStart)},
+		ActionMenuUp:      {KeyBinding(sdl.ScancodeUp), GamepadButtonBinding(sdl.GamepadButtonDpadUp), GamepadAxisBinding(sdl.GamepadAxisLeftY, -1)},
+		ActionMenuDown:    {KeyBinding(sdl.ScancodeDown), GamepadButtonBinding(sdl.GamepadButtonDpadDown), GamepadAxisBinding(sdl.GamepadAxisLeftY, 1)},
+		ActionMenuLeft:    {KeyBinding(sdl.ScancodeLeft), GamepadButtonBinding(sdl.GamepadButtonDpadLeft), GamepadAxisBinding(sdl.GamepadAxisLeftX, -1)},
+		ActionMenuRight:   {KeyBinding(sdl.ScancodeRight), GamepadButtonBinding(sdl.GamepadButtonDpadRight), GamepadAxisBinding(sdl.GamepadAxisLeftX, 1)},
+		ActionMenuConfirm: {KeyBinding(sdl.ScancodeReturn), KeyBinding(sdl.ScancodeSpace), GamepadButtonBinding(sdl.GamepadButtonSouth)},
+		ActionMenuBack:    {KeyBinding(sdl.ScancodeBackspace), GamepadButtonBinding(sdl.GamepadButtonEast)},
+	}
+}
+
+// 从配置文件加载绑定，配置中没有的动作保持原来的绑定
+func (m *InputMap) Load(fsys fs.FS, filePath string) error {
+	data, err := fs.ReadFile(fsys, filePath)
+	if err != nil {
+		return err
+	}
+	config := make(map[string][]string)
+	if err = json.Unmarshal(data, &config); err != nil {
+		return fmt.Errorf("load input config error,%v", err)
+	}
+	for action := range actionCount {
+		names, ok := config[action.String()]
+		if !ok {
+			continue
+		}
+		bindings := make([]InputBinding, 0, len(names))
+		for _, name := range names {
+			binding, err := ParseInputBinding(name)
+			if err != nil {
+				return fmt.Errorf("load input config error,%s: %v", action, err)
+			}
+			bindings = append(bindings, binding)
+		}
+		m.bindings[action] = bindings
+	}
+	return nil
+}
+
+// 保存绑定到配置文件，filePath是系统路径，游戏中通过SaveConfig写到可写目录
+func (m *InputMap) Save(filePath string) error {
+	config := make(map[string][]string)
+	for action := range actionCount {
+		names := make([]string, 0, len(m.bindings[action]))
+		for _, binding := range m.bindings[action] {
+			names = append(names, binding.String())
+		}
+		config[action.String()] = names
+	}
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return fmt.Errorf("save input config error,%v", err)
+	}
+	if err = os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return fmt.Errorf("save input config error,%v", err)
+	}
+	if err = os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("save input config error,%v", err)
+	}
+	return nil
+}
+
+// 加载按键绑定，先读可写目录中保存的配置，没有时读素材中的默认配置
+// 可写目录不在素材文件系统中时，素材包和编译进程序的素材里的配置不会覆盖玩家修改过的绑定
+func (m *InputMap) LoadConfig(assetFS *AssetFS) error {
+	err := m.Load(os.DirFS(assetFS.GetBaseDir()), InputConfigPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		err = m.Load(assetFS, InputConfigPath)
+	}
+	return err
+}
+
+// 保存按键绑定到可写目录，下次通过LoadConfig读回
+func (m *InputMap) SaveConfig(assetFS *AssetFS) error {
+	return m.Save(assetFS.GetWritePath(InputConfigPath))
+}
+
+// 获取动作绑定
+func (m *InputMap) GetBindings(action Action) []InputBinding {
+	return m.bindings[action]
+}
+
+// 设置动作绑定
+func (m *InputMap) SetBindings(action Action, bindings ...InputBinding) {
+	m.bindings[action] = bindings
+}
+
+// 处理事件，记录两次tick之间按下过的动作
+func (m *InputMap) HandleEvent(event *sdl.Event) {
+	if m.blocked {
+		return
+	}
+	var binding InputBinding
+	switch event.Type() {
+	case sdl.EventKeyDown:
+		if event.Key().Repeat {
+			return
+		}
+		binding = KeyBinding(event.Key().Scancode)
+	case sdl.EventMouseButtonDown:
+		binding = MouseBinding(sdl.MouseButtonFlags(event.Button().Button))
+	case sdl.EventGamepadButtonDown:
+		binding = GamepadButtonBinding(sdl.GamepadButton(event.GButton().Button))
+	default:
+		return
+	}
+	for action := range actionCount {
+		for _, b := range m.bindings[action] {
+			if b == binding {
+				m.latched[action] = true
+			}
+		}
+	}
+}
+
+// 每次tick根据键盘、鼠标和手柄状态更新动作状态
+func (m *InputMap) Update(keyboardState []bool, mouseButtons sdl.MouseButtonFlags, gamepad *GamepadManager) {
+	m.prevPressed = m.pressed
+	for action := range actionCount {
+		m.pressed[action] = false
+		for _, b := range m.bindings[action] {
+			if m.isBindingDown(b, keyboardState, mouseButtons, gamepad) {
+				m.pressed[action] = true
+				break
+			}
+		}
+	}
+}
+
+// 清除事件记录的按下，每次tick结束调用
+func (m *InputMap) ClearLatched() {
+	m.latched = [actionCount]bool{}
+}
+
+// 绑定是否按下
+func (m *InputMap) isBindingDown(b InputBinding, keyboardState []bool, mouseButtons sdl.MouseButtonFlags, gamepad *GamepadManager) bool {
+	switch b.Device {
+	case InputDeviceKeyboard:
+		return int(b.Code) < len(keyboardState) && keyboardState[b.Code]
+	case InputDeviceMouse:
+		return mouseButtons&(1<<(b.Code-1)) != 0
+	case InputDeviceGamepadButton:
+		return gamepad != nil && gamepad.IsButtonDown(sdl.GamepadButton(b.Code))
+	case InputDeviceGamepadAxis:
+		return gamepad != nil && gamepad.GetAxis(sdl.GamepadAxis(b.Code))*float32(b.AxisDirection) > GamepadAxisThreshold
+	default:
+		return false
+	}
+}
+
+// 动作是否按下
+func (m *InputMap) IsActionPressed(action Action) bool {
+	return !m.blocked && m.pressed[action]
+}
+
+// 动作是否在这次tick刚刚按下
+func (m *InputMap) WasActionJustPressed(action Action) bool {
+	return !m.blocked && ((m.pressed[action] && !m.prevPressed[action]) || m.latched[action])
+}
+
+// 动作是否在这次tick刚刚松开
+func (m *InputMap) WasActionJustReleased(action Action) bool {
+	return !m.blocked && !m.pressed[action] && m.prevPressed[action]
+}
+
+// 获取是否屏蔽输入
+func (m *InputMap) GetBlocked() bool {
+	return m.blocked
+}
+
+// 设置是否屏蔽输入
+func (m *InputMap) SetBlocked(blocked bool) {
+	m.blocked = blocked
+}
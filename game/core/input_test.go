@@ -0,0 +1,276 @@
+package core
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+	"unsafe"
+
+	"github.com/SunshineZzzz/purego-sdl3/sdl"
+)
+
+// 创建鼠标按钮事件
+func makeMouseButtonEvent(eventType sdl.EventType, button sdl.MouseButtonFlags) sdl.Event {
+	var event sdl.Event
+	*(*sdl.MouseButtonEvent)(unsafe.Pointer(&event)) = sdl.MouseButtonEvent{
+		CommonEvent: sdl.CommonEvent{Type: eventType},
+		Button:      uint8(button),
+		Down:        eventType == sdl.EventMouseButtonDown,
+	}
+	return event
+}
+
+func TestParseInputBinding(t *testing.T) {
+	tests := []struct {
+		name string
+		want InputBinding
+		// 解析后再转换回来的名字，为空时和name相同
+		canonical string
+	}{
+		{"Mouse Left", MouseBinding(sdl.ButtonLeft), ""},
+		{"Mouse x2", MouseBinding(sdl.ButtonX2), "Mouse X2"},
+		{"Gamepad South", GamepadButtonBinding(sdl.GamepadButtonSouth), ""},
+		{"Gamepad dpadup", GamepadButtonBinding(sdl.GamepadButtonDpadUp), "Gamepad DpadUp"},
+		{"Gamepad +LeftX", GamepadAxisBinding(sdl.GamepadAxisLeftX, 1), ""},
+		{"Gamepad -RightY", GamepadAxisBinding(sdl.GamepadAxisRightY, -1), ""},
+		// 扳机不写方向时是正方向
+		{"Gamepad RightTrigger", GamepadAxisBinding(sdl.GamepadAxisRightTrigger, 1), "Gamepad +RightTrigger"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			binding, err := ParseInputBinding(tt.name)
+			if err != nil {
+				t.Fatalf("parse error,%v", err)
+			}
+			if binding != tt.want {
+				t.Errorf("binding %+v, want %+v", binding, tt.want)
+			}
+			canonical := tt.canonical
+			if canonical == "" {
+				canonical = tt.name
+			}
+			if got := binding.String(); got != canonical {
+				t.Errorf("name %q, want %q", got, canonical)
+			}
+		})
+	}
+}
+
+func TestParseInputBindingErrors(t *testing.T) {
+	for _, name := range []string{"Mouse Nose", "Gamepad Select", "Gamepad +South", "Gamepad -Shoulder"} {
+		if _, err := ParseInputBinding(name); err == nil {
+			t.Errorf("%q: expected error", name)
+		}
+	}
+}
+
+func TestInputMapSaveLoad(t *testing.T) {
+	// 键盘绑定的名字来自SDL，这里只用鼠标和手柄绑定
+	saved := CreateInputMap()
+	for action := range actionCount {
+		saved.SetBindings(action, GamepadButtonBinding(sdl.GamepadButton(action)), GamepadAxisBinding(sdl.GamepadAxisLeftY, -1))
+	}
+	saved.SetBindings(ActionAttack, MouseBinding(sdl.ButtonRight))
+	saved.SetBindings(ActionPause)
+	dir := t.TempDir()
+	// 目录不存在时自动创建
+	if err := saved.Save(filepath.Join(dir, InputConfigPath)); err != nil {
+		t.Fatalf("save error,%v", err)
+	}
+
+	loaded := CreateInputMap()
+	if err := loaded.Load(os.DirFS(dir), InputConfigPath); err != nil {
+		t.Fatalf("load error,%v", err)
+	}
+	for action := range actionCount {
+		want, got := saved.GetBindings(action), loaded.GetBindings(action)
+		if len(want) == 0 && len(got) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: loaded %v, want %v", action, got, want)
+		}
+	}
+}
+
+func TestInputMapLoadPartial(t *testing.T) {
+	dir := t.TempDir()
+	config := `{"Attack": ["Mouse Middle", "Gamepad -LeftX"]}`
+	if err := os.WriteFile(filepath.Join(dir, "input.json"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := CreateInputMap()
+	defaults := m.GetBindings(ActionPause)
+	if err := m.Load(os.DirFS(dir), "input.json"); err != nil {
+		t.Fatalf("load error,%v", err)
+	}
+	want := []InputBinding{MouseBinding(sdl.ButtonMiddle), GamepadAxisBinding(sdl.GamepadAxisLeftX, -1)}
+	if got := m.GetBindings(ActionAttack); !reflect.DeepEqual(got, want) {
+		t.Errorf("attack %v, want %v", got, want)
+	}
+	// 配置中没有的动作保持原来的绑定
+	if got := m.GetBindings(ActionPause); !reflect.DeepEqual(got, defaults) {
+		t.Errorf("pause %v, want defaults %v", got, defaults)
+	}
+}
+
+func TestInputMapLoadErrors(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"bad.json":     `{"Attack": `,
+		"unknown.json": `{"Attack": ["Mouse Left", "Gamepad Select"]}`,
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{"bad.json", "unknown.json", "missing.json"} {
+		m := CreateInputMap()
+		if err := m.Load(os.DirFS(dir), name); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestInputMapConfigPersists(t *testing.T) {
+	// 素材编译进程序或者在素材包中，包里自带的配置不能覆盖玩家的修改
+	shipped := `{"Attack": ["Mouse Left"]}`
+	tests := []struct {
+		name   string
+		mounts []fstest.MapFS
+	}{
+		{"embed", []fstest.MapFS{{InputConfigPath: {Data: []byte(shipped)}}}},
+		{"pack", []fstest.MapFS{{}, {InputConfigPath: {Data: []byte(shipped)}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assetFS := CreateAssetFS(t.TempDir())
+			for _, mount := range tt.mounts {
+				assetFS.Mount("", mount)
+			}
+			// 没有保存过时读素材中的配置
+			m := CreateInputMap()
+			if err := m.LoadConfig(assetFS); err != nil {
+				t.Fatalf("load error,%v", err)
+			}
+			if got, want := m.GetBindings(ActionAttack), []InputBinding{MouseBinding(sdl.ButtonLeft)}; !reflect.DeepEqual(got, want) {
+				t.Errorf("shipped attack %v, want %v", got, want)
+			}
+
+			// 键盘绑定的名字来自SDL，这里只用鼠标和手柄绑定
+			for action := range actionCount {
+				m.SetBindings(action, GamepadButtonBinding(sdl.GamepadButton(action)))
+			}
+			m.SetBindings(ActionAttack, MouseBinding(sdl.ButtonRight))
+			if err := m.SaveConfig(assetFS); err != nil {
+				t.Fatalf("save error,%v", err)
+			}
+			loaded := CreateInputMap()
+			if err := loaded.LoadConfig(assetFS); err != nil {
+				t.Fatalf("load error,%v", err)
+			}
+			if got, want := loaded.GetBindings(ActionAttack), []InputBinding{MouseBinding(sdl.ButtonRight)}; !reflect.DeepEqual(got, want) {
+				t.Errorf("saved attack %v, want %v", got, want)
+			}
+		})
+	}
+
+	// 两边都没有配置
+	m := CreateInputMap()
+	if err := m.LoadConfig(CreateAssetFS(t.TempDir())); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("missing config error %v, want not exist", err)
+	}
+}
+
+func TestInputMapActionState(t *testing.T) {
+	m := CreateInputMap()
+	m.SetBindings(ActionMoveUp, KeyBinding(sdl.ScancodeW), KeyBinding(sdl.ScancodeUp))
+	m.SetBindings(ActionAttack, MouseBinding(sdl.ButtonLeft))
+	keys := make([]bool, sdl.ScancodeCount)
+
+	steps := []struct {
+		name         string
+		keys         []sdl.Scancode
+		mouse        sdl.MouseButtonFlags
+		pressed      bool
+		justPressed  bool
+		justReleased bool
+	}{
+		{"idle", nil, 0, false, false, false},
+		{"press", []sdl.Scancode{sdl.ScancodeW}, 0, true, true, false},
+		{"hold", []sdl.Scancode{sdl.ScancodeW}, 0, true, false, false},
+		// 换成另一个绑定，动作一直按着
+		{"switch key", []sdl.Scancode{sdl.ScancodeUp}, 0, true, false, false},
+		{"release", nil, 0, false, false, true},
+		{"other action", nil, sdl.ButtonLMask, false, false, false},
+	}
+	for _, step := range steps {
+		clear(keys)
+		for _, key := range step.keys {
+			keys[key] = true
+		}
+		m.Update(keys, step.mouse, nil)
+		if got := m.IsActionPressed(ActionMoveUp); got != step.pressed {
+			t.Errorf("%s: pressed = %v", step.name, got)
+		}
+		if got := m.WasActionJustPressed(ActionMoveUp); got != step.justPressed {
+			t.Errorf("%s: just pressed = %v", step.name, got)
+		}
+		if got := m.WasActionJustReleased(ActionMoveUp); got != step.justReleased {
+			t.Errorf("%s: just released = %v", step.name, got)
+		}
+		m.ClearLatched()
+	}
+	if !m.IsActionPressed(ActionAttack) {
+		t.Error("mouse binding not pressed")
+	}
+}
+
+func TestInputMapLatchedAndBlocked(t *testing.T) {
+	m := CreateInputMap()
+	m.SetBindings(ActionAttack, MouseBinding(sdl.ButtonLeft))
+	m.SetBindings(ActionPause, KeyBinding(sdl.ScancodeEscape))
+	keys := make([]bool, sdl.ScancodeCount)
+
+	// 两次tick之间按下又松开，轮询看不到，事件记录下来
+	event := makeMouseButtonEvent(sdl.EventMouseButtonDown, sdl.ButtonLeft)
+	m.HandleEvent(&event)
+	m.Update(keys, 0, nil)
+	if !m.WasActionJustPressed(ActionAttack) {
+		t.Error("quick click lost")
+	}
+	m.ClearLatched()
+	m.Update(keys, 0, nil)
+	if m.WasActionJustPressed(ActionAttack) {
+		t.Error("latched press not cleared")
+	}
+
+	// 按键重复不算按下
+	repeat := makeKeyEvent(sdl.EventKeyDown, sdl.ScancodeEscape)
+	(*sdl.KeyboardEvent)(unsafe.Pointer(&repeat)).Repeat = true
+	m.HandleEvent(&repeat)
+	if m.WasActionJustPressed(ActionPause) {
+		t.Error("key repeat latched")
+	}
+
+	// 屏蔽时不响应，解除后按着的键不会产生多余的按下
+	m.SetBlocked(true)
+	keys[sdl.ScancodeEscape] = true
+	press := makeKeyEvent(sdl.EventKeyDown, sdl.ScancodeEscape)
+	m.HandleEvent(&press)
+	m.Update(keys, 0, nil)
+	if m.IsActionPressed(ActionPause) || m.WasActionJustPressed(ActionPause) {
+		t.Error("blocked input reported")
+	}
+	m.ClearLatched()
+	m.SetBlocked(false)
+	m.Update(keys, 0, nil)
+	if !m.IsActionPressed(ActionPause) || m.WasActionJustPressed(ActionPause) {
+		t.Error("held key after unblock should be pressed but not just pressed")
+	}
+}
@@ -36,6 +36,14 @@ type IObject interface {
 	SetNeedRemove(bool)
 	// 安全加入孩子
 	SafeAddChild(child IObject)
+	// 获取渲染层
+	GetRenderLayer() RenderLayer
+	// 设置渲染层
+	SetRenderLayer(RenderLayer)
+	// 获取z序，同一层内越大越后渲染
+	GetZIndex() int
+	// 设置z序
+	SetZIndex(int)
 }
 
 // 基础对象
@@ -52,6 +60,10 @@ type Object struct {
 	IsActive bool
 	// 是否需要移除
 	NeedRemove bool
+	// 渲染层，只对场景的直接孩子生效
+	RenderLayer RenderLayer
+	// z序，同一层内越大越后渲染
+	ZIndex int
 }
 
 var _ IObject = (*Object)(nil)
@@ -63,6 +75,8 @@ func (o *Object) Init() {
 	o.ChildrenToAdd.Init()
 	o.IsActive = true
 	o.NeedRemove = false
+	o.RenderLayer = RenderLayerBackground
+	o.ZIndex = 0
 }
 
 // 处理事件
@@ -174,6 +188,26 @@ func (o *Object) SafeAddChild(child IObject) {
 	o.ChildrenToAdd.PushBack(child)
 }
 
+// 获取渲染层
+func (o *Object) GetRenderLayer() RenderLayer {
+	return o.RenderLayer
+}
+
+// 设置渲染层
+func (o *Object) SetRenderLayer(layer RenderLayer) {
+	o.RenderLayer = layer
+}
+
+// 获取z序
+func (o *Object) GetZIndex() int {
+	return o.ZIndex
+}
+
+// 设置z序
+func (o *Object) SetZIndex(zIndex int) {
+	o.ZIndex = zIndex
+}
+
 // 非接口实现
 
 // 获取游戏实例
@@ -1,5 +1,8 @@
 package core
 
+// 帧事件回调，name为事件名字，frame为触发事件的帧
+type FrameEventFunc func(name string, frame int)
+
 // 物体动画组件抽象
 type IObjectAnima interface {
 	// 继承基础依附对象接口
@@ -30,4 +33,8 @@ type IObjectAnima interface {
 	GetFinish() bool
 	// 设置是否播放完毕
 	SetFinish(bool)
+	// 添加帧事件，播放到该帧时触发
+	AddFrameEvent(frame int, name string)
+	// 监听帧事件
+	OnFrameEvent(name string, callback FrameEventFunc)
 }
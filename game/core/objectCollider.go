@@ -10,6 +10,14 @@ type ColliderType int
 const (
 	// 圆形碰撞器，size的X轴为直径，默认Y=X
 	ColliderTypeCircle ColliderType = iota
+	// 矩形碰撞器，和坐标轴对齐，不旋转
+	ColliderTypeRect
+	// 旋转矩形碰撞器，绕中心旋转Angle度
+	ColliderTypeOBB
+	// 胶囊碰撞器，沿size较长的轴，半径是较短边的一半，绕中心旋转Angle度
+	ColliderTypeCapsule
+	// 凸多边形碰撞器，顶点相对中心，绕中心旋转Angle度
+	ColliderTypePolygon
 )
 
 // 碰撞器组件抽象
@@ -22,6 +30,20 @@ type IObjectCollider interface {
 	SetColliderType(ColliderType)
 	// 是否发生碰撞
 	IsColliding(IObjectCollider) bool
+	// 获取碰撞形状(世界坐标系)
+	GetShape() ColliderShape
+	// 获取碰撞层
+	GetCollisionLayer() CollisionLayer
+	// 设置碰撞层
+	SetCollisionLayer(CollisionLayer)
+	// 获取碰撞掩码
+	GetCollisionMask() CollisionLayer
+	// 设置碰撞掩码
+	SetCollisionMask(CollisionLayer)
+	// 获取是否是触发器
+	GetTrigger() bool
+	// 设置是否是触发器
+	SetTrigger(bool)
 	// 获取父节点
 	GetParent() IObjectScreen
 	// 设置父亲节点
@@ -44,6 +66,16 @@ type ObjectCollider struct {
 	ObjectAffiliate
 	// 碰撞器类型
 	Type ColliderType
+	// 旋转角度，单位度，顺时针，旋转矩形、胶囊和多边形使用
+	Angle float32
+	// 凸多边形顶点，相对碰撞器中心，多边形使用
+	Points []mgl32.Vec2
+	// 碰撞层，自己属于哪些层
+	Layer CollisionLayer
+	// 碰撞掩码，和哪些层的碰撞器重叠时通知父节点
+	Mask CollisionLayer
+	// 是否是触发器，触发器只通知自己的父节点，不会通知对方
+	Trigger bool
 }
 
 var _ IObject = (*ObjectCollider)(nil)
@@ -64,3 +96,125 @@ func (o *ObjectCollider) SetColliderType(t ColliderType) {
 func (o *ObjectCollider) IsColliding(other IObjectCollider) bool {
 	panic("not implemented")
 }
+
+// 获取碰撞形状(世界坐标系)，形状在父节点位置+偏移、大小为Size的矩形内，和依附对象的锚点一致
+func (o *ObjectCollider) GetShape() ColliderShape {
+	half := o.Size.Mul(0.5)
+	center := half.Add(o.Offset)
+	if o.Parent != nil {
+		center = center.Add(o.Parent.GetPosition())
+	}
+	shape := ColliderShape{Type: o.Type}
+	switch o.Type {
+	case ColliderTypeCircle:
+		shape.A = center
+		shape.B = center
+		shape.Radius = half.X()
+	case ColliderTypeCapsule:
+		// 中轴线沿较长的轴，两端各留出半径
+		axis := mgl32.Vec2{half.X() - half.Y(), 0.0}
+		shape.Radius = half.Y()
+		if half.Y() > half.X() {
+			axis = mgl32.Vec2{0.0, half.Y() - half.X()}
+			shape.Radius = half.X()
+		}
+		axis = rotateVec2(axis, o.Angle)
+		shape.A = center.Sub(axis)
+		shape.B = center.Add(axis)
+	case ColliderTypeRect, ColliderTypeOBB:
+		angle := o.Angle
+		if o.Type == ColliderTypeRect {
+			angle = 0.0
+		}
+		corners := [4]mgl32.Vec2{
+			{-half.X(), -half.Y()},
+			{half.X(), -half.Y()},
+			{half.X(), half.Y()},
+			{-half.X(), half.Y()},
+		}
+		shape.Points = make([]mgl32.Vec2, 0, len(corners))
+		for _, corner := range corners {
+			shape.Points = append(shape.Points, center.Add(rotateVec2(corner, angle)))
+		}
+	case ColliderTypePolygon:
+		shape.Points = make([]mgl32.Vec2, 0, len(o.Points))
+		for _, point := range o.Points {
+			shape.Points = append(shape.Points, center.Add(rotateVec2(point, o.Angle)))
+		}
+	}
+	return shape
+}
+
+// 获取碰撞层
+func (o *ObjectCollider) GetCollisionLayer() CollisionLayer {
+	return o.Layer
+}
+
+// 设置碰撞层
+func (o *ObjectCollider) SetCollisionLayer(layer CollisionLayer) {
+	o.Layer = layer
+}
+
+// 获取碰撞掩码
+func (o *ObjectCollider) GetCollisionMask() CollisionLayer {
+	return o.Mask
+}
+
+// 设置碰撞掩码，为CollisionLayerNone时不检测碰撞
+func (o *ObjectCollider) SetCollisionMask(mask CollisionLayer) {
+	o.Mask = mask
+}
+
+// 获取是否是触发器
+func (o *ObjectCollider) GetTrigger() bool {
+	return o.Trigger
+}
+
+// 设置是否是触发器
+func (o *ObjectCollider) SetTrigger(trigger bool) {
+	o.Trigger = trigger
+}
+
+// 设置缩放比例，多边形顶点一起缩放
+func (o *ObjectCollider) SetScale(scale float32) {
+	o.ObjectAffiliate.SetScale(scale)
+	for i := range o.Points {
+		o.Points[i] = o.Points[i].Mul(scale)
+	}
+}
+
+// 非接口实现
+
+// 获取旋转角度
+func (o *ObjectCollider) GetAngle() float32 {
+	return o.Angle
+}
+
+// 设置旋转角度，单位度，顺时针
+func (o *ObjectCollider) SetAngle(angle float32) {
+	o.Angle = angle
+}
+
+// 获取多边形顶点，相对碰撞器中心
+func (o *ObjectCollider) GetPoints() []mgl32.Vec2 {
+	return o.Points
+}
+
+// 设置多边形顶点，顶点相对碰撞器中心，必须是凸多边形，大小设置为顶点的包围盒
+func (o *ObjectCollider) SetPoints(points []mgl32.Vec2) {
+	o.Points = append(o.Points[:0], points...)
+	if len(points) == 0 {
+		return
+	}
+	boundsMin, boundsMax := points[0], points[0]
+	for _, p := range points[1:] {
+		boundsMin = mgl32.Vec2{min(boundsMin.X(), p.X()), min(boundsMin.Y(), p.Y())}
+		boundsMax = mgl32.Vec2{max(boundsMax.X(), p.X()), max(boundsMax.Y(), p.Y())}
+	}
+	// 顶点包围盒的中心移到原点，保证形状在Size矩形内
+	center := boundsMin.Add(boundsMax).Mul(0.5)
+	for i := range o.Points {
+		o.Points[i] = o.Points[i].Sub(center)
+	}
+	o.SetSize(boundsMax.Sub(boundsMin))
+}
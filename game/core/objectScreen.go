@@ -36,6 +36,7 @@ func (o *ObjectScreen) SetRenderPosition(pos mgl32.Vec2) {
 func (o *ObjectScreen) Init() {
 	o.Object.Init()
 	o.ObjectType = ObjectTypeScreen
+	o.RenderLayer = RenderLayerHUD
 }
 
 // 获取渲染(屏幕)位置
@@ -21,6 +21,8 @@ type ObjectWorld struct {
 	ObjectScreen
 	// 世界位置
 	Position mgl32.Vec2
+	// 上一次tick的世界位置，用于渲染插值
+	PrevPosition mgl32.Vec2
 	// 碰撞体组件
 	Collider IObjectCollider
 }
@@ -31,20 +33,31 @@ var _ IObjectWorld = (*ObjectWorld)(nil)
 
 // 更新
 func (o *ObjectWorld) Update(dt float32) {
+	o.PrevPosition = o.Position
 	o.ObjectScreen.Update(dt)
 	o.RenderPosition = o.Game().GetCurrentScene().WorldToScreen(o.Position)
 }
 
+// 渲染，渲染位置在上一次tick和当前tick之间插值，渲染完恢复逻辑上的渲染位置
+func (o *ObjectWorld) Render() {
+	renderPosition := o.RenderPosition
+	o.RenderPosition = o.GetInterpolatedPosition().Sub(o.Game().GetCurrentScene().GetRenderCameraPosition())
+	o.ObjectScreen.Render()
+	o.RenderPosition = renderPosition
+}
+
 // 设置渲染(屏幕)位置
 func (o *ObjectWorld) SetRenderPosition(pos mgl32.Vec2) {
 	o.RenderPosition = pos
 	o.Position = o.Game().GetCurrentScene().ScreenToWorld(pos)
+	o.PrevPosition = o.Position
 }
 
 // 初始化
 func (o *ObjectWorld) Init() {
 	o.ObjectScreen.Init()
 	o.ObjectType = ObjectTypeWorld
+	o.RenderLayer = RenderLayerActors
 }
 
 // 非接口实现
@@ -54,12 +67,19 @@ func (o *ObjectWorld) GetPosition() mgl32.Vec2 {
 	return o.Position
 }
 
-// 设置世界位置
+// 设置世界位置，瞬移，不做渲染插值
 func (o *ObjectWorld) SetPosition(pos mgl32.Vec2) {
 	o.Position = pos
+	o.PrevPosition = pos
 	o.RenderPosition = o.Game().GetCurrentScene().WorldToScreen(pos)
 }
 
+// 获取插值后的世界位置
+func (o *ObjectWorld) GetInterpolatedPosition() mgl32.Vec2 {
+	alpha := o.Game().GetInterpolation()
+	return o.PrevPosition.Add(o.Position.Sub(o.PrevPosition).Mul(alpha))
+}
+
 // 设置碰撞体组件
 func (o *ObjectWorld) SetCollider(collider IObjectCollider) {
 	o.Collider = collider
@@ -0,0 +1,57 @@
+package core
+
+import (
+	"math/rand"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// 随机数流，不同用途使用独立的随机数流，互不影响
+type RandStream int
+
+const (
+	// 默认随机数流
+	RandStreamDefault RandStream = iota
+	// 敌人生成
+	RandStreamSpawn
+	// 视觉表现，背景星空、特效等
+	RandStreamVisual
+	// 掉落
+	RandStreamLoot
+	// 随机数流数量
+	randStreamCount
+)
+
+// 随机数生成器
+type Random struct {
+	// 底层随机数生成器
+	rand *rand.Rand
+}
+
+// 创建随机数生成器，种子由总种子和随机数流共同决定
+func CreateRandom(seed int64, stream RandStream) *Random {
+	return &Random{
+		rand: rand.New(rand.NewSource(seed ^ int64(stream)*0x5DEECE66D)),
+	}
+}
+
+// 随机min和max范围的浮点数
+func (r *Random) RandFloat32(min, max float32) float32 {
+	return min + r.rand.Float32()*(max-min)
+}
+
+// 随机[min,max)范围的整数
+func (r *Random) RandInt(min, max int) int {
+	if max <= min {
+		return min
+	}
+	return min + r.rand.Intn(max-min)
+}
+
+// 随机min和max范围的Vec2
+func (r *Random) RandVec2(min, max mgl32.Vec2) mgl32.Vec2 {
+	return mgl32.Vec2{
+		r.RandFloat32(min.X(), max.X()),
+		r.RandFloat32(min.Y(), max.Y()),
+	}
+}
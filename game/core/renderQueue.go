@@ -0,0 +1,314 @@
+package core
+
+import (
+	"sort"
+
+	"github.com/SunshineZzzz/purego-sdl3/sdl"
+)
+
+// 绘制命令类型
+type renderCommandType int
+
+const (
+	// 三角形，纹理为nil时是纯色
+	renderCommandGeometry renderCommandType = iota
+	// 点
+	renderCommandPoints
+	// 折线
+	renderCommandLines
+)
+
+// 绘制命令
+type renderCommand struct {
+	// 命令类型
+	kind renderCommandType
+	// 纹理，纯色和点线为nil
+	texture *sdl.Texture
+	// 混合模式
+	blendMode sdl.BlendMode
+	// 点和线的颜色
+	color sdl.FColor
+	// 排序组
+	group int
+	// 顶点或点在缓冲中的起始位置和数量
+	start, count int
+	// 索引在缓冲中的起始位置和数量，索引相对命令自己的顶点
+	indexStart, indexCount int
+}
+
+// 渲染队列，收集一帧的绘制命令，按纹理和混合模式排序后合批提交
+// 命令分组，组之间保持提交顺序，可排序的组内按状态排序，不可排序的组只合并相邻的相同状态命令
+type RenderQueue struct {
+	// SDL渲染器
+	renderer *sdl.Renderer
+	// 绘制命令
+	commands []renderCommand
+	// 顶点缓冲
+	vertices []sdl.Vertex
+	// 索引缓冲
+	indices []int32
+	// 点和线缓冲
+	points []sdl.FPoint
+	// 当前排序组
+	group int
+	// 每个组是否可以排序
+	groupSortable []bool
+	// 纹理第一次出现的顺序，排序用，保证结果稳定
+	textureOrder map[*sdl.Texture]int
+	// 最近加入的纹理，纯色四边形优先使用它的白色块，和前面的图片合批
+	lastTexture *sdl.Texture
+	// 合批时使用的顶点缓冲
+	batchVertices []sdl.Vertex
+	// 合批时使用的索引缓冲
+	batchIndices []int32
+	// 合批时使用的点缓冲
+	batchPoints []sdl.FPoint
+	// 当前帧的绘制调用次数
+	drawCalls int
+	// 上一帧的绘制调用次数
+	lastDrawCalls int
+}
+
+// 创建渲染队列
+func CreateRenderQueue(renderer *sdl.Renderer) *RenderQueue {
+	q := &RenderQueue{
+		renderer:     renderer,
+		textureOrder: make(map[*sdl.Texture]int),
+	}
+	q.reset()
+	return q
+}
+
+// 开始新的排序组，组之间保持提交顺序，sortable为true时组内按纹理和混合模式排序
+func (q *RenderQueue) BeginGroup(sortable bool) {
+	q.group = len(q.groupSortable)
+	q.groupSortable = append(q.groupSortable, sortable)
+}
+
+// 加入三角形，indices相对vertices，为nil时按顺序每三个顶点一个三角形
+func (q *RenderQueue) AddGeometry(texture *sdl.Texture, vertices []sdl.Vertex, indices []int32) {
+	if len(vertices) == 0 {
+		return
+	}
+	blendMode := sdl.BlendMode(sdl.BlendModeBlend)
+	if texture != nil {
+		sdl.GetTextureBlendMode(texture, &blendMode)
+		if _, ok := q.textureOrder[texture]; !ok {
+			q.textureOrder[texture] = len(q.textureOrder)
+		}
+		q.lastTexture = texture
+	}
+	command := renderCommand{
+		kind:       renderCommandGeometry,
+		texture:    texture,
+		blendMode:  blendMode,
+		group:      q.group,
+		start:      len(q.vertices),
+		count:      len(vertices),
+		indexStart: len(q.indices),
+	}
+	q.vertices = append(q.vertices, vertices...)
+	if indices == nil {
+		for i := range vertices {
+			q.indices = append(q.indices, int32(i))
+		}
+	} else {
+		q.indices = append(q.indices, indices...)
+	}
+	command.indexCount = len(q.indices) - command.indexStart
+	q.commands = append(q.commands, command)
+}
+
+// 加入四边形，corners按左上、右上、右下、左下排列，uv是纹理中的归一化区域
+func (q *RenderQueue) AddQuad(texture *sdl.Texture, corners [4]sdl.FPoint, uv sdl.FRect, color sdl.FColor) {
+	vertices := [4]sdl.Vertex{
+		{Position: corners[0], Color: color, TexCoord: sdl.FPoint{X: uv.X, Y: uv.Y}},
+		{Position: corners[1], Color: color, TexCoord: sdl.FPoint{X: uv.X + uv.W, Y: uv.Y}},
+		{Position: corners[2], Color: color, TexCoord: sdl.FPoint{X: uv.X + uv.W, Y: uv.Y + uv.H}},
+		{Position: corners[3], Color: color, TexCoord: sdl.FPoint{X: uv.X, Y: uv.Y + uv.H}},
+	}
+	q.AddGeometry(texture, vertices[:], quadIndices[:])
+}
+
+// 四边形的索引
+var quadIndices = [6]int32{0, 1, 2, 0, 2, 3}
+
+// 加入点，相邻的同色点合并成一次绘制
+func (q *RenderQueue) AddPoints(points []sdl.FPoint, color sdl.FColor) {
+	q.addPoints(renderCommandPoints, points, color)
+}
+
+// 加入折线，每条折线一次绘制
+func (q *RenderQueue) AddLines(points []sdl.FPoint, color sdl.FColor) {
+	if len(points) < 2 {
+		return
+	}
+	q.addPoints(renderCommandLines, points, color)
+}
+
+// 提交所有命令，直接使用SDL绘制之前、切换渲染目标之前和显示之前都要调用
+func (q *RenderQueue) Flush() {
+	if len(q.commands) == 0 {
+		q.reset()
+		return
+	}
+	q.forEachBatch(q.submit)
+	sdl.SetRenderDrawColorFloat(q.renderer, 0.0, 0.0, 0.0, 1.0)
+	q.reset()
+}
+
+// 结束一帧，记录绘制调用次数
+func (q *RenderQueue) EndFrame() {
+	q.Flush()
+	q.groupSortable[0] = false
+	q.lastDrawCalls = q.drawCalls
+	q.drawCalls = 0
+}
+
+// 获取最近加入的纹理，提交后仍然保留，只用来查找白色块，不会再绘制
+func (q *RenderQueue) GetLastTexture() *sdl.Texture {
+	return q.lastTexture
+}
+
+// 获取上一帧的绘制调用次数
+func (q *RenderQueue) GetDrawCalls() int {
+	return q.lastDrawCalls
+}
+
+// 加入点或折线
+func (q *RenderQueue) addPoints(kind renderCommandType, points []sdl.FPoint, color sdl.FColor) {
+	if len(points) == 0 {
+		return
+	}
+	q.commands = append(q.commands, renderCommand{
+		kind:      kind,
+		blendMode: sdl.BlendModeBlend,
+		color:     color,
+		group:     q.group,
+		start:     len(q.points),
+		count:     len(points),
+	})
+	q.points = append(q.points, points...)
+}
+
+// 命令排序后，把可以合并的相邻命令分成一批，按顺序回调
+func (q *RenderQueue) forEachBatch(fn func(commands []renderCommand)) {
+	sort.SliceStable(q.commands, func(i, j int) bool {
+		return q.less(&q.commands[i], &q.commands[j])
+	})
+	for i := 0; i < len(q.commands); {
+		j := i + 1
+		for j < len(q.commands) && q.canMerge(&q.commands[i], &q.commands[j]) {
+			j++
+		}
+		fn(q.commands[i:j])
+		i = j
+	}
+}
+
+// 排序比较，不同组按组的顺序，可排序的组内按状态
+func (q *RenderQueue) less(a, b *renderCommand) bool {
+	if a.group != b.group {
+		return a.group < b.group
+	}
+	if !q.groupSortable[a.group] {
+		return false
+	}
+	if a.kind != b.kind {
+		return a.kind < b.kind
+	}
+	if a.texture != b.texture {
+		return q.getTextureOrder(a.texture) < q.getTextureOrder(b.texture)
+	}
+	if a.blendMode != b.blendMode {
+		return a.blendMode < b.blendMode
+	}
+	return lessColor(a.color, b.color)
+}
+
+// 是否可以合并成一次绘制，折线之间不能合并
+func (q *RenderQueue) canMerge(a, b *renderCommand) bool {
+	if a.kind != b.kind || a.kind == renderCommandLines {
+		return false
+	}
+	if a.texture != b.texture || a.blendMode != b.blendMode {
+		return false
+	}
+	return a.kind != renderCommandPoints || a.color == b.color
+}
+
+// 提交一批状态相同的命令
+func (q *RenderQueue) submit(commands []renderCommand) {
+	first := &commands[0]
+	switch first.kind {
+	case renderCommandGeometry:
+		q.batchVertices = q.batchVertices[:0]
+		q.batchIndices = q.batchIndices[:0]
+		for i := range commands {
+			command := &commands[i]
+			base := int32(len(q.batchVertices))
+			q.batchVertices = append(q.batchVertices, q.vertices[command.start:command.start+command.count]...)
+			for _, index := range q.indices[command.indexStart : command.indexStart+command.indexCount] {
+				q.batchIndices = append(q.batchIndices, base+index)
+			}
+		}
+		if first.texture == nil {
+			sdl.SetRenderDrawBlendMode(q.renderer, first.blendMode)
+		} else {
+			// 颜色在顶点里，纹理自己的颜色调制会再乘一次，绘制前恢复
+			sdl.SetTextureColorModFloat(first.texture, 1.0, 1.0, 1.0)
+			sdl.SetTextureAlphaModFloat(first.texture, 1.0)
+		}
+		sdl.RenderGeometry(q.renderer, first.texture, q.batchVertices, q.batchIndices)
+	case renderCommandPoints:
+		// 相邻的点命令在缓冲中不一定连续，排序后可能交错
+		q.batchPoints = q.batchPoints[:0]
+		for i := range commands {
+			q.batchPoints = append(q.batchPoints, q.points[commands[i].start:commands[i].start+commands[i].count]...)
+		}
+		sdl.SetRenderDrawBlendMode(q.renderer, first.blendMode)
+		sdl.SetRenderDrawColorFloat(q.renderer, first.color.R, first.color.G, first.color.B, first.color.A)
+		sdl.RenderPoints(q.renderer, q.batchPoints)
+	case renderCommandLines:
+		sdl.SetRenderDrawBlendMode(q.renderer, first.blendMode)
+		sdl.SetRenderDrawColorFloat(q.renderer, first.color.R, first.color.G, first.color.B, first.color.A)
+		sdl.RenderLines(q.renderer, q.points[first.start:first.start+first.count])
+	}
+	q.drawCalls++
+}
+
+// 获取纹理排序顺序，纯色排在最前面
+func (q *RenderQueue) getTextureOrder(texture *sdl.Texture) int {
+	if texture == nil {
+		return -1
+	}
+	return q.textureOrder[texture]
+}
+
+// 清空命令和缓冲，保留内存
+func (q *RenderQueue) reset() {
+	clear(q.commands)
+	q.commands = q.commands[:0]
+	q.vertices = q.vertices[:0]
+	q.indices = q.indices[:0]
+	q.points = q.points[:0]
+	// 帧中间提交后当前组的排序方式继续生效
+	sortable := len(q.groupSortable) > 0 && q.groupSortable[q.group]
+	q.group = 0
+	q.groupSortable = append(q.groupSortable[:0], sortable)
+	clear(q.textureOrder)
+}
+
+// 颜色比较，排序用
+func lessColor(a, b sdl.FColor) bool {
+	if a.R != b.R {
+		return a.R < b.R
+	}
+	if a.G != b.G {
+		return a.G < b.G
+	}
+	if a.B != b.B {
+		return a.B < b.B
+	}
+	return a.A < b.A
+}
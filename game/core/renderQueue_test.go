@@ -0,0 +1,209 @@
+package core
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/SunshineZzzz/purego-sdl3/sdl"
+)
+
+// 测试用的纹理，只用来区分状态
+var (
+	testPage   = &sdl.Texture{W: 64, H: 64}
+	testFont   = &sdl.Texture{W: 64, H: 64}
+	testWhite  = sdl.FRect{X: 0.1, Y: 0.1, W: 0.1, H: 0.1}
+	testColor  = sdl.FColor{R: 1.0, G: 1.0, B: 1.0, A: 1.0}
+	testRed    = sdl.FColor{R: 1.0, A: 1.0}
+	testCorner = [4]sdl.FPoint{{X: 0.0, Y: 0.0}, {X: 1.0, Y: 0.0}, {X: 1.0, Y: 1.0}, {X: 0.0, Y: 1.0}}
+)
+
+// 纹理的名字，检查结果用
+func textureName(texture *sdl.Texture) string {
+	switch texture {
+	case nil:
+		return "solid"
+	case testPage:
+		return "page"
+	case testFont:
+		return "font"
+	}
+	return "unknown"
+}
+
+// 加入图片
+func queueSprite(texture *sdl.Texture) func(q *RenderQueue) {
+	return func(q *RenderQueue) {
+		q.AddQuad(texture, testCorner, sdl.FRect{W: 1.0, H: 1.0}, testColor)
+	}
+}
+
+// 加入纯色四边形，和Game.queueSolidQuad一样，最近的纹理是图集页时采样白色块
+func queueSolid(q *RenderQueue) {
+	texture := q.GetLastTexture()
+	uv := testWhite
+	if texture != testPage {
+		texture, uv = nil, sdl.FRect{}
+	}
+	q.AddQuad(texture, testCorner, uv, testColor)
+}
+
+// 加入不经过白色块的纯色四边形
+func queueUntextured(q *RenderQueue) {
+	q.AddQuad(nil, testCorner, sdl.FRect{}, testColor)
+}
+
+// 加入折线
+func queueLines(q *RenderQueue) {
+	q.AddLines(testCorner[:], testColor)
+}
+
+// 加入点
+func queuePoints(color sdl.FColor) func(q *RenderQueue) {
+	return func(q *RenderQueue) {
+		q.AddPoints(testCorner[:], color)
+	}
+}
+
+// 开始排序组
+func beginGroup(sortable bool) func(q *RenderQueue) {
+	return func(q *RenderQueue) {
+		q.BeginGroup(sortable)
+	}
+}
+
+// 取出每一批的状态和命令数
+func describeBatches(q *RenderQueue) []string {
+	batches := make([]string, 0)
+	q.forEachBatch(func(commands []renderCommand) {
+		name := textureName(commands[0].texture)
+		switch commands[0].kind {
+		case renderCommandPoints:
+			name = "points"
+		case renderCommandLines:
+			name = "lines"
+		}
+		batches = append(batches, name+" x"+strconv.Itoa(len(commands)))
+	})
+	return batches
+}
+
+func TestRenderQueueBatches(t *testing.T) {
+	tests := []struct {
+		name string
+		ops  []func(q *RenderQueue)
+		want []string
+	}{
+		{
+			// 精灵、血条边框四条边和填充合并成一批
+			"health bar",
+			[]func(q *RenderQueue){
+				queueSprite(testPage), queueSolid, queueSolid, queueSolid, queueSolid, queueSolid,
+				queueSprite(testPage), queueSolid, queueSolid, queueSolid, queueSolid, queueSolid,
+			},
+			[]string{"page x12"},
+		},
+		{
+			// 前面是文字纹理时没有白色块，退回不带纹理的四边形
+			"solid after font",
+			[]func(q *RenderQueue){queueSprite(testFont), queueSolid, queueSolid},
+			[]string{"font x1", "solid x2"},
+		},
+		{
+			// 不排序的组保持提交顺序，只合并相邻的相同状态
+			"unsorted keeps order",
+			[]func(q *RenderQueue){queueSprite(testPage), queueUntextured, queueSprite(testPage)},
+			[]string{"page x1", "solid x1", "page x1"},
+		},
+		{
+			// 可排序的组按纹理排序，纯色排在最前面
+			"sorted by texture",
+			[]func(q *RenderQueue){
+				beginGroup(true),
+				queueSprite(testPage), queueUntextured, queueSprite(testFont), queueSprite(testPage),
+			},
+			[]string{"solid x1", "page x2", "font x1"},
+		},
+		{
+			// 组之间保持顺序，纹理按第一次出现的顺序排
+			"groups keep order",
+			[]func(q *RenderQueue){
+				beginGroup(true), queueSprite(testFont), queueSprite(testPage),
+				beginGroup(true), queueUntextured, queueSprite(testPage),
+			},
+			[]string{"font x1", "page x1", "solid x1", "page x1"},
+		},
+		{
+			// 折线之间不合并，同色的点合并
+			"lines and points",
+			[]func(q *RenderQueue){
+				queueLines, queueLines,
+				queuePoints(testColor), queuePoints(testColor), queuePoints(testRed),
+			},
+			[]string{"lines x1", "lines x1", "points x2", "points x1"},
+		},
+		{
+			"sorted points by color",
+			[]func(q *RenderQueue){
+				beginGroup(true), queuePoints(testColor), queuePoints(testRed), queuePoints(testColor),
+			},
+			[]string{"points x1", "points x2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := CreateRenderQueue(nil)
+			for _, op := range tt.ops {
+				op(q)
+			}
+			if got := describeBatches(q); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("batches %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderQueueMergedGeometry(t *testing.T) {
+	q := CreateRenderQueue(nil)
+	queueSprite(testPage)(q)
+	queueSolid(q)
+	var vertices []sdl.Vertex
+	var indices []int32
+	q.forEachBatch(func(commands []renderCommand) {
+		for _, command := range commands {
+			base := int32(len(vertices))
+			vertices = append(vertices, q.vertices[command.start:command.start+command.count]...)
+			for _, index := range q.indices[command.indexStart : command.indexStart+command.indexCount] {
+				indices = append(indices, base+index)
+			}
+		}
+	})
+	if want := []int32{0, 1, 2, 0, 2, 3, 4, 5, 6, 4, 6, 7}; !reflect.DeepEqual(indices, want) {
+		t.Errorf("indices %v, want %v", indices, want)
+	}
+	// 纯色四边形采样白色块
+	if uv := vertices[4].TexCoord; uv.X != testWhite.X || uv.Y != testWhite.Y {
+		t.Errorf("solid quad uv %v, want white texel %v", uv, testWhite)
+	}
+}
+
+func TestRenderQueueDrawCalls(t *testing.T) {
+	q := CreateRenderQueue(nil)
+	queueSprite(testPage)(q)
+	queueSolid(q)
+	queueLines(q)
+	// 帧中间提交，最近的纹理仍然保留
+	q.Flush()
+	if q.GetLastTexture() != testPage {
+		t.Errorf("last texture lost after flush")
+	}
+	queueSolid(q)
+	q.EndFrame()
+	if got := q.GetDrawCalls(); got != 3 {
+		t.Errorf("draw calls = %d, want 3", got)
+	}
+	q.EndFrame()
+	if got := q.GetDrawCalls(); got != 0 {
+		t.Errorf("empty frame draw calls = %d, want 0", got)
+	}
+}
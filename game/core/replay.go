@@ -0,0 +1,414 @@
+package core
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/SunshineZzzz/purego-sdl3/sdl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	// 录像文件魔数
+	replayMagic = "GERP"
+	// 录像文件版本
+	replayVersion = 1
+	// 回放最小速度
+	ReplayMinSpeed = 0.25
+	// 回放最大速度
+	ReplayMaxSpeed = 8.0
+)
+
+// 一次tick的输入
+type ReplayTick struct {
+	// 逻辑步长
+	Dt float32
+	// 鼠标位置(游戏逻辑坐标)
+	MousePosition mgl32.Vec2
+	// 鼠标按钮状态
+	MouseButtons sdl.MouseButtonFlags
+	// 按下的按键
+	Keys []sdl.Scancode
+}
+
+// 一帧的输入，先处理事件，再依次执行tick
+type ReplayFrame struct {
+	// 本帧分发给场景的事件
+	Events []sdl.Event
+	// 本帧执行的tick
+	Ticks []ReplayTick
+}
+
+// 录像
+type Replay struct {
+	// 随机种子
+	Seed int64
+	// 逻辑更新频率
+	TickRate uint64
+	// 所有帧
+	Frames []ReplayFrame
+}
+
+// 保存录像到文件，gzip压缩
+func (r *Replay) Save(filePath string) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	zw := gzip.NewWriter(file)
+	bw := bufio.NewWriter(zw)
+	if err = r.write(bw); err != nil {
+		return err
+	}
+	if err = bw.Flush(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// 写入录像
+func (r *Replay) write(w io.Writer) error {
+	le := binary.LittleEndian
+	if _, err := w.Write([]byte(replayMagic)); err != nil {
+		return err
+	}
+	header := []any{uint16(replayVersion), r.Seed, uint32(r.TickRate), uint32(len(r.Frames))}
+	for _, v := range header {
+		if err := binary.Write(w, le, v); err != nil {
+			return err
+		}
+	}
+	for i := range r.Frames {
+		frame := &r.Frames[i]
+		if err := binary.Write(w, le, uint16(len(frame.Events))); err != nil {
+			return err
+		}
+		for j := range frame.Events {
+			if _, err := w.Write(frame.Events[j][:]); err != nil {
+				return err
+			}
+		}
+		if err := binary.Write(w, le, uint16(len(frame.Ticks))); err != nil {
+			return err
+		}
+		for _, t := range frame.Ticks {
+			values := []any{t.Dt, t.MousePosition[0], t.MousePosition[1], uint32(t.MouseButtons), uint16(len(t.Keys))}
+			for _, v := range values {
+				if err := binary.Write(w, le, v); err != nil {
+					return err
+				}
+			}
+			for _, k := range t.Keys {
+				if err := binary.Write(w, le, uint16(k)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// 从文件加载录像
+func LoadReplay(filePath string) (*Replay, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("load replay error,%v", err)
+	}
+	defer zr.Close()
+
+	r := &Replay{}
+	if err = r.read(bufio.NewReader(zr)); err != nil {
+		return nil, fmt.Errorf("load replay error,%v", err)
+	}
+	return r, nil
+}
+
+// 读取录像
+func (r *Replay) read(rd io.Reader) error {
+	le := binary.LittleEndian
+	magic := make([]byte, len(replayMagic))
+	if _, err := io.ReadFull(rd, magic); err != nil {
+		return err
+	}
+	if string(magic) != replayMagic {
+		return errors.New("invalid replay magic")
+	}
+	var version uint16
+	var tickRate, frameCount uint32
+	if err := binary.Read(rd, le, &version); err != nil {
+		return err
+	}
+	if version != replayVersion {
+		return fmt.Errorf("unsupported replay version %d", version)
+	}
+	if err := binary.Read(rd, le, &r.Seed); err != nil {
+		return err
+	}
+	if err := binary.Read(rd, le, &tickRate); err != nil {
+		return err
+	}
+	if err := binary.Read(rd, le, &frameCount); err != nil {
+		return err
+	}
+	r.TickRate = uint64(tickRate)
+	r.Frames = make([]ReplayFrame, frameCount)
+	for i := range r.Frames {
+		frame := &r.Frames[i]
+		var eventCount, tickCount uint16
+		if err := binary.Read(rd, le, &eventCount); err != nil {
+			return err
+		}
+		frame.Events = make([]sdl.Event, 0, eventCount)
+		for range eventCount {
+			var event sdl.Event
+			if _, err := io.ReadFull(rd, event[:]); err != nil {
+				return err
+			}
+			frame.Events = append(frame.Events, event)
+		}
+		if err := binary.Read(rd, le, &tickCount); err != nil {
+			return err
+		}
+		frame.Ticks = make([]ReplayTick, tickCount)
+		for j := range frame.Ticks {
+			t := &frame.Ticks[j]
+			var buttons uint32
+			var keyCount uint16
+			values := []any{&t.Dt, &t.MousePosition[0], &t.MousePosition[1], &buttons, &keyCount}
+			for _, v := range values {
+				if err := binary.Read(rd, le, v); err != nil {
+					return err
+				}
+			}
+			t.MouseButtons = sdl.MouseButtonFlags(buttons)
+			t.Keys = make([]sdl.Scancode, keyCount)
+			for k := range t.Keys {
+				var key uint16
+				if err := binary.Read(rd, le, &key); err != nil {
+					return err
+				}
+				t.Keys[k] = sdl.Scancode(key)
+			}
+		}
+	}
+	return nil
+}
+
+// 是否是录像记录的事件，只有游戏读取的键盘、鼠标按钮和手柄输入
+// 设备插拔事件带设备ID，回放时会打开别的设备，文本输入、拖放文件等事件带指针，都不记录
+func isReplayEvent(event *sdl.Event) bool {
+	switch event.Type() {
+	case sdl.EventKeyDown, sdl.EventKeyUp,
+		sdl.EventMouseButtonDown, sdl.EventMouseButtonUp,
+		sdl.EventGamepadAxisMotion, sdl.EventGamepadButtonDown, sdl.EventGamepadButtonUp:
+		return true
+	}
+	return false
+}
+
+// 录像录制器
+type ReplayRecorder struct {
+	// 录像数据
+	replay *Replay
+	// 当前帧
+	frame ReplayFrame
+	// 保存路径
+	filePath string
+}
+
+// 创建录像录制器
+func CreateReplayRecorder(filePath string, seed int64, tickRate uint64) *ReplayRecorder {
+	return &ReplayRecorder{
+		replay:   &Replay{Seed: seed, TickRate: tickRate},
+		filePath: filePath,
+	}
+}
+
+// 记录事件，只记录游戏读取的输入事件
+func (r *ReplayRecorder) RecordEvent(event *sdl.Event) {
+	if !isReplayEvent(event) {
+		return
+	}
+	r.frame.Events = append(r.frame.Events, *event)
+}
+
+// 记录一次tick的输入
+func (r *ReplayRecorder) RecordTick(dt float32, mousePosition mgl32.Vec2, mouseButtons sdl.MouseButtonFlags, keyboardState []bool) {
+	keys := make([]sdl.Scancode, 0)
+	for scancode, down := range keyboardState {
+		if down {
+			keys = append(keys, sdl.Scancode(scancode))
+		}
+	}
+	r.frame.Ticks = append(r.frame.Ticks, ReplayTick{
+		Dt:            dt,
+		MousePosition: mousePosition,
+		MouseButtons:  mouseButtons,
+		Keys:          keys,
+	})
+}
+
+// 结束当前帧，没有任何输入的空帧不记录
+func (r *ReplayRecorder) EndFrame() {
+	if len(r.frame.Events) == 0 && len(r.frame.Ticks) == 0 {
+		return
+	}
+	r.replay.Frames = append(r.replay.Frames, r.frame)
+	r.frame = ReplayFrame{}
+}
+
+// 保存录像
+func (r *ReplayRecorder) Save() error {
+	r.EndFrame()
+	return r.replay.Save(r.filePath)
+}
+
+// 录像回放器
+type ReplayPlayer struct {
+	// 录像数据
+	replay *Replay
+	// 下一帧索引
+	frameIndex int
+	// 回放速度
+	speed float32
+	// 是否暂停
+	isPause bool
+	// 暂停时是否单步执行一帧
+	needStep bool
+	// 尚未消耗的回放时间，单位秒
+	clock float32
+}
+
+// 创建录像回放器
+func CreateReplayPlayer(replay *Replay) *ReplayPlayer {
+	return &ReplayPlayer{
+		replay: replay,
+		speed:  1.0,
+	}
+}
+
+// 获取录像数据
+func (p *ReplayPlayer) GetReplay() *Replay {
+	return p.replay
+}
+
+// 取出下一帧，播放完毕返回nil
+func (p *ReplayPlayer) NextFrame() *ReplayFrame {
+	if p.GetFinish() {
+		return nil
+	}
+	frame := &p.replay.Frames[p.frameIndex]
+	p.frameIndex++
+	return frame
+}
+
+// 查看下一帧，不移动索引
+func (p *ReplayPlayer) PeekFrame() *ReplayFrame {
+	if p.GetFinish() {
+		return nil
+	}
+	return &p.replay.Frames[p.frameIndex]
+}
+
+// 获取是否播放完毕
+func (p *ReplayPlayer) GetFinish() bool {
+	return p.frameIndex >= len(p.replay.Frames)
+}
+
+// 获取当前帧索引
+func (p *ReplayPlayer) GetFrameIndex() int {
+	return p.frameIndex
+}
+
+// 获取总帧数
+func (p *ReplayPlayer) GetTotalFrame() int {
+	return len(p.replay.Frames)
+}
+
+// 获取回放速度
+func (p *ReplayPlayer) GetSpeed() float32 {
+	return p.speed
+}
+
+// 设置回放速度
+func (p *ReplayPlayer) SetSpeed(speed float32) {
+	p.speed = mgl32.Clamp(speed, ReplayMinSpeed, ReplayMaxSpeed)
+}
+
+// 获取是否暂停
+func (p *ReplayPlayer) GetPause() bool {
+	return p.isPause
+}
+
+// 设置是否暂停
+func (p *ReplayPlayer) SetPause(pause bool) {
+	p.isPause = pause
+	p.clock = 0.0
+}
+
+// 暂停时单步执行一帧
+func (p *ReplayPlayer) Step() {
+	p.needStep = true
+}
+
+// 推进回放时间，卡顿后最多追赶maxCatchUpSteps次tick，多出的时间直接丢弃
+func (p *ReplayPlayer) advanceClock(elapsed, dt float32, maxCatchUpSteps int) {
+	p.clock += elapsed * p.speed
+	maxClock := dt * float32(maxCatchUpSteps) * p.speed
+	// 录制时卡顿的帧包含很多tick，上限至少要够播放下一帧，否则回放会一直卡住
+	if frame := p.PeekFrame(); frame != nil {
+		maxClock = max(maxClock, dt*float32(len(frame.Ticks)))
+	}
+	p.clock = min(p.clock, maxClock)
+}
+
+// 取出回放时间足够播放的下一帧，时间不够或者播放完毕返回nil
+func (p *ReplayPlayer) nextDueFrame(dt float32) *ReplayFrame {
+	frame := p.PeekFrame()
+	if frame == nil {
+		return nil
+	}
+	cost := dt * float32(len(frame.Ticks))
+	if cost > p.clock {
+		return nil
+	}
+	p.clock -= cost
+	return p.NextFrame()
+}
+
+// 取出单步请求
+func (p *ReplayPlayer) consumeStep() bool {
+	needStep := p.needStep
+	p.needStep = false
+	return needStep
+}
+
+// 处理回放控制按键，空格暂停/恢复，右方向键单步，上下方向键加速/减速
+func (p *ReplayPlayer) HandleEvent(event *sdl.Event) {
+	if event.Type() != sdl.EventKeyDown {
+		return
+	}
+	switch event.Key().Scancode {
+	case sdl.ScancodeSpace:
+		p.SetPause(!p.isPause)
+	case sdl.ScancodeRight:
+		p.Step()
+	case sdl.ScancodeUp:
+		p.SetSpeed(p.speed * 2.0)
+	case sdl.ScancodeDown:
+		p.SetSpeed(p.speed * 0.5)
+	}
+}
@@ -0,0 +1,138 @@
+package core
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/SunshineZzzz/purego-sdl3/sdl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// 创建键盘事件
+func makeKeyEvent(eventType sdl.EventType, scancode sdl.Scancode) sdl.Event {
+	var event sdl.Event
+	*(*sdl.KeyboardEvent)(unsafe.Pointer(&event)) = sdl.KeyboardEvent{
+		CommonEvent: sdl.CommonEvent{Type: eventType},
+		Scancode:    scancode,
+		Down:        eventType == sdl.EventKeyDown,
+	}
+	return event
+}
+
+// 创建只有类型的事件
+func makeEvent(eventType sdl.EventType) sdl.Event {
+	var event sdl.Event
+	*(*sdl.CommonEvent)(unsafe.Pointer(&event)) = sdl.CommonEvent{Type: eventType}
+	return event
+}
+
+func TestReplaySaveLoad(t *testing.T) {
+	replay := &Replay{
+		Seed:     -1234567890123,
+		TickRate: 60,
+		Frames: []ReplayFrame{
+			{
+				Events: []sdl.Event{
+					makeKeyEvent(sdl.EventKeyDown, sdl.ScancodeW),
+					makeKeyEvent(sdl.EventKeyUp, sdl.ScancodeW),
+				},
+				Ticks: []ReplayTick{
+					{Dt: 1.0 / 60.0, MousePosition: mgl32.Vec2{12.5, -3.25}, MouseButtons: sdl.ButtonLMask, Keys: []sdl.Scancode{sdl.ScancodeW, sdl.ScancodeSpace}},
+					{Dt: 1.0 / 60.0, MousePosition: mgl32.Vec2{13.0, -3.0}, Keys: []sdl.Scancode{}},
+				},
+			},
+			{
+				Events: []sdl.Event{},
+				Ticks:  []ReplayTick{{Dt: 1.0 / 60.0, Keys: []sdl.Scancode{}}},
+			},
+			{
+				Events: []sdl.Event{makeEvent(sdl.EventGamepadButtonDown)},
+				Ticks:  []ReplayTick{},
+			},
+		},
+	}
+	filePath := filepath.Join(t.TempDir(), "test.replay")
+	if err := replay.Save(filePath); err != nil {
+		t.Fatalf("save error,%v", err)
+	}
+	loaded, err := LoadReplay(filePath)
+	if err != nil {
+		t.Fatalf("load error,%v", err)
+	}
+	if !reflect.DeepEqual(replay, loaded) {
+		t.Errorf("round trip mismatch\nwant %+v\ngot  %+v", replay, loaded)
+	}
+}
+
+func TestLoadReplayInvalid(t *testing.T) {
+	if _, err := LoadReplay(filepath.Join(t.TempDir(), "missing.replay")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestReplayRecorderFiltersEvents(t *testing.T) {
+	recorder := CreateReplayRecorder("", 1, 60)
+	for _, event := range []sdl.Event{
+		makeEvent(sdl.EventTextInput),
+		makeEvent(sdl.EventDropFile),
+		makeEvent(sdl.EventGamepadAdded),
+		makeEvent(sdl.EventGamepadRemoved),
+		makeEvent(sdl.EventMouseMotion),
+		makeKeyEvent(sdl.EventKeyDown, sdl.ScancodeA),
+		makeEvent(sdl.EventMouseButtonUp),
+		makeEvent(sdl.EventGamepadAxisMotion),
+	} {
+		recorder.RecordEvent(&event)
+	}
+	recorder.EndFrame()
+	var types []sdl.EventType
+	for _, event := range recorder.replay.Frames[0].Events {
+		types = append(types, event.Type())
+	}
+	want := []sdl.EventType{sdl.EventKeyDown, sdl.EventMouseButtonUp, sdl.EventGamepadAxisMotion}
+	if !reflect.DeepEqual(types, want) {
+		t.Errorf("recorded %v, want %v", types, want)
+	}
+}
+
+func TestReplayPlayerPlaysLongFrameAtLowSpeed(t *testing.T) {
+	const dt = 1.0 / 60.0
+	// 录制时卡顿，一帧执行了5次tick
+	replay := &Replay{TickRate: 60, Frames: []ReplayFrame{
+		{Ticks: make([]ReplayTick, 1)},
+		{Ticks: make([]ReplayTick, 5)},
+		{Ticks: make([]ReplayTick, 1)},
+	}}
+	for _, speed := range []float32{ReplayMinSpeed, 0.5, 1.0, ReplayMaxSpeed} {
+		player := CreateReplayPlayer(replay)
+		player.SetSpeed(speed)
+		for step := 0; step < 1000 && !player.GetFinish(); step++ {
+			player.advanceClock(dt, dt, 5)
+			for player.nextDueFrame(dt) != nil {
+			}
+		}
+		if !player.GetFinish() {
+			t.Errorf("speed %v: playback stuck at frame %d", speed, player.GetFrameIndex())
+		}
+	}
+}
+
+func TestReplayPlayerCatchUpLimit(t *testing.T) {
+	const dt = 1.0 / 60.0
+	frames := make([]ReplayFrame, 20)
+	for i := range frames {
+		frames[i].Ticks = make([]ReplayTick, 1)
+	}
+	player := CreateReplayPlayer(&Replay{TickRate: 60, Frames: frames})
+	// 卡顿1秒，最多追赶5次tick
+	player.advanceClock(1.0, dt, 5)
+	played := 0
+	for player.nextDueFrame(dt) != nil {
+		played++
+	}
+	if played != 5 {
+		t.Errorf("played %d frames after a hitch, want 5", played)
+	}
+}
@@ -2,6 +2,7 @@ package core
 
 import (
 	"container/list"
+	"sort"
 
 	"github.com/SunshineZzzz/purego-sdl3/sdl"
 	"github.com/go-gl/mathgl/mgl32"
@@ -18,8 +19,16 @@ type IScene interface {
 	GetCameraPosition() mgl32.Vec2
 	// 设置摄像机位置
 	SetCameraPosition(mgl32.Vec2)
+	// 获取相机
+	GetCamera() *Camera
+	// 获取渲染用摄像机位置，在上一次tick和当前tick之间插值，包括震屏偏移
+	GetRenderCameraPosition() mgl32.Vec2
 	// 获取世界大小
 	GetWorldSize() mgl32.Vec2
+	// 获取空间哈希，世界对象的碰撞器注册在里面
+	GetSpatialHash() *SpatialHash
+	// 获取碰撞系统
+	GetCollisionSystem() *CollisionSystem
 	// 获取世界对象孩子
 	GetChildWorld() *list.List
 	// 获取屏幕对象孩子
@@ -28,6 +37,18 @@ type IScene interface {
 	LoadData(string)
 	// 保存数据
 	SaveData(string)
+	// 进入场景栈，在Init之后调用
+	OnEnter()
+	// 离开场景栈，在Clean之前调用
+	OnExit()
+	// 被压入的场景覆盖
+	OnPause()
+	// 覆盖的场景弹出，重新成为栈顶
+	OnResume()
+	// 作为栈顶场景时是否渲染下层场景
+	GetRenderBelow() bool
+	// 作为栈顶场景时是否更新下层场景
+	GetUpdateBelow() bool
 }
 
 // 基础场景
@@ -36,14 +57,46 @@ type Scene struct {
 	Object
 	// 世界大小
 	WorldSize mgl32.Vec2
-	// 摄像机位置
+	// 相机，负责跟随、边界和震屏
+	Camera *Camera
+	// 摄像机位置，相机更新后的位置，不包括震屏偏移，坐标换算和逻辑使用
 	CameraPositon mgl32.Vec2
+	// 上一次tick的摄像机位置，用于渲染插值
+	PrevCameraPosition mgl32.Vec2
 	// 世界对象孩子
 	ChildrenWorld list.List
 	// 屏幕对象孩子
 	ChildrenScreen list.List
 	// 是否暂停
 	IsPause bool
+	// 作为栈顶场景时是否渲染下层场景，覆盖场景使用
+	RenderBelow bool
+	// 作为栈顶场景时是否更新下层场景，覆盖场景使用
+	UpdateBelow bool
+	// 每个渲染层是否按y排序，y越大越靠前，越后渲染
+	LayerYSort [RenderLayerCount]bool
+	// 每个渲染层是否按纹理排序合批，层内绘制顺序不重要时开启，按y排序的层不生效
+	LayerBatchSort [RenderLayerCount]bool
+	// 空间哈希，世界对象的碰撞器每次tick自动注册，用于碰撞查询
+	SpatialHash *SpatialHash
+	// 碰撞系统，检测空间哈希里的碰撞器并派发碰撞回调
+	CollisionSystem *CollisionSystem
+	// 渲染队列，每帧重新收集排序，复用内存
+	renderQueue []renderItem
+}
+
+// 渲染队列中的一项
+type renderItem struct {
+	// 对象
+	object IObject
+	// 是否世界对象，需要在世界视图中渲染
+	world bool
+	// 渲染层
+	layer RenderLayer
+	// z序
+	zIndex int
+	// y排序用的世界位置y
+	y float32
 }
 
 var _ IObject = (*Scene)(nil)
@@ -51,14 +104,14 @@ var _ IScene = (*Scene)(nil)
 
 // 世界坐标转换为屏幕坐标
 func (s *Scene) WorldToScreen(worldPosition mgl32.Vec2) mgl32.Vec2 {
-	// 世界坐标-摄像机位置=屏幕坐标
-	return worldPosition.Sub(s.CameraPositon)
+	// 世界坐标-摄像机位置=相机空间坐标，再绕屏幕中心缩放旋转得到屏幕坐标
+	return s.Camera.GetView().Apply(worldPosition.Sub(s.CameraPositon))
 }
 
 // 屏幕坐标转换为世界坐标
 func (s *Scene) ScreenToWorld(screenPosition mgl32.Vec2) mgl32.Vec2 {
-	// 屏幕坐标+摄像机位置=世界坐标
-	return screenPosition.Add(s.CameraPositon)
+	// 屏幕坐标逆变换到相机空间，+摄像机位置=世界坐标
+	return s.Camera.GetView().Inverse(screenPosition).Add(s.CameraPositon)
 }
 
 // 获取摄像机位置(世界坐标系)
@@ -66,10 +119,22 @@ func (s *Scene) GetCameraPosition() mgl32.Vec2 {
 	return s.CameraPositon
 }
 
-// 设置摄像机位置(世界坐标系)
+// 设置摄像机位置(世界坐标系)，瞬移，限制在相机边界内
 func (s *Scene) SetCameraPosition(pos mgl32.Vec2) {
-	s.CameraPositon[0] = mgl32.Clamp(pos.X(), -30.0, s.WorldSize.X()-s.Game().GetScreenSize().X()+30.0)
-	s.CameraPositon[1] = mgl32.Clamp(pos.Y(), -30.0, s.WorldSize.Y()-s.Game().GetScreenSize().Y()+30.0)
+	s.Camera.SetPosition(pos)
+	s.CameraPositon = s.Camera.GetPosition()
+}
+
+// 获取相机
+func (s *Scene) GetCamera() *Camera {
+	return s.Camera
+}
+
+// 获取渲染用摄像机位置(世界坐标系)，在上一次tick和当前tick之间插值
+// 震屏只在这里加上，只影响画面，瞄准和坐标换算不跟着抖动
+func (s *Scene) GetRenderCameraPosition() mgl32.Vec2 {
+	alpha := s.Game().GetInterpolation()
+	return s.PrevCameraPosition.Add(s.CameraPositon.Sub(s.PrevCameraPosition).Mul(alpha)).Add(s.Camera.GetShakeOffset())
 }
 
 // 获取世界大小
@@ -77,6 +142,16 @@ func (s *Scene) GetWorldSize() mgl32.Vec2 {
 	return s.WorldSize
 }
 
+// 获取空间哈希
+func (s *Scene) GetSpatialHash() *SpatialHash {
+	return s.SpatialHash
+}
+
+// 获取碰撞系统
+func (s *Scene) GetCollisionSystem() *CollisionSystem {
+	return s.CollisionSystem
+}
+
 // 初始化
 func (s *Scene) Init() {
 	s.Object.Init()
@@ -85,6 +160,15 @@ func (s *Scene) Init() {
 	// 我这里重写了AddChild所以需要设置Self
 	s.Object.Self = s
 	s.IsPause = false
+	s.Camera = CreateCamera(s)
+	s.SpatialHash = CreateSpatialHash(SpatialHashCellSize)
+	s.CollisionSystem = CreateCollisionSystem(s.SpatialHash)
+	// 角色默认按y排序，下面的角色挡住上面的
+	s.LayerYSort[RenderLayerActors] = true
+	// 背景和地面之间互相遮挡不重要，按纹理合批
+	// 特效保持提交顺序，粒子要画在法术上面，纯色粒子采样图集白色块，不排序也能合批
+	s.LayerBatchSort[RenderLayerBackground] = true
+	s.LayerBatchSort[RenderLayerGround] = true
 }
 
 // 处理事件
@@ -107,6 +191,7 @@ func (s *Scene) HandleEvent(event *sdl.Event) {
 
 // 更新
 func (s *Scene) Update(dt float32) {
+	s.PrevCameraPosition = s.CameraPositon
 	if !s.IsPause {
 		s.Object.Update(dt)
 		for e := s.ChildrenWorld.Front(); e != nil; {
@@ -120,6 +205,13 @@ func (s *Scene) Update(dt float32) {
 			}
 			e = next
 		}
+		// 世界对象移动完再刷新碰撞器位置，移除这次没有注册的碰撞器
+		s.SpatialHash.Refresh()
+		// 位置都更新完再检测碰撞，派发回调
+		s.CollisionSystem.Update()
+		// 世界对象移动完再更新相机
+		s.Camera.Update(dt)
+		s.CameraPositon = s.Camera.GetPosition()
 	}
 
 	for e := s.ChildrenScreen.Front(); e != nil; {
@@ -135,19 +227,49 @@ func (s *Scene) Update(dt float32) {
 	}
 }
 
-// 渲染
+// 渲染，所有孩子按渲染层、z序、y排序后渲染，和加入顺序无关，都相同时按加入顺序
+// 世界对象在世界视图中渲染，应用相机的缩放和旋转
 func (s *Scene) Render() {
-	s.Object.Render()
-	for e := s.ChildrenWorld.Front(); e != nil; e = e.Next() {
-		if e.Value.(IObject).GetActive() {
-			e.Value.(IObject).Render()
+	s.collectRender(&s.Children, false)
+	s.collectRender(&s.ChildrenWorld, true)
+	s.collectRender(&s.ChildrenScreen, false)
+	sort.SliceStable(s.renderQueue, func(i, j int) bool {
+		a, b := &s.renderQueue[i], &s.renderQueue[j]
+		if a.layer != b.layer {
+			return a.layer < b.layer
 		}
-	}
-	for e := s.ChildrenScreen.Front(); e != nil; e = e.Next() {
-		if e.Value.(IObject).GetActive() {
-			e.Value.(IObject).Render()
+		if a.zIndex != b.zIndex {
+			return a.zIndex < b.zIndex
+		}
+		if s.GetLayerYSort(a.layer) {
+			return a.y < b.y
 		}
+		return false
+	})
+	worldView := false
+	for i, item := range s.renderQueue {
+		// 每个层的每个z序是一个渲染排序组，组之间保持顺序
+		if i == 0 || item.layer != s.renderQueue[i-1].layer || item.zIndex != s.renderQueue[i-1].zIndex {
+			s.Game().BeginRenderGroup(s.GetLayerBatchSort(item.layer) && !s.GetLayerYSort(item.layer))
+		}
+		if item.world != worldView {
+			worldView = item.world
+			if worldView {
+				s.Game().BeginWorldView(s.Camera.GetView())
+			} else {
+				s.Game().EndWorldView()
+			}
+		}
+		item.object.Render()
+	}
+	if worldView {
+		s.Game().EndWorldView()
 	}
+	// 之后的绘制保持顺序
+	s.Game().BeginRenderGroup(false)
+	// 不持有对象引用，移除的对象可以被回收
+	clear(s.renderQueue)
+	s.renderQueue = s.renderQueue[:0]
 }
 
 // 清理
@@ -161,6 +283,8 @@ func (s *Scene) Clean() {
 	}
 	s.ChildrenWorld.Init()
 	s.ChildrenScreen.Init()
+	s.SpatialHash.Clear()
+	s.CollisionSystem.Clear()
 }
 
 // 增加孩子
@@ -195,6 +319,58 @@ func (s *Scene) RemoveChild(child IObject) {
 	}
 }
 
+// 获取渲染层是否按y排序
+func (s *Scene) GetLayerYSort(layer RenderLayer) bool {
+	if layer < 0 || layer >= RenderLayerCount {
+		return false
+	}
+	return s.LayerYSort[layer]
+}
+
+// 设置渲染层是否按y排序，只对世界对象生效，按世界位置的y排序
+func (s *Scene) SetLayerYSort(layer RenderLayer, ySort bool) {
+	if layer < 0 || layer >= RenderLayerCount {
+		return
+	}
+	s.LayerYSort[layer] = ySort
+}
+
+// 获取渲染层是否按纹理排序合批
+func (s *Scene) GetLayerBatchSort(layer RenderLayer) bool {
+	if layer < 0 || layer >= RenderLayerCount {
+		return false
+	}
+	return s.LayerBatchSort[layer]
+}
+
+// 设置渲染层是否按纹理排序合批，开启后层内的绘制顺序可能改变
+func (s *Scene) SetLayerBatchSort(layer RenderLayer, batchSort bool) {
+	if layer < 0 || layer >= RenderLayerCount {
+		return
+	}
+	s.LayerBatchSort[layer] = batchSort
+}
+
+// 收集需要渲染的孩子到渲染队列
+func (s *Scene) collectRender(children *list.List, world bool) {
+	for e := children.Front(); e != nil; e = e.Next() {
+		object := e.Value.(IObject)
+		if !object.GetActive() {
+			continue
+		}
+		item := renderItem{
+			object: object,
+			world:  world,
+			layer:  object.GetRenderLayer(),
+			zIndex: object.GetZIndex(),
+		}
+		if worldObject, ok := object.(IObjectWorld); ok {
+			item.y = worldObject.GetPosition().Y()
+		}
+		s.renderQueue = append(s.renderQueue, item)
+	}
+}
+
 // 获取世界对象孩子
 func (s *Scene) GetChildWorld() *list.List {
 	return &s.ChildrenWorld
@@ -219,6 +395,42 @@ func (s *Scene) Resume() {
 	s.Game().ResumeAllEffects()
 }
 
+// 进入场景栈
+func (s *Scene) OnEnter() {
+}
+
+// 离开场景栈
+func (s *Scene) OnExit() {
+}
+
+// 被压入的场景覆盖
+func (s *Scene) OnPause() {
+}
+
+// 重新成为栈顶
+func (s *Scene) OnResume() {
+}
+
+// 获取是否渲染下层场景
+func (s *Scene) GetRenderBelow() bool {
+	return s.RenderBelow
+}
+
+// 设置是否渲染下层场景
+func (s *Scene) SetRenderBelow(renderBelow bool) {
+	s.RenderBelow = renderBelow
+}
+
+// 获取是否更新下层场景
+func (s *Scene) GetUpdateBelow() bool {
+	return s.UpdateBelow
+}
+
+// 设置是否更新下层场景
+func (s *Scene) SetUpdateBelow(updateBelow bool) {
+	s.UpdateBelow = updateBelow
+}
+
 // 加载数据
 func (s *Scene) LoadData(string) {
 	panic("not implemented")
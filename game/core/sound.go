@@ -1,8 +1,10 @@
 package core
 
 import (
+	"encoding/binary"
 	"fmt"
-	"os"
+	"io/fs"
+	"math"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -39,25 +41,75 @@ type ISound interface {
 	Close()
 	// 获取声音类型
 	GetSoundType() SoundType
+	// 设置音量，范围[0,1]
+	SetVolume(volume float32)
+	// 获取解码后的PCM数据占用内存，单位字节
+	GetMemorySize() int
+	// 换成另一个声音的数据，热重载时使用，另一个声音之后不能再使用
+	reload(other ISound) error
 }
 
 // 创建声音
-func NewSound(soundFilePath string, soundType SoundType) (ISound, error) {
+func NewSound(fsys fs.FS, soundFilePath string, soundType SoundType) (ISound, error) {
 	extWithDot := filepath.Ext(soundFilePath)
 	ext := strings.ToLower(extWithDot[1:])
 
 	switch ext {
 	case "ogg":
-		return newOggSound(soundFilePath, soundType)
+		return newOggSound(fsys, soundFilePath, soundType)
 	case "wav":
-		return newWavSound(soundFilePath, soundType)
+		return newWavSound(fsys, soundFilePath, soundType)
 	case "mp3":
-		return newMp3Sound(soundFilePath, soundType)
+		return newMp3Sound(fsys, soundFilePath, soundType)
 	default:
 		return nil, fmt.Errorf("unsupported audio file format: %s", extWithDot)
 	}
 }
 
+// 限制音量范围
+func clampVolume(volume float32) float32 {
+	return min(max(volume, 0.0), 1.0)
+}
+
+// 按音量缩放PCM数据，音量为1时直接返回原数据，绑定库没有导出SDL_SetAudioStreamGain，只能软件缩放
+func applyVolume(data []byte, format sdl.AudioFormat, volume float32, buf *[]byte) []byte {
+	if volume >= 1.0 || len(data) == 0 {
+		return data
+	}
+	if cap(*buf) < len(data) {
+		*buf = make([]byte, len(data))
+	}
+	out := (*buf)[:len(data)]
+	copy(out, data)
+	le := binary.LittleEndian
+	switch format {
+	case sdl.AudioF32:
+		for i := 0; i+4 <= len(out); i += 4 {
+			f := math.Float32frombits(le.Uint32(out[i:]))
+			le.PutUint32(out[i:], math.Float32bits(f*volume))
+		}
+	case sdl.AudioS32:
+		for i := 0; i+4 <= len(out); i += 4 {
+			le.PutUint32(out[i:], uint32(int32(float32(int32(le.Uint32(out[i:])))*volume)))
+		}
+	case sdl.AudioS16:
+		for i := 0; i+2 <= len(out); i += 2 {
+			le.PutUint16(out[i:], uint16(int16(float32(int16(le.Uint16(out[i:])))*volume)))
+		}
+	case sdl.AudioS8:
+		for i := range out {
+			out[i] = uint8(int8(float32(int8(out[i])) * volume))
+		}
+	case sdl.AudioU8:
+		for i := range out {
+			out[i] = uint8(128 + int(float32(int(out[i])-128)*volume))
+		}
+	default:
+		return data
+	}
+	return out
+}
+
 // 全局声音句柄管理
 var soundHandles = struct {
 	sync.RWMutex
@@ -105,6 +157,26 @@ func getSound(id uint32) ISound {
 	return soundHandles.handles[id]
 }
 
+// 把id指向另一个声音，热重载时接管新声音的音频流
+func replaceSound(id uint32, sound ISound) {
+	soundHandles.Lock()
+	defer soundHandles.Unlock()
+
+	soundHandles.handles[id] = sound
+}
+
+// 热重载后切换音频流，新音频流的回调指向原来的声音，旧音频流销毁，销毁时不能持有声音的锁
+func switchSoundStream(sound ISound, id, oldID uint32, stream, oldStream *sdl.AudioStream, isPlaying bool) {
+	replaceSound(id, sound)
+	unregisterSound(oldID)
+	if oldStream != nil {
+		sdl.DestroyAudioStream(oldStream)
+	}
+	if isPlaying && stream != nil {
+		sdl.ResumeAudioStreamDevice(stream)
+	}
+}
+
 // 注销声音
 func unregisterSound(id uint32) {
 	soundHandles.Lock()
@@ -129,6 +201,10 @@ type oggSound struct {
 	isPlaying bool
 	// 是否循环播放
 	loop bool
+	// 音量
+	volume float32
+	// 音量缩放缓冲区，避免回调中反复分配
+	volumeBuf []byte
 	// id
 	id uint32
 	// 音频规格
@@ -138,8 +214,8 @@ type oggSound struct {
 
 var _ ISound = (*oggSound)(nil)
 
-func newOggSound(soundFilePath string, soundType SoundType) (*oggSound, error) {
-	file, err := os.Open(soundFilePath)
+func newOggSound(fsys fs.FS, soundFilePath string, soundType SoundType) (*oggSound, error) {
+	file, err := fsys.Open(soundFilePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open sound file, %v, %v", soundFilePath, err)
 	}
@@ -153,6 +229,10 @@ func newOggSound(soundFilePath string, soundType SoundType) (*oggSound, error) {
 	pcmData := make([]float32, 1024*1024)
 	totalSamples := 0
 	for {
+		// 缓冲区满了就扩容，否则长音频会被截断
+		if totalSamples == len(pcmData) {
+			pcmData = append(pcmData, make([]float32, len(pcmData))...)
+		}
 		n, err := oggReader.Read(pcmData[totalSamples:])
 		if err != nil && err.Error() != "EOF" {
 			return nil, fmt.Errorf("failed to read oggvorbis data, %v, %v", soundFilePath, err)
@@ -176,6 +256,7 @@ func newOggSound(soundFilePath string, soundType SoundType) (*oggSound, error) {
 		dataPos:    0,
 		isPlaying:  false,
 		loop:       false,
+		volume:     1.0,
 		sampleRate: int32(oggReader.SampleRate()),
 		channels:   int32(oggReader.Channels()),
 	}
@@ -199,10 +280,10 @@ func newOggSound(soundFilePath string, soundType SoundType) (*oggSound, error) {
 // 音频回调函数
 func oggAudioCallback(userdata unsafe.Pointer, stream *sdl.AudioStream, additionalAmount, totalAmount int32) {
 	id := uint32(uintptr(userdata))
-	ogg := getSound(id).(*oggSound)
+	ogg, ok := getSound(id).(*oggSound)
 
-	// 安全检查
-	if ogg == nil {
+	// 安全检查，声音关闭后回调可能还会被调用一次
+	if !ok || ogg == nil {
 		return
 	}
 
@@ -231,7 +312,7 @@ func oggAudioCallback(userdata unsafe.Pointer, stream *sdl.AudioStream, addition
 	neededBytes := int(additionalAmount)
 	dataToSend := min(neededBytes, remaining)
 	if dataToSend > 0 {
-		data := ogg.audioData[ogg.dataPos : ogg.dataPos+dataToSend]
+		data := applyVolume(ogg.audioData[ogg.dataPos:ogg.dataPos+dataToSend], sdl.AudioF32, ogg.volume, &ogg.volumeBuf)
 		sdl.PutAudioStreamData(stream, (*uint8)(unsafe.Pointer(&data[0])), int32(dataToSend))
 		ogg.dataPos += dataToSend
 	}
@@ -310,17 +391,60 @@ func (o *oggSound) SetLoop(loop bool) {
 	o.loop = loop
 }
 
-// 关闭播放器，释放资源
-func (o *oggSound) Close() {
+// 设置音量
+func (o *oggSound) SetVolume(volume float32) {
+	o.Lock()
+	defer o.Unlock()
+
+	o.volume = clampVolume(volume)
+}
+
+// 获取PCM数据占用内存
+func (o *oggSound) GetMemorySize() int {
 	o.Lock()
 	defer o.Unlock()
 
-	if o.stream != nil {
-		o.Stop()
-		sdl.DestroyAudioStream(o.stream)
-		o.stream = nil
+	return len(o.audioData)
+}
+
+// 换成新声音的数据和音频流，音频规格可能变化，音频流也一起接管，从头开始播放
+func (o *oggSound) reload(other ISound) error {
+	n, ok := other.(*oggSound)
+	if !ok {
+		return fmt.Errorf("sound format changed")
 	}
+	n.Lock()
+	stream, id := n.stream, n.id
+	audioData, sampleRate, channels := n.audioData, n.sampleRate, n.channels
+	n.stream, n.id, n.audioData = nil, 0, nil
+	n.Unlock()
+
+	o.Lock()
+	oldStream, oldID := o.stream, o.id
+	o.stream, o.id = stream, id
+	o.audioData, o.sampleRate, o.channels = audioData, sampleRate, channels
+	o.dataPos = 0
+	isPlaying := o.isPlaying
+	o.Unlock()
+
+	switchSoundStream(o, id, oldID, stream, oldStream, isPlaying)
+	return nil
+}
+
+// 关闭播放器，释放资源
+func (o *oggSound) Close() {
+	o.Lock()
+	stream := o.stream
+	o.stream = nil
+	o.isPlaying = false
+	o.audioData = nil
+	o.Unlock()
+
+	// 音频回调会加锁，销毁音频流时不能持有锁，Stop也会加锁，不能在这里调用
 	unregisterSound(o.id)
+	if stream != nil {
+		sdl.DestroyAudioStream(stream)
+	}
 }
 
 // 获取声音类型
@@ -349,27 +473,32 @@ type wavSound struct {
 	isPlaying bool
 	// 是否循环播放
 	loop bool
+	// 音量
+	volume float32
+	// 音量缩放缓冲区，避免回调中反复分配
+	volumeBuf []byte
 	// 音频规格
 	spec *sdl.AudioSpec
 	// id
 	id uint32
 }
 
-func newWavSound(soundFilePath string, soundType SoundType) (*wavSound, error) {
-	// 打开文件IO流
-	ioStream := sdl.IOFromFile(soundFilePath, "rb")
-	if ioStream == nil {
-		return nil, fmt.Errorf("failed to open WAV file: %s", sdl.GetError())
+func newWavSound(fsys fs.FS, soundFilePath string, soundType SoundType) (*wavSound, error) {
+	data, err := fs.ReadFile(fsys, soundFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sound file, %v, %v", soundFilePath, err)
 	}
-	// 自动释放了
-	// defer sdl.CloseIO(ioStream)
-
 	// 使用SDL直接加载WAV文件
 	var audioBuf *uint8
 	var audioLen uint32
 	spec := &sdl.AudioSpec{}
-	// 加载WAV数据
-	success := sdl.LoadWAVIO(ioStream, true, spec, &audioBuf, &audioLen)
+	// 加载WAV数据，SDL加载时会拷贝PCM数据，IO流自动释放了
+	success := loadFromMemory(data, func(ioStream *sdl.IOStream) bool {
+		if ioStream == nil {
+			return false
+		}
+		return sdl.LoadWAVIO(ioStream, true, spec, &audioBuf, &audioLen)
+	})
 	if !success {
 		return nil, fmt.Errorf("failed to load WAV data: %s", sdl.GetError())
 	}
@@ -382,6 +511,7 @@ func newWavSound(soundFilePath string, soundType SoundType) (*wavSound, error) {
 		dataPos:   0,
 		isPlaying: false,
 		loop:      false,
+		volume:    1.0,
 	}
 
 	// 注册WAV播放器
@@ -407,10 +537,10 @@ func newWavSound(soundFilePath string, soundType SoundType) (*wavSound, error) {
 // wav音频回调函数
 func wavAudioCallback(userdata unsafe.Pointer, stream *sdl.AudioStream, additionalAmount, totalAmount int32) {
 	id := uint32(uintptr(userdata))
-	wav := getSound(id).(*wavSound)
+	wav, ok := getSound(id).(*wavSound)
 
-	// 安全检查
-	if wav == nil {
+	// 安全检查，声音关闭后回调可能还会被调用一次
+	if !ok || wav == nil {
 		return
 	}
 
@@ -443,7 +573,8 @@ func wavAudioCallback(userdata unsafe.Pointer, stream *sdl.AudioStream, addition
 	if dataToSend > 0 {
 		data := (*uint8)(unsafe.Pointer(uintptr(unsafe.Pointer(wav.audioBuf)) + uintptr(wav.dataPos)))
 		// wav.audioBuf+wav.dataPos
-		sdl.PutAudioStreamData(stream, data, int32(dataToSend))
+		scaled := applyVolume(unsafe.Slice(data, dataToSend), wav.spec.Format, wav.volume, &wav.volumeBuf)
+		sdl.PutAudioStreamData(stream, &scaled[0], int32(dataToSend))
 		wav.dataPos += dataToSend
 	}
 
@@ -521,22 +652,68 @@ func (w *wavSound) SetLoop(loop bool) {
 	w.loop = loop
 }
 
-// 关闭播放器，释放资源
-func (w *wavSound) Close() {
+// 设置音量
+func (w *wavSound) SetVolume(volume float32) {
+	w.Lock()
+	defer w.Unlock()
+
+	w.volume = clampVolume(volume)
+}
+
+// 获取PCM数据占用内存
+func (w *wavSound) GetMemorySize() int {
 	w.Lock()
 	defer w.Unlock()
 
-	if w.stream != nil {
-		w.Stop()
-		sdl.DestroyAudioStream(w.stream)
-		w.stream = nil
+	return w.audioLen
+}
+
+// 换成新声音的数据和音频流，音频规格可能变化，音频流也一起接管，从头开始播放
+func (w *wavSound) reload(other ISound) error {
+	n, ok := other.(*wavSound)
+	if !ok {
+		return fmt.Errorf("sound format changed")
 	}
-	if w.audioLen > 0 {
-		sdl.Free(unsafe.Pointer(w.audioBuf))
-		w.audioBuf = nil
-		w.audioLen = 0
+	n.Lock()
+	stream, id := n.stream, n.id
+	audioBuf, audioLen, spec := n.audioBuf, n.audioLen, n.spec
+	n.stream, n.id, n.audioBuf, n.audioLen = nil, 0, nil, 0
+	n.Unlock()
+
+	w.Lock()
+	oldStream, oldID, oldBuf := w.stream, w.id, w.audioBuf
+	w.stream, w.id = stream, id
+	w.audioBuf, w.audioLen, w.spec = audioBuf, audioLen, spec
+	w.dataPos = 0
+	isPlaying := w.isPlaying
+	w.Unlock()
+
+	switchSoundStream(w, id, oldID, stream, oldStream, isPlaying)
+	if oldBuf != nil {
+		sdl.Free(unsafe.Pointer(oldBuf))
 	}
+	return nil
+}
+
+// 关闭播放器，释放资源
+func (w *wavSound) Close() {
+	w.Lock()
+	stream := w.stream
+	audioBuf := w.audioBuf
+	w.stream = nil
+	w.isPlaying = false
+	w.audioBuf = nil
+	w.audioLen = 0
+	w.Unlock()
+
+	// 音频回调会加锁，销毁音频流时不能持有锁，Stop也会加锁，不能在这里调用
 	unregisterSound(w.id)
+	if stream != nil {
+		sdl.DestroyAudioStream(stream)
+	}
+	if audioBuf != nil {
+		sdl.Free(unsafe.Pointer(audioBuf))
+	}
 }
 
 // 获取声音类型
@@ -563,6 +740,10 @@ type mp3Sound struct {
 	isPlaying bool
 	// 是否循环播放
 	loop bool
+	// 音量
+	volume float32
+	// 音量缩放缓冲区，避免回调中反复分配
+	volumeBuf []byte
 	// id
 	id uint32
 	// 音频规格
@@ -572,8 +753,8 @@ type mp3Sound struct {
 
 var _ ISound = (*mp3Sound)(nil)
 
-func newMp3Sound(soundFilePath string, soundType SoundType) (*mp3Sound, error) {
-	file, err := os.Open(soundFilePath)
+func newMp3Sound(fsys fs.FS, soundFilePath string, soundType SoundType) (*mp3Sound, error) {
+	file, err := fsys.Open(soundFilePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open sound file, %v, %v", soundFilePath, err)
 	}
@@ -587,6 +768,10 @@ func newMp3Sound(soundFilePath string, soundType SoundType) (*mp3Sound, error) {
 	pcmData := make([]byte, 1024*1024)
 	totalSamples := 0
 	for {
+		// 缓冲区满了就扩容，否则长音频会被截断
+		if totalSamples == len(pcmData) {
+			pcmData = append(pcmData, make([]byte, len(pcmData))...)
+		}
 		n, err := d.Read(pcmData[totalSamples:])
 		if err != nil && err.Error() != "EOF" {
 			return nil, fmt.Errorf("failed to read mp3 data, %v, %v", soundFilePath, err)
@@ -606,10 +791,11 @@ func newMp3Sound(soundFilePath string, soundType SoundType) (*mp3Sound, error) {
 	callback := sdl.NewAudioStreamCallback(mp3AudioCallback)
 	mp3 := &mp3Sound{
 		soundType:  soundType,
-		audioData:  pcmData,
+		audioData:  pcmData[:totalSamples:totalSamples],
 		dataPos:    0,
 		isPlaying:  false,
 		loop:       false,
+		volume:     1.0,
 		sampleRate: int32(d.SampleRate()),
 		channels:   2,
 	}
@@ -633,10 +819,10 @@ func newMp3Sound(soundFilePath string, soundType SoundType) (*mp3Sound, error) {
 // 音频回调函数
 func mp3AudioCallback(userdata unsafe.Pointer, stream *sdl.AudioStream, additionalAmount, totalAmount int32) {
 	id := uint32(uintptr(userdata))
-	mp3 := getSound(id).(*mp3Sound)
+	mp3, ok := getSound(id).(*mp3Sound)
 
-	// 安全检查
-	if mp3 == nil {
+	// 安全检查，声音关闭后回调可能还会被调用一次
+	if !ok || mp3 == nil {
 		return
 	}
 
@@ -665,7 +851,7 @@ func mp3AudioCallback(userdata unsafe.Pointer, stream *sdl.AudioStream, addition
 	neededBytes := int(additionalAmount)
 	dataToSend := min(neededBytes, remaining)
 	if dataToSend > 0 {
-		data := mp3.audioData[mp3.dataPos : mp3.dataPos+dataToSend]
+		data := applyVolume(mp3.audioData[mp3.dataPos:mp3.dataPos+dataToSend], sdl.AudioS16, mp3.volume, &mp3.volumeBuf)
 		sdl.PutAudioStreamData(stream, (*uint8)(unsafe.Pointer(&data[0])), int32(dataToSend))
 		mp3.dataPos += dataToSend
 	}
@@ -747,17 +933,60 @@ func (o *mp3Sound) SetLoop(loop bool) {
 	o.loop = loop
 }
 
-// 关闭播放器，释放资源
-func (o *mp3Sound) Close() {
+// 设置音量
+func (o *mp3Sound) SetVolume(volume float32) {
+	o.Lock()
+	defer o.Unlock()
+
+	o.volume = clampVolume(volume)
+}
+
+// 获取PCM数据占用内存
+func (o *mp3Sound) GetMemorySize() int {
 	o.Lock()
 	defer o.Unlock()
 
-	if o.stream != nil {
-		o.Stop()
-		sdl.DestroyAudioStream(o.stream)
-		o.stream = nil
+	return len(o.audioData)
+}
+
+// 换成新声音的数据和音频流，音频规格可能变化，音频流也一起接管，从头开始播放
+func (o *mp3Sound) reload(other ISound) error {
+	n, ok := other.(*mp3Sound)
+	if !ok {
+		return fmt.Errorf("sound format changed")
 	}
+	n.Lock()
+	stream, id := n.stream, n.id
+	audioData, sampleRate, channels := n.audioData, n.sampleRate, n.channels
+	n.stream, n.id, n.audioData = nil, 0, nil
+	n.Unlock()
+
+	o.Lock()
+	oldStream, oldID := o.stream, o.id
+	o.stream, o.id = stream, id
+	o.audioData, o.sampleRate, o.channels = audioData, sampleRate, channels
+	o.dataPos = 0
+	isPlaying := o.isPlaying
+	o.Unlock()
+
+	switchSoundStream(o, id, oldID, stream, oldStream, isPlaying)
+	return nil
+}
+
+// 关闭播放器，释放资源
+func (o *mp3Sound) Close() {
+	o.Lock()
+	stream := o.stream
+	o.stream = nil
+	o.isPlaying = false
+	o.audioData = nil
+	o.Unlock()
+
+	// 音频回调会加锁，销毁音频流时不能持有锁，Stop也会加锁，不能在这里调用
 	unregisterSound(o.id)
+	if stream != nil {
+		sdl.DestroyAudioStream(stream)
+	}
 }
 
 // 获取声音类型
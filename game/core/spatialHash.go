@@ -0,0 +1,538 @@
+package core
+
+import (
+	"math"
+	"sort"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// 空间哈希默认格子大小，大约是最大碰撞器的两倍
+const SpatialHashCellSize = 128.0
+
+// 空间哈希默认边距，包围盒向外扩展，覆盖一次tick内的移动
+const SpatialHashMargin = 8.0
+
+// 碰撞器过滤，返回false的碰撞器不参与查询
+type ColliderFilter func(IObjectCollider) bool
+
+// 按掩码过滤，只保留层在掩码内的非触发器碰撞器，和碰撞系统的规则一致
+func LayerFilter(mask CollisionLayer) ColliderFilter {
+	return func(collider IObjectCollider) bool {
+		return !collider.GetTrigger() && collider.GetCollisionLayer()&mask != 0
+	}
+}
+
+// 射线命中结果
+type RaycastHit struct {
+	// 命中的碰撞器
+	Collider IObjectCollider
+	// 命中点(世界坐标系)
+	Point mgl32.Vec2
+	// 起点到命中点的距离
+	Distance float32
+}
+
+// 格子坐标
+type cellKey struct {
+	x, y int32
+}
+
+// 空间哈希中的一项
+type spatialEntry struct {
+	// 碰撞器
+	collider IObjectCollider
+	// 加入顺序，查询结果按加入顺序排列，保证结果稳定
+	order uint64
+	// 最后一次注册的刷新次数，没有再注册的会被移除
+	stamp uint64
+	// 覆盖的格子范围
+	minCell, maxCell cellKey
+	// 查询去重标记
+	queryMark uint64
+}
+
+// 空间哈希，碰撞器按包围盒放入固定大小的格子，查询时只检查附近格子里的碰撞器
+// 碰撞器每次tick注册自己，场景在世界对象更新完后刷新位置并移除没有注册的碰撞器
+type SpatialHash struct {
+	// 格子大小
+	cellSize float32
+	// 包围盒边距
+	margin float32
+	// 格子里的碰撞器
+	cells map[cellKey][]*spatialEntry
+	// 所有碰撞器，按加入顺序
+	entries []*spatialEntry
+	// 碰撞器到项的索引
+	index map[IObjectCollider]*spatialEntry
+	// 下一个加入顺序
+	nextOrder uint64
+	// 刷新次数
+	stamp uint64
+	// 查询次数，用于去重
+	queryStamp uint64
+	// 所有项覆盖的格子范围，查询不会超出这个范围
+	gridMin, gridMax cellKey
+	// 格子范围是否有效，没有项时无效
+	hasGrid bool
+}
+
+// 创建空间哈希
+func CreateSpatialHash(cellSize float32) *SpatialHash {
+	if cellSize <= 0.0 {
+		cellSize = SpatialHashCellSize
+	}
+	return &SpatialHash{
+		cellSize: cellSize,
+		margin:   SpatialHashMargin,
+		cells:    make(map[cellKey][]*spatialEntry),
+		index:    make(map[IObjectCollider]*spatialEntry),
+	}
+}
+
+// 获取格子大小
+func (h *SpatialHash) GetCellSize() float32 {
+	return h.cellSize
+}
+
+// 获取包围盒边距
+func (h *SpatialHash) GetMargin() float32 {
+	return h.margin
+}
+
+// 设置包围盒边距，移动快的碰撞器较多时调大
+func (h *SpatialHash) SetMargin(margin float32) {
+	h.margin = max(margin, 0.0)
+}
+
+// 获取碰撞器数量
+func (h *SpatialHash) GetCount() int {
+	return len(h.entries)
+}
+
+// 按注册顺序遍历所有碰撞器
+func (h *SpatialHash) ForEach(fn func(IObjectCollider)) {
+	for _, entry := range h.entries {
+		fn(entry.collider)
+	}
+}
+
+// 注册碰撞器，已经注册时只标记存活，位置在刷新时更新
+func (h *SpatialHash) Insert(collider IObjectCollider) {
+	if entry, ok := h.index[collider]; ok {
+		entry.stamp = h.stamp
+		return
+	}
+	entry := &spatialEntry{
+		collider: collider,
+		order:    h.nextOrder,
+		stamp:    h.stamp,
+	}
+	h.nextOrder++
+	entry.minCell, entry.maxCell = h.getCellRange(collider)
+	h.addToCells(entry)
+	h.entries = append(h.entries, entry)
+	h.index[collider] = entry
+}
+
+// 移除碰撞器
+func (h *SpatialHash) Remove(collider IObjectCollider) {
+	entry, ok := h.index[collider]
+	if !ok {
+		return
+	}
+	h.removeFromCells(entry)
+	delete(h.index, collider)
+	for i, e := range h.entries {
+		if e == entry {
+			h.entries = append(h.entries[:i], h.entries[i+1:]...)
+			break
+		}
+	}
+}
+
+// 刷新，移除这次没有注册的碰撞器，更新其余碰撞器所在的格子，场景在世界对象更新完后调用
+func (h *SpatialHash) Refresh() {
+	entries := h.entries[:0]
+	for _, entry := range h.entries {
+		if entry.stamp != h.stamp {
+			h.removeFromCells(entry)
+			delete(h.index, entry.collider)
+			continue
+		}
+		minCell, maxCell := h.getCellRange(entry.collider)
+		if minCell != entry.minCell || maxCell != entry.maxCell {
+			h.removeFromCells(entry)
+			entry.minCell, entry.maxCell = minCell, maxCell
+			h.addToCells(entry)
+		}
+		entries = append(entries, entry)
+	}
+	// 不持有移除的碰撞器
+	clear(h.entries[len(entries):])
+	h.entries = entries
+	h.stamp++
+	// 重新计算格子范围，移除的项不再占用
+	h.hasGrid = false
+	for _, entry := range h.entries {
+		h.growGrid(entry)
+	}
+}
+
+// 清空
+func (h *SpatialHash) Clear() {
+	clear(h.cells)
+	clear(h.index)
+	clear(h.entries)
+	h.entries = h.entries[:0]
+	h.hasGrid = false
+}
+
+// 查询和形状相交的碰撞器，结果按注册顺序
+func (h *SpatialHash) OverlapShape(shape *ColliderShape, filter ColliderFilter) []IObjectCollider {
+	var result []*spatialEntry
+	boundsMin, boundsMax := shape.GetBounds()
+	h.visit(boundsMin, boundsMax, func(entry *spatialEntry) {
+		if !h.accept(entry, filter) {
+			return
+		}
+		other := entry.collider.GetShape()
+		if CollideShapes(shape, &other) {
+			result = append(result, entry)
+		}
+	})
+	return sortedColliders(result)
+}
+
+// 查询和圆相交的碰撞器
+func (h *SpatialHash) OverlapCircle(center mgl32.Vec2, radius float32, filter ColliderFilter) []IObjectCollider {
+	shape := ColliderShape{Type: ColliderTypeCircle, A: center, B: center, Radius: radius}
+	return h.OverlapShape(&shape, filter)
+}
+
+// 查询和矩形相交的碰撞器，矩形和坐标轴对齐
+func (h *SpatialHash) OverlapRect(pos, size mgl32.Vec2, filter ColliderFilter) []IObjectCollider {
+	shape := ColliderShape{Type: ColliderTypeRect, Points: []mgl32.Vec2{
+		pos,
+		{pos.X() + size.X(), pos.Y()},
+		pos.Add(size),
+		{pos.X(), pos.Y() + size.Y()},
+	}}
+	return h.OverlapShape(&shape, filter)
+}
+
+// 查询和碰撞器相交的其他碰撞器，不包括自己
+func (h *SpatialHash) OverlapCollider(collider IObjectCollider, filter ColliderFilter) []IObjectCollider {
+	shape := collider.GetShape()
+	return h.OverlapShape(&shape, func(other IObjectCollider) bool {
+		return other != collider && (filter == nil || filter(other))
+	})
+}
+
+// 射线检测，返回最近的命中，沿射线经过的格子逐个检查，找到命中后不再继续
+func (h *SpatialHash) Raycast(origin, direction mgl32.Vec2, maxDistance float32, filter ColliderFilter) (RaycastHit, bool) {
+	if direction.Len() == 0.0 || maxDistance <= 0.0 || !h.hasGrid {
+		return RaycastHit{}, false
+	}
+	direction = direction.Normalize()
+	h.queryStamp++
+	inf := float32(math.Inf(1))
+
+	// 格子遍历，每一步走到x或y方向较近的格子边界
+	cell := h.getCell(origin)
+	stepX, nextX, deltaX := h.getRayStep(origin.X(), direction.X(), cell.x)
+	stepY, nextY, deltaY := h.getRayStep(origin.Y(), direction.Y(), cell.y)
+
+	hit := RaycastHit{Distance: inf}
+	var hitEntry *spatialEntry
+	for {
+		// 射线离开格子范围后不会再有命中，maxDistance是无穷大时也能结束
+		if h.leavingGrid(cell.x, stepX, h.gridMin.x, h.gridMax.x) || h.leavingGrid(cell.y, stepY, h.gridMin.y, h.gridMax.y) {
+			break
+		}
+		for _, entry := range h.cells[cell] {
+			if entry.queryMark == h.queryStamp {
+				continue
+			}
+			entry.queryMark = h.queryStamp
+			if !h.accept(entry, filter) {
+				continue
+			}
+			shape := entry.collider.GetShape()
+			distance, ok := RaycastShape(&shape, origin, direction, maxDistance)
+			if !ok {
+				continue
+			}
+			// 距离相同时取先注册的，保证结果稳定
+			if hitEntry == nil || distance < hit.Distance || (distance == hit.Distance && entry.order < hitEntry.order) {
+				hit.Distance = distance
+				hitEntry = entry
+			}
+		}
+		// 命中点在当前格子内，后面的格子不会有更近的命中
+		exit := min(nextX, nextY)
+		if hitEntry != nil && hit.Distance <= exit {
+			break
+		}
+		if exit > maxDistance || exit == inf {
+			break
+		}
+		if nextX < nextY {
+			cell.x += stepX
+			nextX += deltaX
+		} else {
+			cell.y += stepY
+			nextY += deltaY
+		}
+	}
+	if hitEntry == nil {
+		return RaycastHit{}, false
+	}
+	hit.Collider = hitEntry.collider
+	hit.Point = origin.Add(direction.Mul(hit.Distance))
+	return hit, true
+}
+
+// 查询离点最近的count个碰撞器，距离是点到形状的距离，只找maxDistance以内的，结果按距离从近到远
+func (h *SpatialHash) Nearest(pos mgl32.Vec2, count int, maxDistance float32, filter ColliderFilter) []IObjectCollider {
+	if count <= 0 || maxDistance < 0.0 || !h.hasGrid {
+		return nil
+	}
+	h.queryStamp++
+	type nearestItem struct {
+		entry    *spatialEntry
+		distance float32
+	}
+	var found []nearestItem
+	less := func(i, j int) bool {
+		if found[i].distance != found[j].distance {
+			return found[i].distance < found[j].distance
+		}
+		return found[i].entry.order < found[j].entry.order
+	}
+	check := func(cell cellKey) {
+		for _, entry := range h.cells[cell] {
+			if entry.queryMark == h.queryStamp {
+				continue
+			}
+			entry.queryMark = h.queryStamp
+			if !h.accept(entry, filter) {
+				continue
+			}
+			shape := entry.collider.GetShape()
+			if distance := ShapeDistance(&shape, pos); distance <= maxDistance {
+				found = append(found, nearestItem{entry, distance})
+			}
+		}
+	}
+
+	// 从点所在的格子一圈一圈向外找，第ring圈之外的格子离点至少ring个格子远
+	center := h.getCell(pos)
+	// 最多找到覆盖整个格子范围，maxDistance是无穷大时也不会超出
+	maxRing := h.getMaxRing(center)
+	if rings := float64(maxDistance)/float64(h.cellSize) + 1.0; rings < float64(maxRing) {
+		maxRing = int32(rings)
+	}
+	// 格子范围外的圈是空的，从最近的有格子的圈开始
+	for ring := h.getMinRing(center); ring <= maxRing; ring++ {
+		h.visitRing(center, ring, check)
+		if len(found) >= count {
+			sort.Slice(found, less)
+			if found[count-1].distance <= float32(ring)*h.cellSize {
+				break
+			}
+		}
+	}
+	sort.Slice(found, less)
+	if len(found) > count {
+		found = found[:count]
+	}
+	result := make([]IObjectCollider, 0, len(found))
+	for _, item := range found {
+		result = append(result, item.entry.collider)
+	}
+	return result
+}
+
+// 点所在的格子
+func (h *SpatialHash) getCell(p mgl32.Vec2) cellKey {
+	return cellKey{x: h.toCell(p.X()), y: h.toCell(p.Y())}
+}
+
+// 坐标所在的格子，超出int32的坐标限制在范围内，无穷大和NaN不会溢出
+func (h *SpatialHash) toCell(v float32) int32 {
+	c := math.Floor(float64(v) / float64(h.cellSize))
+	if math.IsNaN(c) {
+		return 0
+	}
+	return int32(min(max(c, math.MinInt32), math.MaxInt32))
+}
+
+// 项覆盖的格子加入格子范围
+func (h *SpatialHash) growGrid(entry *spatialEntry) {
+	if !h.hasGrid {
+		h.gridMin, h.gridMax = entry.minCell, entry.maxCell
+		h.hasGrid = true
+		return
+	}
+	h.gridMin = cellKey{min(h.gridMin.x, entry.minCell.x), min(h.gridMin.y, entry.minCell.y)}
+	h.gridMax = cellKey{max(h.gridMax.x, entry.maxCell.x), max(h.gridMax.y, entry.maxCell.y)}
+}
+
+// 格子范围和查询范围的交集，没有交集时返回false
+func (h *SpatialHash) clampToGrid(minCell, maxCell cellKey) (cellKey, cellKey, bool) {
+	if !h.hasGrid {
+		return minCell, maxCell, false
+	}
+	minCell = cellKey{max(minCell.x, h.gridMin.x), max(minCell.y, h.gridMin.y)}
+	maxCell = cellKey{min(maxCell.x, h.gridMax.x), min(maxCell.y, h.gridMax.y)}
+	return minCell, maxCell, minCell.x <= maxCell.x && minCell.y <= maxCell.y
+}
+
+// 射线在一个轴上是否已经离开格子范围并且不会再回来
+func (h *SpatialHash) leavingGrid(cell, step, gridMin, gridMax int32) bool {
+	return (step >= 0 && cell > gridMax) || (step <= 0 && cell < gridMin)
+}
+
+// 从格子到格子范围的圈数，格子在范围内时为0
+func (h *SpatialHash) getMinRing(center cellKey) int32 {
+	ring := max(
+		int64(h.gridMin.x)-int64(center.x),
+		int64(center.x)-int64(h.gridMax.x),
+		int64(h.gridMin.y)-int64(center.y),
+		int64(center.y)-int64(h.gridMax.y),
+		0,
+	)
+	return int32(min(ring, math.MaxInt32-1))
+}
+
+// 遍历以center为中心第ring圈的格子，只遍历格子范围内的部分
+func (h *SpatialHash) visitRing(center cellKey, ring int32, fn func(cellKey)) {
+	cx, cy, r := int64(center.x), int64(center.y), int64(ring)
+	gridMinX, gridMinY := int64(h.gridMin.x), int64(h.gridMin.y)
+	gridMaxX, gridMaxY := int64(h.gridMax.x), int64(h.gridMax.y)
+	// 上下两行
+	for _, y := range []int64{cy - r, cy + r} {
+		if y < gridMinY || y > gridMaxY {
+			continue
+		}
+		for x := max(cx-r, gridMinX); x <= min(cx+r, gridMaxX); x++ {
+			fn(cellKey{int32(x), int32(y)})
+		}
+		if r == 0 {
+			return
+		}
+	}
+	// 左右两列，不包括角上的格子
+	for _, x := range []int64{cx - r, cx + r} {
+		if x < gridMinX || x > gridMaxX {
+			continue
+		}
+		for y := max(cy-r+1, gridMinY); y <= min(cy+r-1, gridMaxY); y++ {
+			fn(cellKey{int32(x), int32(y)})
+		}
+	}
+}
+
+// 从格子到格子范围最远角的圈数
+func (h *SpatialHash) getMaxRing(center cellKey) int32 {
+	ring := max(
+		int64(center.x)-int64(h.gridMin.x),
+		int64(h.gridMax.x)-int64(center.x),
+		int64(center.y)-int64(h.gridMin.y),
+		int64(h.gridMax.y)-int64(center.y),
+	)
+	return int32(min(ring, math.MaxInt32-1))
+}
+
+// 碰撞器包围盒加上边距覆盖的格子范围
+func (h *SpatialHash) getCellRange(collider IObjectCollider) (cellKey, cellKey) {
+	shape := collider.GetShape()
+	boundsMin, boundsMax := shape.GetBounds()
+	margin := mgl32.Vec2{h.margin, h.margin}
+	return h.getCell(boundsMin.Sub(margin)), h.getCell(boundsMax.Add(margin))
+}
+
+// 把项加入覆盖的格子
+func (h *SpatialHash) addToCells(entry *spatialEntry) {
+	for y := entry.minCell.y; y <= entry.maxCell.y; y++ {
+		for x := entry.minCell.x; x <= entry.maxCell.x; x++ {
+			key := cellKey{x, y}
+			h.cells[key] = append(h.cells[key], entry)
+		}
+	}
+	h.growGrid(entry)
+}
+
+// 把项从覆盖的格子中移除，空格子删除
+func (h *SpatialHash) removeFromCells(entry *spatialEntry) {
+	for y := entry.minCell.y; y <= entry.maxCell.y; y++ {
+		for x := entry.minCell.x; x <= entry.maxCell.x; x++ {
+			key := cellKey{x, y}
+			cell := h.cells[key]
+			for i, e := range cell {
+				if e == entry {
+					cell = append(cell[:i], cell[i+1:]...)
+					break
+				}
+			}
+			if len(cell) == 0 {
+				delete(h.cells, key)
+			} else {
+				h.cells[key] = cell
+			}
+		}
+	}
+}
+
+// 遍历包围盒覆盖的格子里的项，每项只遍历一次
+func (h *SpatialHash) visit(boundsMin, boundsMax mgl32.Vec2, fn func(*spatialEntry)) {
+	h.queryStamp++
+	minCell, maxCell, ok := h.clampToGrid(h.getCell(boundsMin), h.getCell(boundsMax))
+	if !ok {
+		return
+	}
+	for y := minCell.y; y <= maxCell.y; y++ {
+		for x := minCell.x; x <= maxCell.x; x++ {
+			for _, entry := range h.cells[cellKey{x, y}] {
+				if entry.queryMark == h.queryStamp {
+					continue
+				}
+				entry.queryMark = h.queryStamp
+				fn(entry)
+			}
+		}
+	}
+}
+
+// 项是否参与查询，碰撞器和父节点都要是激活的，父节点等待移除时不参与
+func (h *SpatialHash) accept(entry *spatialEntry, filter ColliderFilter) bool {
+	if !isColliderAlive(entry.collider) {
+		return false
+	}
+	return filter == nil || filter(entry.collider)
+}
+
+// 射线在一个轴上的格子步进，返回步进方向、到下一条格子边界的距离和每走一格的距离
+func (h *SpatialHash) getRayStep(origin, direction float32, cell int32) (int32, float32, float32) {
+	inf := float32(math.Inf(1))
+	switch {
+	case direction > 0.0:
+		return 1, (float32(cell+1)*h.cellSize - origin) / direction, h.cellSize / direction
+	case direction < 0.0:
+		return -1, (float32(cell)*h.cellSize - origin) / direction, -h.cellSize / direction
+	}
+	return 0, inf, inf
+}
+
+// 按注册顺序排列并取出碰撞器
+func sortedColliders(entries []*spatialEntry) []IObjectCollider {
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].order < entries[j].order
+	})
+	result := make([]IObjectCollider, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, entry.collider)
+	}
+	return result
+}
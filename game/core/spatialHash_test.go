@@ -0,0 +1,203 @@
+package core
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// 测试用的父节点
+type testOwner struct {
+	ObjectWorld
+	// 名字，用于检查结果
+	name string
+}
+
+// 获取名字
+func (o *testOwner) getName() string {
+	return o.name
+}
+
+// 获取碰撞器父节点的名字
+func ownerName(collider IObjectCollider) string {
+	return collider.GetParent().(interface{ getName() string }).getName()
+}
+
+// 创建依附在pos上的碰撞器，碰撞器中心在pos
+func newTestCollider(name string, pos, size mgl32.Vec2, colliderType ColliderType) *ObjectCollider {
+	owner := &testOwner{name: name}
+	owner.Init()
+	owner.Position = pos
+	collider := &ObjectCollider{}
+	collider.Init()
+	collider.SetAnchorType(AnchorTypeCenter)
+	collider.SetSize(size)
+	collider.SetParent(owner)
+	collider.SetColliderType(colliderType)
+	collider.SetCollisionLayer(CollisionLayerDefault)
+	return collider
+}
+
+// 取出碰撞器父节点的名字
+func colliderNames(colliders []IObjectCollider) []string {
+	names := make([]string, 0, len(colliders))
+	for _, collider := range colliders {
+		names = append(names, ownerName(collider))
+	}
+	return names
+}
+
+// 创建空间哈希并注册碰撞器
+func newTestHash(colliders ...*ObjectCollider) *SpatialHash {
+	h := CreateSpatialHash(32.0)
+	for _, collider := range colliders {
+		h.Insert(collider)
+	}
+	h.Refresh()
+	return h
+}
+
+func TestSpatialHashOverlap(t *testing.T) {
+	a := newTestCollider("a", mgl32.Vec2{0.0, 0.0}, mgl32.Vec2{10.0, 10.0}, ColliderTypeCircle)
+	b := newTestCollider("b", mgl32.Vec2{100.0, 0.0}, mgl32.Vec2{10.0, 10.0}, ColliderTypeRect)
+	c := newTestCollider("c", mgl32.Vec2{8.0, 0.0}, mgl32.Vec2{10.0, 10.0}, ColliderTypeCircle)
+	h := newTestHash(c, a, b)
+
+	tests := []struct {
+		name   string
+		result []IObjectCollider
+		want   []string
+	}{
+		{"circle", h.OverlapCircle(mgl32.Vec2{0.0, 0.0}, 5.0, nil), []string{"c", "a"}},
+		{"circle far", h.OverlapCircle(mgl32.Vec2{500.0, 500.0}, 5.0, nil), []string{}},
+		{"circle inf", h.OverlapCircle(mgl32.Vec2{}, float32(math.Inf(1)), nil), []string{"c", "a", "b"}},
+		{"rect", h.OverlapRect(mgl32.Vec2{90.0, -10.0}, mgl32.Vec2{20.0, 20.0}, nil), []string{"b"}},
+		{"collider", h.OverlapCollider(a, nil), []string{"c"}},
+		{"filter", h.OverlapCircle(mgl32.Vec2{}, 1000.0, func(other IObjectCollider) bool { return other != c }), []string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := colliderNames(tt.result); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpatialHashRefresh(t *testing.T) {
+	a := newTestCollider("a", mgl32.Vec2{0.0, 0.0}, mgl32.Vec2{10.0, 10.0}, ColliderTypeCircle)
+	b := newTestCollider("b", mgl32.Vec2{0.0, 0.0}, mgl32.Vec2{10.0, 10.0}, ColliderTypeCircle)
+	h := newTestHash(a, b)
+
+	// 移动后刷新，格子跟着更新
+	a.GetParent().(*testOwner).Position = mgl32.Vec2{300.0, 300.0}
+	h.Insert(a)
+	h.Insert(b)
+	h.Refresh()
+	if got := colliderNames(h.OverlapCircle(mgl32.Vec2{300.0, 300.0}, 1.0, nil)); !reflect.DeepEqual(got, []string{"a"}) {
+		t.Errorf("after move got %v, want [a]", got)
+	}
+
+	// 没有再注册的碰撞器被移除
+	h.Insert(a)
+	h.Refresh()
+	if h.GetCount() != 1 {
+		t.Errorf("count = %d, want 1", h.GetCount())
+	}
+	if got := h.OverlapCircle(mgl32.Vec2{}, 10.0, nil); len(got) != 0 {
+		t.Errorf("removed collider still found: %v", colliderNames(got))
+	}
+
+	// 父节点等待移除时不参与查询
+	a.GetParent().SetNeedRemove(true)
+	if got := h.OverlapCircle(mgl32.Vec2{300.0, 300.0}, 1.0, nil); len(got) != 0 {
+		t.Errorf("collider of removed parent found: %v", colliderNames(got))
+	}
+}
+
+func TestSpatialHashRaycast(t *testing.T) {
+	inf := float32(math.Inf(1))
+	near := newTestCollider("near", mgl32.Vec2{100.0, 0.0}, mgl32.Vec2{10.0, 10.0}, ColliderTypeCircle)
+	far := newTestCollider("far", mgl32.Vec2{300.0, 0.0}, mgl32.Vec2{10.0, 10.0}, ColliderTypeRect)
+	// 和near在同一个位置，注册更晚，距离相同时取先注册的
+	twin := newTestCollider("twin", mgl32.Vec2{100.0, 0.0}, mgl32.Vec2{10.0, 10.0}, ColliderTypeCircle)
+	h := newTestHash(far, near, twin)
+
+	tests := []struct {
+		name        string
+		origin      mgl32.Vec2
+		direction   mgl32.Vec2
+		maxDistance float32
+		hit         string
+		distance    float32
+	}{
+		{"nearest first", mgl32.Vec2{0.0, 0.0}, mgl32.Vec2{1.0, 0.0}, 1000.0, "near", 95.0},
+		{"tie order", mgl32.Vec2{0.0, 0.0}, mgl32.Vec2{2.0, 0.0}, inf, "near", 95.0},
+		{"from behind", mgl32.Vec2{400.0, 0.0}, mgl32.Vec2{-1.0, 0.0}, 1000.0, "far", 95.0},
+		{"too short", mgl32.Vec2{0.0, 0.0}, mgl32.Vec2{1.0, 0.0}, 50.0, "", 0.0},
+		{"miss", mgl32.Vec2{0.0, 100.0}, mgl32.Vec2{1.0, 0.0}, 1000.0, "", 0.0},
+		{"inf miss", mgl32.Vec2{0.0, 100.0}, mgl32.Vec2{1.0, 0.0}, inf, "", 0.0},
+		{"inf away", mgl32.Vec2{0.0, 0.0}, mgl32.Vec2{-1.0, 0.0}, inf, "", 0.0},
+		{"inf vertical", mgl32.Vec2{100.0, -1000.0}, mgl32.Vec2{0.0, 1.0}, inf, "near", 995.0},
+		{"zero direction", mgl32.Vec2{}, mgl32.Vec2{}, 1000.0, "", 0.0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hit, ok := h.Raycast(tt.origin, tt.direction, tt.maxDistance, nil)
+			if !ok {
+				if tt.hit != "" {
+					t.Fatalf("no hit, want %s", tt.hit)
+				}
+				return
+			}
+			if tt.hit == "" {
+				t.Fatalf("hit %s, want no hit", ownerName(hit.Collider))
+			}
+			if name := ownerName(hit.Collider); name != tt.hit {
+				t.Errorf("hit %s, want %s", name, tt.hit)
+			}
+			if math.Abs(float64(hit.Distance-tt.distance)) > 1e-3 {
+				t.Errorf("distance = %v, want %v", hit.Distance, tt.distance)
+			}
+		})
+	}
+
+	if _, ok := CreateSpatialHash(32.0).Raycast(mgl32.Vec2{}, mgl32.Vec2{1.0, 0.0}, inf, nil); ok {
+		t.Error("raycast on empty hash hit something")
+	}
+}
+
+func TestSpatialHashNearest(t *testing.T) {
+	inf := float32(math.Inf(1))
+	a := newTestCollider("a", mgl32.Vec2{50.0, 0.0}, mgl32.Vec2{10.0, 10.0}, ColliderTypeCircle)
+	b := newTestCollider("b", mgl32.Vec2{-20.0, 0.0}, mgl32.Vec2{10.0, 10.0}, ColliderTypeCircle)
+	c := newTestCollider("c", mgl32.Vec2{500.0, 500.0}, mgl32.Vec2{10.0, 10.0}, ColliderTypeRect)
+	// 和b距离相同，注册更晚
+	d := newTestCollider("d", mgl32.Vec2{0.0, 20.0}, mgl32.Vec2{10.0, 10.0}, ColliderTypeCircle)
+	h := newTestHash(a, b, c, d)
+
+	tests := []struct {
+		name        string
+		pos         mgl32.Vec2
+		count       int
+		maxDistance float32
+		want        []string
+	}{
+		{"one", mgl32.Vec2{}, 1, 1000.0, []string{"b"}},
+		{"tie order", mgl32.Vec2{}, 2, 1000.0, []string{"b", "d"}},
+		{"all", mgl32.Vec2{}, 10, 1000.0, []string{"b", "d", "a", "c"}},
+		{"max distance", mgl32.Vec2{}, 10, 50.0, []string{"b", "d", "a"}},
+		{"inf", mgl32.Vec2{}, 10, inf, []string{"b", "d", "a", "c"}},
+		{"far outside", mgl32.Vec2{1e9, 1e9}, 1, inf, []string{"c"}},
+		{"none", mgl32.Vec2{}, 0, inf, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := colliderNames(h.Nearest(tt.pos, tt.count, tt.maxDistance, nil)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
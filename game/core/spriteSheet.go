@@ -0,0 +1,355 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/SunshineZzzz/purego-sdl3/sdl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// 动画播放方向
+type AnimDirection int
+
+const (
+	// 正向播放
+	AnimDirectionForward AnimDirection = iota
+	// 反向播放
+	AnimDirectionReverse
+	// 来回播放
+	AnimDirectionPingPong
+)
+
+// 解析播放方向，支持Aseprite的写法
+func ParseAnimDirection(name string) (AnimDirection, error) {
+	switch strings.ToLower(name) {
+	case "", "forward":
+		return AnimDirectionForward, nil
+	case "reverse":
+		return AnimDirectionReverse, nil
+	case "pingpong":
+		return AnimDirectionPingPong, nil
+	}
+	return AnimDirectionForward, fmt.Errorf("unknown anim direction %q", name)
+}
+
+// 精灵表中的一帧
+type SpriteFrame struct {
+	// 帧在图片中的区域
+	Rect sdl.FRect
+	// 裁掉透明边后，帧在原始大小中的偏移
+	TrimOffset mgl32.Vec2
+	// 帧的原始大小
+	SourceSize mgl32.Vec2
+	// 持续时间，单位秒，0表示使用动画的帧率
+	Duration float32
+	// 轴心，相对原始大小的比例
+	Pivot mgl32.Vec2
+	// 是否指定了轴心，没有指定时使用锚点
+	HasPivot bool
+}
+
+// 精灵表中的一段动画
+type SpriteClip struct {
+	// 名字
+	Name string
+	// 播放方向
+	Direction AnimDirection
+	// 按播放顺序排列的帧索引，已经按播放方向展开
+	Frames []int
+}
+
+// 精灵表，描述一张图片中的所有帧和动画
+type SpriteSheet struct {
+	// 图片路径
+	Image string
+	// 所有帧
+	Frames []SpriteFrame
+	// 所有动画
+	clips map[string]*SpriteClip
+	// 动画名字，按文件中的顺序
+	clipNames []string
+}
+
+// 获取动画，名字为空时返回默认动画
+func (s *SpriteSheet) GetClip(name string) *SpriteClip {
+	if name == "" {
+		return s.GetDefaultClip()
+	}
+	return s.clips[name]
+}
+
+// 获取默认动画，有动画时是第一个动画，否则按顺序播放所有帧
+func (s *SpriteSheet) GetDefaultClip() *SpriteClip {
+	if len(s.clipNames) > 0 {
+		return s.clips[s.clipNames[0]]
+	}
+	frames := make([]int, len(s.Frames))
+	for i := range frames {
+		frames[i] = i
+	}
+	return &SpriteClip{Frames: frames}
+}
+
+// 获取所有动画名字
+func (s *SpriteSheet) GetClipNames() []string {
+	return s.clipNames
+}
+
+// 添加动画，from和to都包含在内
+func (s *SpriteSheet) AddClip(name string, from, to int, direction AnimDirection) error {
+	if from < 0 || to >= len(s.Frames) || from > to {
+		return fmt.Errorf("clip %q frame range [%d,%d] out of %d frames", name, from, to, len(s.Frames))
+	}
+	frames := make([]int, 0, to-from+1)
+	switch direction {
+	case AnimDirectionReverse:
+		for i := to; i >= from; i-- {
+			frames = append(frames, i)
+		}
+	case AnimDirectionPingPong:
+		for i := from; i <= to; i++ {
+			frames = append(frames, i)
+		}
+		// 两端的帧不重复播放
+		for i := to - 1; i > from; i-- {
+			frames = append(frames, i)
+		}
+	default:
+		for i := from; i <= to; i++ {
+			frames = append(frames, i)
+		}
+	}
+	if _, ok := s.clips[name]; !ok {
+		s.clipNames = append(s.clipNames, name)
+	}
+	s.clips[name] = &SpriteClip{Name: name, Direction: direction, Frames: frames}
+	return nil
+}
+
+// 获取图片对应的精灵表路径，和图片同名的json文件
+func GetSpriteSheetPath(imagePath string) string {
+	return strings.TrimSuffix(imagePath, path.Ext(imagePath)) + ".json"
+}
+
+// 精灵表json中的矩形
+type sheetRect struct {
+	X float32 `json:"x"`
+	Y float32 `json:"y"`
+	W float32 `json:"w"`
+	H float32 `json:"h"`
+}
+
+// 精灵表json中的大小
+type sheetSize struct {
+	W float32 `json:"w"`
+	H float32 `json:"h"`
+}
+
+// 精灵表json中的点
+type sheetPoint struct {
+	X float32 `json:"x"`
+	Y float32 `json:"y"`
+}
+
+// 精灵表json中的一帧，Aseprite和TexturePacker格式相同
+type sheetFrame struct {
+	// 帧名字，数组格式时才有
+	Filename string `json:"filename"`
+	// 帧在图片中的区域
+	Frame sheetRect `json:"frame"`
+	// 是否旋转，不支持
+	Rotated bool `json:"rotated"`
+	// 裁剪后在原始大小中的区域
+	SpriteSourceSize *sheetRect `json:"spriteSourceSize"`
+	// 原始大小
+	SourceSize *sheetSize `json:"sourceSize"`
+	// 持续时间，单位毫秒，Aseprite导出
+	Duration float32 `json:"duration"`
+	// 轴心，相对原始大小的比例，TexturePacker导出
+	Pivot *sheetPoint `json:"pivot"`
+}
+
+// 精灵表json
+type sheetFile struct {
+	// 帧，可能是对象也可能是数组
+	Frames json.RawMessage `json:"frames"`
+	// TexturePacker导出的动画，名字到帧名字列表
+	Animations map[string][]string `json:"animations"`
+	// 元数据
+	Meta struct {
+		// 图片文件名，相对json所在目录
+		Image string `json:"image"`
+		// Aseprite动画标签
+		FrameTags []struct {
+			Name      string `json:"name"`
+			From      int    `json:"from"`
+			To        int    `json:"to"`
+			Direction string `json:"direction"`
+		} `json:"frameTags"`
+		// Aseprite切片，用于指定轴心
+		Slices []struct {
+			Name string `json:"name"`
+			Keys []struct {
+				Frame  int         `json:"frame"`
+				Bounds sheetRect   `json:"bounds"`
+				Pivot  *sheetPoint `json:"pivot"`
+			} `json:"keys"`
+		} `json:"slices"`
+	} `json:"meta"`
+}
+
+// 从文件加载精灵表，支持Aseprite和TexturePacker导出的json，帧可以是对象或者数组格式
+func LoadSpriteSheet(fsys fs.FS, filePath string) (*SpriteSheet, error) {
+	data, err := fs.ReadFile(fsys, filePath)
+	if err != nil {
+		return nil, err
+	}
+	sheet, err := ParseSpriteSheet(data, path.Dir(filePath))
+	if err != nil {
+		return nil, fmt.Errorf("load sprite sheet error,%s,%v", filePath, err)
+	}
+	return sheet, nil
+}
+
+// 解析精灵表json，dir为json所在目录，用于确定图片路径
+func ParseSpriteSheet(data []byte, dir string) (*SpriteSheet, error) {
+	file := sheetFile{}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	frames, err := decodeSheetFrames(file.Frames)
+	if err != nil {
+		return nil, err
+	}
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("no frames")
+	}
+	sheet := &SpriteSheet{
+		Frames:    make([]SpriteFrame, 0, len(frames)),
+		clips:     make(map[string]*SpriteClip),
+		clipNames: make([]string, 0),
+	}
+	if file.Meta.Image != "" {
+		sheet.Image = path.Join(dir, file.Meta.Image)
+	}
+	names := make(map[string]int, len(frames))
+	for i, frame := range frames {
+		if frame.Rotated {
+			return nil, fmt.Errorf("frame %q is rotated, rotated frames are not supported", frame.Filename)
+		}
+		spriteFrame := SpriteFrame{
+			Rect:       sdl.FRect{X: frame.Frame.X, Y: frame.Frame.Y, W: frame.Frame.W, H: frame.Frame.H},
+			SourceSize: mgl32.Vec2{frame.Frame.W, frame.Frame.H},
+			Duration:   frame.Duration / 1000.0,
+		}
+		if frame.SourceSize != nil {
+			spriteFrame.SourceSize = mgl32.Vec2{frame.SourceSize.W, frame.SourceSize.H}
+		}
+		if frame.SpriteSourceSize != nil {
+			spriteFrame.TrimOffset = mgl32.Vec2{frame.SpriteSourceSize.X, frame.SpriteSourceSize.Y}
+		}
+		if frame.Pivot != nil {
+			spriteFrame.Pivot = mgl32.Vec2{frame.Pivot.X, frame.Pivot.Y}
+			spriteFrame.HasPivot = true
+		}
+		sheet.Frames = append(sheet.Frames, spriteFrame)
+		names[frame.Filename] = i
+	}
+
+	// Aseprite切片的轴心是像素坐标，从指定帧开始生效，直到下一个关键帧
+	for _, slice := range file.Meta.Slices {
+		keys := slice.Keys
+		sort.SliceStable(keys, func(i, j int) bool { return keys[i].Frame < keys[j].Frame })
+		for k, key := range keys {
+			if key.Pivot == nil {
+				continue
+			}
+			end := len(sheet.Frames)
+			if k+1 < len(keys) {
+				end = min(keys[k+1].Frame, end)
+			}
+			for i := max(key.Frame, 0); i < end; i++ {
+				frame := &sheet.Frames[i]
+				if frame.SourceSize.X() <= 0 || frame.SourceSize.Y() <= 0 {
+					continue
+				}
+				frame.Pivot = mgl32.Vec2{
+					(key.Bounds.X + key.Pivot.X) / frame.SourceSize.X(),
+					(key.Bounds.Y + key.Pivot.Y) / frame.SourceSize.Y(),
+				}
+				frame.HasPivot = true
+			}
+		}
+	}
+
+	for _, tag := range file.Meta.FrameTags {
+		direction, err := ParseAnimDirection(tag.Direction)
+		if err != nil {
+			return nil, err
+		}
+		if err = sheet.AddClip(tag.Name, tag.From, tag.To, direction); err != nil {
+			return nil, err
+		}
+	}
+
+	// TexturePacker的动画是帧名字列表，名字按字母排序保证顺序稳定
+	animNames := make([]string, 0, len(file.Animations))
+	for name := range file.Animations {
+		animNames = append(animNames, name)
+	}
+	sort.Strings(animNames)
+	for _, name := range animNames {
+		clip := &SpriteClip{Name: name, Frames: make([]int, 0, len(file.Animations[name]))}
+		for _, frameName := range file.Animations[name] {
+			index, ok := names[frameName]
+			if !ok {
+				return nil, fmt.Errorf("animation %q references unknown frame %q", name, frameName)
+			}
+			clip.Frames = append(clip.Frames, index)
+		}
+		if _, ok := sheet.clips[name]; !ok {
+			sheet.clipNames = append(sheet.clipNames, name)
+		}
+		sheet.clips[name] = clip
+	}
+	return sheet, nil
+}
+
+// 解析帧，对象格式要保持文件中的顺序，帧索引和Aseprite标签对应
+func decodeSheetFrames(raw json.RawMessage) ([]sheetFrame, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	if raw[0] == '[' {
+		frames := make([]sheetFrame, 0)
+		if err := json.Unmarshal(raw, &frames); err != nil {
+			return nil, err
+		}
+		return frames, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	frames := make([]sheetFrame, 0)
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		frame := sheetFrame{}
+		if err = decoder.Decode(&frame); err != nil {
+			return nil, err
+		}
+		frame.Filename, _ = token.(string)
+		frames = append(frames, frame)
+	}
+	return frames, nil
+}
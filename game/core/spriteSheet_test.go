@@ -0,0 +1,146 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/SunshineZzzz/purego-sdl3/sdl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Aseprite导出的对象格式，帧名字不按字母顺序，切片指定轴心
+const asepriteSheet = `{
+	"frames": {
+		"ghost 1.aseprite": {"frame": {"x": 32, "y": 0, "w": 32, "h": 32}, "sourceSize": {"w": 32, "h": 32}, "duration": 100},
+		"ghost 0.aseprite": {"frame": {"x": 0, "y": 0, "w": 32, "h": 32}, "sourceSize": {"w": 32, "h": 32}, "duration": 200},
+		"ghost 2.aseprite": {"frame": {"x": 64, "y": 0, "w": 20, "h": 24}, "spriteSourceSize": {"x": 6, "y": 8, "w": 20, "h": 24}, "sourceSize": {"w": 32, "h": 32}, "duration": 100},
+		"ghost 3.aseprite": {"frame": {"x": 84, "y": 0, "w": 48, "h": 48}, "sourceSize": {"w": 48, "h": 48}, "duration": 50}
+	},
+	"meta": {
+		"image": "ghost.png",
+		"frameTags": [
+			{"name": "float", "from": 0, "to": 2, "direction": "forward"},
+			{"name": "back", "from": 0, "to": 2, "direction": "reverse"},
+			{"name": "bounce", "from": 0, "to": 3, "direction": "pingpong"}
+		],
+		"slices": [{"name": "pivot", "keys": [
+			{"frame": 2, "bounds": {"x": 0, "y": 0, "w": 32, "h": 32}, "pivot": {"x": 16, "y": 24}},
+			{"frame": 0, "bounds": {"x": 0, "y": 0, "w": 32, "h": 32}, "pivot": {"x": 16, "y": 32}}
+		]}]
+	}
+}`
+
+// TexturePacker导出的数组格式，动画是帧名字列表
+const texturePackerSheet = `{
+	"frames": [
+		{"filename": "walk_0", "frame": {"x": 0, "y": 0, "w": 16, "h": 16}, "pivot": {"x": 0.5, "y": 1}},
+		{"filename": "walk_1", "frame": {"x": 16, "y": 0, "w": 16, "h": 16}},
+		{"filename": "jump_0", "frame": {"x": 32, "y": 0, "w": 16, "h": 20}}
+	],
+	"animations": {"walk": ["walk_0", "walk_1"], "jump": ["jump_0"]},
+	"meta": {"image": "../img/hero.png"}
+}`
+
+func TestParseSpriteSheetAseprite(t *testing.T) {
+	sheet, err := ParseSpriteSheet([]byte(asepriteSheet), "assets/sprite")
+	if err != nil {
+		t.Fatalf("parse error,%v", err)
+	}
+	if sheet.Image != "assets/sprite/ghost.png" {
+		t.Errorf("image = %q", sheet.Image)
+	}
+	// 对象格式保持文件中的顺序
+	want := []SpriteFrame{
+		{Rect: sdl.FRect{X: 32.0, W: 32.0, H: 32.0}, SourceSize: mgl32.Vec2{32.0, 32.0}, Duration: 0.1, Pivot: mgl32.Vec2{0.5, 1.0}, HasPivot: true},
+		{Rect: sdl.FRect{X: 0.0, W: 32.0, H: 32.0}, SourceSize: mgl32.Vec2{32.0, 32.0}, Duration: 0.2, Pivot: mgl32.Vec2{0.5, 1.0}, HasPivot: true},
+		{Rect: sdl.FRect{X: 64.0, W: 20.0, H: 24.0}, TrimOffset: mgl32.Vec2{6.0, 8.0}, SourceSize: mgl32.Vec2{32.0, 32.0}, Duration: 0.1, Pivot: mgl32.Vec2{0.5, 0.75}, HasPivot: true},
+		{Rect: sdl.FRect{X: 84.0, W: 48.0, H: 48.0}, SourceSize: mgl32.Vec2{48.0, 48.0}, Duration: 0.05, Pivot: mgl32.Vec2{16.0 / 48.0, 0.5}, HasPivot: true},
+	}
+	if len(sheet.Frames) != len(want) {
+		t.Fatalf("%d frames, want %d", len(sheet.Frames), len(want))
+	}
+	for i := range want {
+		got := sheet.Frames[i]
+		if got.Rect != want[i].Rect || got.TrimOffset != want[i].TrimOffset || got.SourceSize != want[i].SourceSize ||
+			got.HasPivot != want[i].HasPivot || !got.Pivot.ApproxEqual(want[i].Pivot) ||
+			!mgl32.FloatEqual(got.Duration, want[i].Duration) {
+			t.Errorf("frame %d = %+v, want %+v", i, got, want[i])
+		}
+	}
+
+	if names := sheet.GetClipNames(); !reflect.DeepEqual(names, []string{"float", "back", "bounce"}) {
+		t.Errorf("clip names %v", names)
+	}
+	clips := map[string][]int{
+		"":       {0, 1, 2},
+		"float":  {0, 1, 2},
+		"back":   {2, 1, 0},
+		"bounce": {0, 1, 2, 3, 2, 1},
+	}
+	for name, frames := range clips {
+		clip := sheet.GetClip(name)
+		if clip == nil || !reflect.DeepEqual(clip.Frames, frames) {
+			t.Errorf("clip %q = %+v, want frames %v", name, clip, frames)
+		}
+	}
+	if sheet.GetClip("missing") != nil {
+		t.Error("missing clip found")
+	}
+}
+
+func TestParseSpriteSheetTexturePacker(t *testing.T) {
+	sheet, err := ParseSpriteSheet([]byte(texturePackerSheet), "assets/sprite")
+	if err != nil {
+		t.Fatalf("parse error,%v", err)
+	}
+	if sheet.Image != "assets/img/hero.png" {
+		t.Errorf("image = %q", sheet.Image)
+	}
+	// 没有原始大小时使用帧大小
+	if got := sheet.Frames[2].SourceSize; got != (mgl32.Vec2{16.0, 20.0}) {
+		t.Errorf("source size = %v", got)
+	}
+	if frame := sheet.Frames[0]; !frame.HasPivot || frame.Pivot != (mgl32.Vec2{0.5, 1.0}) {
+		t.Errorf("pivot = %v %v", frame.HasPivot, frame.Pivot)
+	}
+	if sheet.Frames[1].HasPivot {
+		t.Error("frame without pivot has pivot")
+	}
+	// 动画名字按字母排序
+	if names := sheet.GetClipNames(); !reflect.DeepEqual(names, []string{"jump", "walk"}) {
+		t.Errorf("clip names %v", names)
+	}
+	if clip := sheet.GetClip("walk"); !reflect.DeepEqual(clip.Frames, []int{0, 1}) {
+		t.Errorf("walk frames %v", clip.Frames)
+	}
+	if clip := sheet.GetDefaultClip(); clip.Name != "jump" {
+		t.Errorf("default clip %q, want jump", clip.Name)
+	}
+}
+
+func TestParseSpriteSheetErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"invalid json", `{"frames": `},
+		{"no frames", `{"frames": {}}`},
+		{"rotated", `{"frames": [{"filename": "a", "frame": {"w": 1, "h": 1}, "rotated": true}]}`},
+		{"bad direction", `{"frames": [{"frame": {"w": 1, "h": 1}}], "meta": {"frameTags": [{"name": "a", "from": 0, "to": 0, "direction": "sideways"}]}}`},
+		{"bad range", `{"frames": [{"frame": {"w": 1, "h": 1}}], "meta": {"frameTags": [{"name": "a", "from": 0, "to": 1}]}}`},
+		{"unknown frame", `{"frames": [{"filename": "a", "frame": {"w": 1, "h": 1}}], "animations": {"walk": ["b"]}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseSpriteSheet([]byte(tt.data), ""); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestGetSpriteSheetPath(t *testing.T) {
+	if got := GetSpriteSheetPath("assets/sprite/ghost-Sheet.png"); got != "assets/sprite/ghost-Sheet.json" {
+		t.Errorf("sheet path = %q", got)
+	}
+}
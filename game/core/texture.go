@@ -10,22 +10,51 @@ type Texture struct {
 	Texture *sdl.Texture
 	// 纹理原始矩形区域，目标渲染矩形区域在ObjectAffiliate中已经有了
 	SrcRect sdl.FRect
+	// 图片在底层纹理中的区域，单独加载时是整张纹理，打包进图集时是图集中的位置
+	Region sdl.FRect
+	// 颜色调制，图集中的图片共用底层纹理，颜色在绘制时设置，不要直接修改底层纹理
+	Color sdl.FColor
 	// 角度
 	Angle float64
 	// 是否反转
 	IsFlip bool
 }
 
-// 创建纹理
+// 热重载后纹理大小变化时换成新纹理，源矩形按比例缩放，精灵表的帧数不变时仍然对齐
+func (t *Texture) resolve(store *AssetStore) {
+	texture := store.ResolveTexture(t.Texture)
+	if texture == t.Texture {
+		return
+	}
+	scaleX := float32(texture.W) / float32(t.Texture.W)
+	scaleY := float32(texture.H) / float32(t.Texture.H)
+	t.SrcRect = sdl.FRect{X: t.SrcRect.X * scaleX, Y: t.SrcRect.Y * scaleY, W: t.SrcRect.W * scaleX, H: t.SrcRect.H * scaleY}
+	t.Region = sdl.FRect{X: t.Region.X * scaleX, Y: t.Region.Y * scaleY, W: t.Region.W * scaleX, H: t.Region.H * scaleY}
+	t.Texture = texture
+}
+
+// 创建纹理，图片打包进图集时源矩形指向图集中的区域
 func CreateTexture(filePath string) *Texture {
-	texture, err := GetInstance().GetAssetStore().GetImage(filePath)
+	store := GetInstance().GetAssetStore()
+	if texture, region, ok := store.GetAtlasRegion(filePath); ok {
+		return &Texture{
+			Texture: texture,
+			SrcRect: region,
+			Region:  region,
+			Color:   sdl.FColor{R: 1.0, G: 1.0, B: 1.0, A: 1.0},
+		}
+	}
+	texture, err := store.GetImage(filePath)
 	if err != nil {
 		panic(err)
 	}
 	var w, h float32
 	sdl.GetTextureSize(texture, &w, &h)
+	rect := sdl.FRect{X: 0.0, Y: 0.0, W: w, H: h}
 	return &Texture{
 		Texture: texture,
-		SrcRect: sdl.FRect{X: 0.0, Y: 0.0, W: w, H: h},
+		SrcRect: rect,
+		Region:  rect,
+		Color:   sdl.FColor{R: 1.0, G: 1.0, B: 1.0, A: 1.0},
 	}
 }
@@ -0,0 +1,360 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/SunshineZzzz/purego-sdl3/img"
+	"github.com/SunshineZzzz/purego-sdl3/sdl"
+)
+
+const (
+	// 图集单页最大尺寸
+	AtlasPageSize = 2048
+	// 图片之间的间隔，避免线性过滤时采样到相邻图片
+	AtlasPadding = 2
+	// 每页左上角保留的白色块大小，纯色四边形采样白色块，和同一页的图片合批
+	AtlasWhiteSize = 4
+)
+
+// 图片在图集中的区域
+type AtlasRegion struct {
+	// 所在页
+	Page int `json:"page"`
+	// 位置和大小，单位像素
+	X int32 `json:"x"`
+	Y int32 `json:"y"`
+	W int32 `json:"w"`
+	H int32 `json:"h"`
+}
+
+// 转换为纹理源矩形
+func (r AtlasRegion) GetRect() sdl.FRect {
+	return sdl.FRect{X: float32(r.X), Y: float32(r.Y), W: float32(r.W), H: float32(r.H)}
+}
+
+// 图集布局，导出时写入json
+type atlasLayout struct {
+	// 图集名字
+	Name string `json:"name"`
+	// 每一页的大小
+	Pages [][2]int32 `json:"pages"`
+	// 每张图片的区域
+	Regions map[string]AtlasRegion `json:"regions"`
+	// 放不进图集的图片，仍然单独加载
+	Skipped []string `json:"skipped"`
+	// 每页的白色块
+	Whites []AtlasRegion `json:"whites"`
+}
+
+// 待打包的图片
+type atlasImage struct {
+	// 图片路径
+	path string
+	// 图片大小
+	w, h int32
+}
+
+// 纹理图集，把多张小图片打包到少数几张大纹理中，减少渲染时的纹理切换
+type TextureAtlas struct {
+	// 图集名字
+	name string
+	// 每一页的纹理
+	pages []*sdl.Texture
+	// 每张图片的区域
+	regions map[string]AtlasRegion
+	// 放不进图集的图片
+	skipped []string
+	// 每页的白色块
+	whites []AtlasRegion
+}
+
+// 打包图片并创建图集，dumpDir不为空时把图集和布局导出到该目录
+func BuildTextureAtlas(renderer *sdl.Renderer, fsys fs.FS, name string, paths []string, dumpDir string) (*TextureAtlas, error) {
+	surfaces := make(map[string]*sdl.Surface, len(paths))
+	defer func() {
+		for _, surface := range surfaces {
+			sdl.DestroySurface(surface)
+		}
+	}()
+	images := make([]atlasImage, 0, len(paths))
+	for _, path := range paths {
+		if _, ok := surfaces[path]; ok {
+			continue
+		}
+		surface, err := loadSurface(fsys, path)
+		if err != nil {
+			return nil, fmt.Errorf("build atlas error,%v", err)
+		}
+		surfaces[path] = surface
+		images = append(images, atlasImage{path: path, w: surface.W, h: surface.H})
+	}
+
+	regions, whites, pageSizes, skipped := packAtlas(images, AtlasPageSize, AtlasPadding)
+	atlas := &TextureAtlas{
+		name:    name,
+		pages:   make([]*sdl.Texture, 0, len(pageSizes)),
+		regions: regions,
+		skipped: skipped,
+		whites:  whites,
+	}
+	pageSurfaces := make([]*sdl.Surface, 0, len(pageSizes))
+	defer func() {
+		for _, surface := range pageSurfaces {
+			sdl.DestroySurface(surface)
+		}
+	}()
+	for _, size := range pageSizes {
+		page := sdl.CreateSurface(size[0], size[1], sdl.PixelFormatRGBA32)
+		if page == nil {
+			atlas.Clean()
+			return nil, fmt.Errorf("build atlas error,%s", sdl.GetError())
+		}
+		pageSurfaces = append(pageSurfaces, page)
+	}
+	for i, white := range whites {
+		// RGBA每个通道都是0xFF，和字节顺序无关
+		dst := sdl.Rect{X: white.X, Y: white.Y, W: white.W, H: white.H}
+		if !sdl.FillSurfaceRect(pageSurfaces[i], &dst, 0xFFFFFFFF) {
+			atlas.Clean()
+			return nil, fmt.Errorf("build atlas error,%s", sdl.GetError())
+		}
+	}
+	for path, region := range regions {
+		surface := surfaces[path]
+		// 直接拷贝像素，不做混合
+		sdl.SetSurfaceBlendMode(surface, sdl.BlendModeNone)
+		dst := sdl.Rect{X: region.X, Y: region.Y, W: region.W, H: region.H}
+		if !sdl.BlitSurface(surface, nil, pageSurfaces[region.Page], &dst) {
+			atlas.Clean()
+			return nil, fmt.Errorf("build atlas error,%s,%s", path, sdl.GetError())
+		}
+	}
+	for _, page := range pageSurfaces {
+		texture := sdl.CreateTextureFromSurface(renderer, page)
+		if texture == nil {
+			atlas.Clean()
+			return nil, fmt.Errorf("build atlas error,%s", sdl.GetError())
+		}
+		atlas.pages = append(atlas.pages, texture)
+	}
+	if dumpDir != "" {
+		if err := atlas.dump(dumpDir, pageSurfaces, pageSizes); err != nil {
+			fmt.Printf("dump atlas error,%v\n", err)
+		}
+	}
+	return atlas, nil
+}
+
+// 从文件系统加载图片到表面
+func loadSurface(fsys fs.FS, path string) (*sdl.Surface, error) {
+	data, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return nil, err
+	}
+	surface := loadFromMemory(data, func(ioStream *sdl.IOStream) *sdl.Surface {
+		return img.LoadIO(ioStream, true)
+	})
+	if surface == nil {
+		return nil, fmt.Errorf("load image error,%s,%s", path, sdl.GetError())
+	}
+	return surface, nil
+}
+
+// 按行打包，图片按高度从高到低排列，一行放满换行，一页放满换页，每页先放一个白色块
+// 返回每张图片的区域、每页的白色块、每页大小和放不下的图片
+func packAtlas(images []atlasImage, pageSize, padding int32) (map[string]AtlasRegion, []AtlasRegion, [][2]int32, []string) {
+	sorted := make([]atlasImage, len(images))
+	copy(sorted, images)
+	// 排序要稳定，保证每次打包结果一致
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].h != sorted[j].h {
+			return sorted[i].h > sorted[j].h
+		}
+		if sorted[i].w != sorted[j].w {
+			return sorted[i].w > sorted[j].w
+		}
+		return sorted[i].path < sorted[j].path
+	})
+
+	regions := make(map[string]AtlasRegion, len(sorted))
+	whites := make([]AtlasRegion, 0)
+	pageSizes := make([][2]int32, 0)
+	skipped := make([]string, 0)
+	page := -1
+	var x, y, rowHeight int32
+	// 放入一个区域，返回false表示当前页放不下
+	place := func(w, h int32) (AtlasRegion, bool) {
+		// 换行
+		if x+w+padding > pageSize {
+			x = padding
+			y += rowHeight
+			rowHeight = 0
+		}
+		if y+h+padding > pageSize {
+			return AtlasRegion{}, false
+		}
+		region := AtlasRegion{Page: page, X: x, Y: y, W: w, H: h}
+		// 每页只保留用到的大小
+		pageSizes[page][0] = max(pageSizes[page][0], x+w+padding)
+		pageSizes[page][1] = max(pageSizes[page][1], y+h+padding)
+		x += w + padding
+		rowHeight = max(rowHeight, h+padding)
+		return region, true
+	}
+	// 换页，新页先放白色块
+	newPage := func() {
+		page++
+		pageSizes = append(pageSizes, [2]int32{0, 0})
+		x, y, rowHeight = padding, padding, 0
+		white, _ := place(AtlasWhiteSize, AtlasWhiteSize)
+		whites = append(whites, white)
+	}
+	for _, image := range sorted {
+		if image.w+padding*2 > pageSize || image.h+padding*2 > pageSize {
+			skipped = append(skipped, image.path)
+			continue
+		}
+		// 新页上白色块的右边和下面都放不下
+		if AtlasWhiteSize+image.w+padding*3 > pageSize && AtlasWhiteSize+image.h+padding*3 > pageSize {
+			skipped = append(skipped, image.path)
+			continue
+		}
+		if page < 0 {
+			newPage()
+		}
+		region, ok := place(image.w, image.h)
+		if !ok {
+			newPage()
+			region, _ = place(image.w, image.h)
+		}
+		regions[image.path] = region
+	}
+	return regions, whites, pageSizes, skipped
+}
+
+// 导出图集每一页的图片和布局
+func (t *TextureAtlas) dump(dir string, pageSurfaces []*sdl.Surface, pageSizes [][2]int32) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for i, surface := range pageSurfaces {
+		file := filepath.Join(dir, t.name+"_"+strconv.Itoa(i)+".png")
+		if !img.SavePNG(surface, file) {
+			return fmt.Errorf("save atlas page error,%s,%s", file, sdl.GetError())
+		}
+	}
+	layout := atlasLayout{Name: t.name, Pages: pageSizes, Regions: t.regions, Skipped: t.skipped, Whites: t.whites}
+	data, err := json.MarshalIndent(layout, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, t.name+".json"), data, 0644)
+}
+
+// 清理，销毁所有页
+func (t *TextureAtlas) Clean() {
+	for _, page := range t.pages {
+		sdl.DestroyTexture(page)
+	}
+	t.pages = nil
+}
+
+// 获取图集名字
+func (t *TextureAtlas) GetName() string {
+	return t.name
+}
+
+// 获取页数
+func (t *TextureAtlas) GetPageCount() int {
+	return len(t.pages)
+}
+
+// 获取某一页的纹理
+func (t *TextureAtlas) GetPage(page int) *sdl.Texture {
+	if page < 0 || page >= len(t.pages) {
+		return nil
+	}
+	return t.pages[page]
+}
+
+// 获取图片所在的纹理和区域
+func (t *TextureAtlas) GetRegion(path string) (*sdl.Texture, AtlasRegion, bool) {
+	region, ok := t.regions[path]
+	if !ok {
+		return nil, AtlasRegion{}, false
+	}
+	return t.pages[region.Page], region, true
+}
+
+// 获取某一页白色块的归一化纹理坐标，向内收缩一个像素，线性过滤时不会采样到白色块外面
+func (t *TextureAtlas) GetWhiteUV(page int) (sdl.FRect, bool) {
+	if page < 0 || page >= len(t.pages) || page >= len(t.whites) {
+		return sdl.FRect{}, false
+	}
+	texture, white := t.pages[page], t.whites[page]
+	if texture.W <= 0 || texture.H <= 0 {
+		return sdl.FRect{}, false
+	}
+	return sdl.FRect{
+		X: float32(white.X+1) / float32(texture.W),
+		Y: float32(white.Y+1) / float32(texture.H),
+		W: float32(white.W-2) / float32(texture.W),
+		H: float32(white.H-2) / float32(texture.H),
+	}, true
+}
+
+// 获取打包进图集的所有图片
+func (t *TextureAtlas) GetImages() []string {
+	images := make([]string, 0, len(t.regions))
+	for path := range t.regions {
+		images = append(images, path)
+	}
+	sort.Strings(images)
+	return images
+}
+
+// 获取放不进图集的图片
+func (t *TextureAtlas) GetSkipped() []string {
+	return t.skipped
+}
+
+// 获取占用内存，按RGBA估算
+func (t *TextureAtlas) GetMemorySize() int {
+	size := 0
+	for _, page := range t.pages {
+		size += int(page.W) * int(page.H) * 4
+	}
+	return size
+}
+
+// 重新加载图集中的一张图片，只更新对应区域，大小变化时需要重新打包
+func (t *TextureAtlas) reloadImage(fsys fs.FS, path string) error {
+	page, region, ok := t.GetRegion(path)
+	if !ok {
+		return nil
+	}
+	surface, err := loadSurface(fsys, path)
+	if err != nil {
+		return fmt.Errorf("reload atlas image error,%v", err)
+	}
+	defer sdl.DestroySurface(surface)
+	if surface.W != region.W || surface.H != region.H {
+		return fmt.Errorf("reload atlas image error,%s size changed, restart to repack atlas %s", path, t.name)
+	}
+	converted := sdl.ConvertSurface(surface, page.Format)
+	if converted == nil {
+		return fmt.Errorf("reload atlas image error,%s,%s", path, sdl.GetError())
+	}
+	defer sdl.DestroySurface(converted)
+	rect := sdl.Rect{X: region.X, Y: region.Y, W: region.W, H: region.H}
+	if !sdl.UpdateTexture(page, &rect, converted.Pixels, converted.Pitch) {
+		return fmt.Errorf("reload atlas image error,%s,%s", path, sdl.GetError())
+	}
+	return nil
+}
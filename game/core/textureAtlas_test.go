@@ -0,0 +1,87 @@
+package core
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// 两个区域是否重叠，padding是两者之间至少要留的间隔
+func regionsOverlap(a, b AtlasRegion, padding int32) bool {
+	if a.Page != b.Page {
+		return false
+	}
+	return a.X < b.X+b.W+padding && b.X < a.X+a.W+padding && a.Y < b.Y+b.H+padding && b.Y < a.Y+a.H+padding
+}
+
+func TestPackAtlas(t *testing.T) {
+	const pageSize, padding = 64, 2
+	images := []atlasImage{
+		{path: "big", w: 40, h: 40},
+		{path: "wide", w: 56, h: 10},
+		{path: "small", w: 8, h: 8},
+		{path: "small2", w: 8, h: 8},
+		{path: "tall", w: 10, h: 56},
+		{path: "huge", w: 70, h: 10},
+		// 放在白色块右边和下面都放不下
+		{path: "almost", w: 60, h: 60},
+	}
+	regions, whites, pageSizes, skipped := packAtlas(images, pageSize, padding)
+
+	// 放不下的图片按打包顺序，高的在前
+	if want := []string{"almost", "huge"}; !reflect.DeepEqual(skipped, want) {
+		t.Errorf("skipped %v, want %v", skipped, want)
+	}
+	if len(regions) != len(images)-2 {
+		t.Errorf("packed %d images, want %d", len(regions), len(images)-2)
+	}
+	if len(whites) != len(pageSizes) {
+		t.Fatalf("%d white blocks for %d pages", len(whites), len(pageSizes))
+	}
+	for page, white := range whites {
+		if white.Page != page || white.W != AtlasWhiteSize || white.H != AtlasWhiteSize {
+			t.Errorf("page %d white block %+v", page, white)
+		}
+	}
+
+	all := make(map[string]AtlasRegion, len(regions)+len(whites))
+	for path, region := range regions {
+		all[path] = region
+	}
+	for page, white := range whites {
+		all["white"+strconv.Itoa(page)] = white
+	}
+	for name, region := range all {
+		size := pageSizes[region.Page]
+		if region.X < padding || region.Y < padding || region.X+region.W+padding > size[0] || region.Y+region.H+padding > size[1] {
+			t.Errorf("%s %+v outside page size %v", name, region, size)
+		}
+		if size[0] > pageSize || size[1] > pageSize {
+			t.Errorf("page %d size %v larger than %d", region.Page, size, pageSize)
+		}
+		for other, otherRegion := range all {
+			if name != other && regionsOverlap(region, otherRegion, padding) {
+				t.Errorf("%s %+v overlaps %s %+v", name, region, other, otherRegion)
+			}
+		}
+	}
+
+	// 打包结果和输入顺序无关
+	reversed := make([]atlasImage, len(images))
+	for i, image := range images {
+		reversed[len(images)-1-i] = image
+	}
+	again, _, _, _ := packAtlas(reversed, pageSize, padding)
+	for path, region := range regions {
+		if again[path] != region {
+			t.Errorf("%s packed at %+v, then %+v", path, region, again[path])
+		}
+	}
+}
+
+func TestPackAtlasEmpty(t *testing.T) {
+	regions, whites, pageSizes, skipped := packAtlas(nil, AtlasPageSize, AtlasPadding)
+	if len(regions) != 0 || len(whites) != 0 || len(pageSizes) != 0 || len(skipped) != 0 {
+		t.Errorf("empty input produced pages %v", pageSizes)
+	}
+}
@@ -0,0 +1,240 @@
+package core
+
+import (
+	"math"
+
+	"github.com/SunshineZzzz/purego-sdl3/sdl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// 转场圆形遮罩分段数
+const transitionIrisSegments = 64
+
+// 转场类型
+type TransitionType int
+
+const (
+	// 淡出到纯色，切换场景后淡入
+	TransitionTypeFade TransitionType = iota
+	// 交叉淡化，旧场景渲染到纹理后逐渐透明
+	TransitionTypeCrossfade
+	// 从左到右擦除，切换场景后继续向右擦出
+	TransitionTypeWipe
+	// 圆形遮罩向中心收缩，切换场景后再张开
+	TransitionTypeIris
+)
+
+// 场景转场，转场期间屏蔽输入，音乐音量跟随画面变化
+type Transition struct {
+	// 转场类型
+	transitionType TransitionType
+	// 总时长，单位秒
+	duration float32
+	// 缓动函数
+	ease EaseFunc
+	// 遮罩颜色
+	color sdl.FColor
+	// 圆形遮罩中心(屏幕坐标)，每次渲染时获取，可以跟随角色，为nil时使用屏幕中心
+	center func() mgl32.Vec2
+	// 已经播放的时间
+	timer float32
+	// 是否已经切换场景
+	isSwitched bool
+	// 转场结束时执行的场景操作
+	command sceneCommand
+	// 交叉淡化时旧场景的画面
+	snapshot *sdl.Texture
+	// 转场开始时的音乐音量，结束后恢复
+	musicVolume float32
+}
+
+// 创建转场，默认二次缓入缓出，黑色遮罩
+func CreateTransition(transitionType TransitionType, duration float32) *Transition {
+	return &Transition{
+		transitionType: transitionType,
+		duration:       max(duration, 0.0),
+		ease:           EaseInOutQuad,
+		color:          sdl.FColor{R: 0.0, G: 0.0, B: 0.0, A: 1.0},
+	}
+}
+
+// 开始转场
+func (t *Transition) start(g *Game, command sceneCommand) {
+	t.command = command
+	t.timer = 0.0
+	t.isSwitched = false
+	t.musicVolume = g.GetMusicVolume()
+	// 交叉淡化一开始就切换场景，先把旧场景画下来
+	if t.transitionType == TransitionTypeCrossfade {
+		t.snapshot = g.captureScenes()
+		t.switchScene(g)
+	}
+	t.apply(g)
+}
+
+// 更新，返回转场是否结束
+func (t *Transition) update(g *Game, dt float32) bool {
+	t.timer += dt
+	if !t.isSwitched && t.GetProcess() >= 0.5 {
+		t.switchScene(g)
+	}
+	t.apply(g)
+	if t.GetProcess() < 1.0 {
+		return false
+	}
+	t.finish(g)
+	return true
+}
+
+// 执行场景操作，之前排队的旧场景操作全部丢弃
+func (t *Transition) switchScene(g *Game) {
+	t.isSwitched = true
+	g.sceneCommands = nil
+	g.runSceneCommand(t.command)
+}
+
+// 按当前进度设置音乐音量
+func (t *Transition) apply(g *Game) {
+	if t.transitionType == TransitionTypeCrossfade {
+		// 新场景的音乐逐渐变大
+		g.SetMusicVolume(t.musicVolume * t.ease(t.GetProcess()))
+		return
+	}
+	g.SetMusicVolume(t.musicVolume * (1.0 - t.getCoverage()))
+}
+
+// 结束转场，恢复音量，释放纹理
+func (t *Transition) finish(g *Game) {
+	if !t.isSwitched {
+		t.switchScene(g)
+	}
+	g.SetMusicVolume(t.musicVolume)
+	t.clean()
+}
+
+// 释放纹理
+func (t *Transition) clean() {
+	if t.snapshot != nil {
+		sdl.DestroyTexture(t.snapshot)
+		t.snapshot = nil
+	}
+}
+
+// 获取遮挡程度，0为完全可见，1为完全遮挡，前半段遮上，后半段揭开
+func (t *Transition) getCoverage() float32 {
+	process := t.GetProcess()
+	if process < 0.5 {
+		return t.ease(process * 2.0)
+	}
+	return t.ease((1.0 - process) * 2.0)
+}
+
+// 渲染转场遮罩，在场景渲染之后调用
+func (t *Transition) render(g *Game) {
+	switch t.transitionType {
+	case TransitionTypeFade:
+		color := t.color
+		color.A *= t.getCoverage()
+		g.DrawFillRect(mgl32.Vec2{0.0, 0.0}, g.screenSize, color)
+	case TransitionTypeCrossfade:
+		if t.snapshot == nil {
+			return
+		}
+		// 快照直接绘制，先提交之前的命令
+		g.FlushRender()
+		sdl.SetTextureBlendMode(t.snapshot, sdl.BlendModeBlend)
+		sdl.SetTextureAlphaModFloat(t.snapshot, 1.0-t.ease(t.GetProcess()))
+		sdl.RenderTexture(g.sdlRenderer, t.snapshot, nil, nil)
+	case TransitionTypeWipe:
+		width := g.screenSize.X() * t.getCoverage()
+		if t.isSwitched {
+			// 揭开时遮罩从右侧退出
+			g.DrawFillRect(mgl32.Vec2{g.screenSize.X() - width, 0.0}, g.screenSize, t.color)
+			return
+		}
+		g.DrawFillRect(mgl32.Vec2{0.0, 0.0}, mgl32.Vec2{width, g.screenSize.Y()}, t.color)
+	case TransitionTypeIris:
+		t.renderIris(g)
+	}
+}
+
+// 渲染圆形遮罩，圆外是遮罩颜色
+func (t *Transition) renderIris(g *Game) {
+	center := g.screenSize.Mul(0.5)
+	if t.center != nil {
+		center = t.center()
+	}
+	// 外圈半径要覆盖到离中心最远的屏幕角
+	outer := float32(0.0)
+	for _, corner := range []mgl32.Vec2{{0.0, 0.0}, {g.screenSize.X(), 0.0}, {0.0, g.screenSize.Y()}, g.screenSize} {
+		outer = max(outer, corner.Sub(center).Len())
+	}
+	inner := outer * (1.0 - t.getCoverage())
+	vertices := make([]sdl.Vertex, 0, (transitionIrisSegments+1)*2)
+	indices := make([]int32, 0, transitionIrisSegments*6)
+	for i := 0; i <= transitionIrisSegments; i++ {
+		angle := float64(i) / transitionIrisSegments * 2.0 * math.Pi
+		dir := mgl32.Vec2{float32(math.Cos(angle)), float32(math.Sin(angle))}
+		in := center.Add(dir.Mul(inner))
+		out := center.Add(dir.Mul(outer))
+		vertices = append(vertices,
+			sdl.Vertex{Position: sdl.FPoint{X: in.X(), Y: in.Y()}, Color: t.color},
+			sdl.Vertex{Position: sdl.FPoint{X: out.X(), Y: out.Y()}, Color: t.color})
+		if i < transitionIrisSegments {
+			base := int32(i * 2)
+			indices = append(indices, base, base+1, base+2, base+1, base+3, base+2)
+		}
+	}
+	g.DrawGeometry(nil, vertices, indices)
+}
+
+// 获取进度，范围[0,1]
+func (t *Transition) GetProcess() float32 {
+	if t.duration <= 0.0 {
+		return 1.0
+	}
+	return min(t.timer/t.duration, 1.0)
+}
+
+// 获取转场类型
+func (t *Transition) GetType() TransitionType {
+	return t.transitionType
+}
+
+// 获取总时长
+func (t *Transition) GetDuration() float32 {
+	return t.duration
+}
+
+// 设置总时长
+func (t *Transition) SetDuration(duration float32) {
+	t.duration = max(duration, 0.0)
+}
+
+// 获取缓动函数
+func (t *Transition) GetEase() EaseFunc {
+	return t.ease
+}
+
+// 设置缓动函数
+func (t *Transition) SetEase(ease EaseFunc) {
+	if ease == nil {
+		ease = EaseLinear
+	}
+	t.ease = ease
+}
+
+// 获取遮罩颜色
+func (t *Transition) GetColor() sdl.FColor {
+	return t.color
+}
+
+// 设置遮罩颜色
+func (t *Transition) SetColor(color sdl.FColor) {
+	t.color = color
+}
+
+// 设置圆形遮罩中心，返回屏幕坐标
+func (t *Transition) SetCenter(center func() mgl32.Vec2) {
+	t.center = center
+}
@@ -0,0 +1,489 @@
+package core
+
+import (
+	"github.com/SunshineZzzz/purego-sdl3/sdl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// 补间动画抽象，作为子对象挂在拥有者上，拥有者移除时补间也随之停止
+type ITween interface {
+	// 继承基础对象接口
+	IObject
+	// 推进时间，返回是否播放完毕和播放完毕后没用完的时间，组合补间用它驱动内部的补间
+	Step(dt float32) (bool, float32)
+	// 从头播放
+	Restart()
+	// 获取是否播放完毕
+	GetFinish() bool
+}
+
+// 可缩放对象，缩放是相对当前大小的
+type IScalable interface {
+	// 继承基础对象接口
+	IObject
+	// 设置缩放比例
+	SetScale(float32)
+}
+
+// 补间动画，把一段时间内的进度经过缓动后交给apply
+type Tween struct {
+	// 继承基础对象
+	Object
+	// 一次播放的时长，单位秒
+	duration float32
+	// 开始前的延迟，只在第一次播放前生效
+	delay float32
+	// 缓动函数
+	ease EaseFunc
+	// 播放次数，小于等于0表示无限循环
+	loops int
+	// 是否来回播放，反向也算一次播放
+	yoyo bool
+	// 开始播放时调用一次，用于记录起始值
+	begin func()
+	// 根据缓动后的进度更新属性
+	apply func(t float32)
+	// 播放完毕回调
+	onComplete func()
+	// 已经播放的时间，包括延迟
+	elapsed float32
+	// 已经播放的次数
+	played int
+	// 是否已经记录起始值，重新播放时使用第一次的起始值
+	begun bool
+	// 是否反向播放中
+	reversed bool
+	// 是否播放完毕
+	finished bool
+}
+
+var _ IObject = (*Tween)(nil)
+var _ ITween = (*Tween)(nil)
+
+// 添加补间动画到拥有者中，apply接收缓动后的进度，parent为nil时用于组合补间
+func AddTweenChild(parent IObject, duration float32, apply func(t float32)) *Tween {
+	t := &Tween{}
+	t.Init()
+	t.duration = max(duration, 0.0)
+	t.apply = apply
+	if parent != nil {
+		parent.AddChild(t)
+	}
+	return t
+}
+
+// 添加浮点数补间，可以用于任意float32字段
+func AddTweenFloatChild(parent IObject, target *float32, to float32, duration float32) *Tween {
+	var from float32
+	t := AddTweenChild(parent, duration, func(t float32) {
+		*target = from + (to-from)*t
+	})
+	t.begin = func() {
+		from = *target
+	}
+	return t
+}
+
+// 添加二维向量补间
+func AddTweenVec2Child(parent IObject, target *mgl32.Vec2, to mgl32.Vec2, duration float32) *Tween {
+	var from mgl32.Vec2
+	t := AddTweenChild(parent, duration, func(t float32) {
+		*target = from.Add(to.Sub(from).Mul(t))
+	})
+	t.begin = func() {
+		from = *target
+	}
+	return t
+}
+
+// 添加颜色补间，比如纹理的Color
+func AddTweenColorChild(parent IObject, target *sdl.FColor, to sdl.FColor, duration float32) *Tween {
+	var from sdl.FColor
+	t := AddTweenChild(parent, duration, func(t float32) {
+		target.R = from.R + (to.R-from.R)*t
+		target.G = from.G + (to.G-from.G)*t
+		target.B = from.B + (to.B-from.B)*t
+		target.A = from.A + (to.A-from.A)*t
+	})
+	t.begin = func() {
+		from = *target
+	}
+	return t
+}
+
+// 添加透明度补间
+func AddTweenAlphaChild(parent IObject, target *sdl.FColor, to float32, duration float32) *Tween {
+	return AddTweenFloatChild(parent, &target.A, to, duration)
+}
+
+// 添加世界位置补间，直接修改Position，保留渲染插值
+func AddTweenPositionChild(object *ObjectWorld, to mgl32.Vec2, duration float32) *Tween {
+	return AddTweenVec2Child(object, &object.Position, to, duration)
+}
+
+// 添加渲染(屏幕)位置补间
+func AddTweenRenderPositionChild(object IObjectScreen, to mgl32.Vec2, duration float32) *Tween {
+	var from mgl32.Vec2
+	t := AddTweenChild(object, duration, func(t float32) {
+		object.SetRenderPosition(from.Add(to.Sub(from).Mul(t)))
+	})
+	t.begin = func() {
+		from = object.GetRenderPosition()
+	}
+	return t
+}
+
+// 添加缩放补间，from和to是相对开始时大小的比例，必须大于0
+func AddTweenScaleChild(object IScalable, from, to float32, duration float32) *Tween {
+	from = max(from, 0.001)
+	to = max(to, 0.001)
+	// 当前相对开始时大小的比例，SetScale是相对当前大小的，每次只缩放变化的部分
+	current := float32(1.0)
+	t := AddTweenChild(object, duration, func(t float32) {
+		scale := from + (to-from)*t
+		object.SetScale(scale / current)
+		current = scale
+	})
+	return t
+}
+
+// 初始化
+func (t *Tween) Init() {
+	t.Object.Init()
+	t.ease = EaseLinear
+	t.loops = 1
+}
+
+// 更新，播放完毕后从拥有者中移除
+func (t *Tween) Update(dt float32) {
+	if finished, _ := t.Step(dt); finished {
+		t.SetNeedRemove(true)
+	}
+}
+
+// 推进时间，返回是否播放完毕和播放完毕后没用完的时间
+func (t *Tween) Step(dt float32) (bool, float32) {
+	if t.finished {
+		return true, dt
+	}
+	t.elapsed += dt
+	if t.elapsed < t.delay {
+		return false, 0.0
+	}
+	if !t.begun {
+		t.begun = true
+		if t.begin != nil {
+			t.begin()
+		}
+	}
+	local := t.elapsed - t.delay
+	progress := float32(1.0)
+	if t.duration > 0.0 {
+		progress = min(local/t.duration, 1.0)
+	}
+	if t.reversed {
+		progress = 1.0 - progress
+	}
+	if t.apply != nil {
+		t.apply(t.ease(progress))
+	}
+	if local < t.duration {
+		return false, 0.0
+	}
+	// 一次播放结束
+	t.played++
+	if t.loops > 0 && t.played >= t.loops {
+		t.finished = true
+		if t.onComplete != nil {
+			t.onComplete()
+		}
+		return true, local - t.duration
+	}
+	// 多出来的时间留给下一次播放
+	t.elapsed = t.delay + local - t.duration
+	if t.yoyo {
+		t.reversed = !t.reversed
+	}
+	return false, 0.0
+}
+
+// 从头播放，起始值不变，已经开始播放过时跳过延迟
+func (t *Tween) Restart() {
+	t.elapsed = 0.0
+	if t.begun {
+		t.elapsed = t.delay
+	}
+	t.played = 0
+	t.reversed = false
+	t.finished = false
+}
+
+// 获取是否播放完毕
+func (t *Tween) GetFinish() bool {
+	return t.finished
+}
+
+// 非接口实现
+
+// 停止播放并从拥有者中移除，不会回调播放完毕
+func (t *Tween) Kill() {
+	t.finished = true
+	t.SetNeedRemove(true)
+}
+
+// 获取时长
+func (t *Tween) GetDuration() float32 {
+	return t.duration
+}
+
+// 设置时长
+func (t *Tween) SetDuration(duration float32) {
+	t.duration = max(duration, 0.0)
+}
+
+// 获取延迟
+func (t *Tween) GetDelay() float32 {
+	return t.delay
+}
+
+// 设置延迟
+func (t *Tween) SetDelay(delay float32) {
+	t.delay = max(delay, 0.0)
+}
+
+// 获取缓动函数
+func (t *Tween) GetEase() EaseFunc {
+	return t.ease
+}
+
+// 设置缓动函数，为nil时使用线性
+func (t *Tween) SetEase(ease EaseFunc) {
+	if ease == nil {
+		ease = EaseLinear
+	}
+	t.ease = ease
+}
+
+// 获取播放次数
+func (t *Tween) GetLoops() int {
+	return t.loops
+}
+
+// 设置播放次数，小于等于0表示无限循环
+func (t *Tween) SetLoops(loops int) {
+	t.loops = loops
+}
+
+// 获取是否来回播放
+func (t *Tween) GetYoyo() bool {
+	return t.yoyo
+}
+
+// 设置是否来回播放
+func (t *Tween) SetYoyo(yoyo bool) {
+	t.yoyo = yoyo
+}
+
+// 设置播放完毕回调
+func (t *Tween) SetOnComplete(onComplete func()) {
+	t.onComplete = onComplete
+}
+
+// 组合补间类型
+type TweenGroupType int
+
+const (
+	// 依次播放
+	TweenGroupTypeSequence TweenGroupType = iota
+	// 同时播放
+	TweenGroupTypeParallel
+)
+
+// 组合补间，内部的补间由组合驱动，不需要拥有者
+type TweenGroup struct {
+	// 继承基础对象
+	Object
+	// 组合类型
+	groupType TweenGroupType
+	// 内部的补间
+	tweens []ITween
+	// 开始前的延迟，只在第一次播放前生效
+	delay float32
+	// 播放次数，小于等于0表示无限循环
+	loops int
+	// 播放完毕回调
+	onComplete func()
+	// 已经延迟的时间
+	delayTimer float32
+	// 上一次播放多出来的时间，下一次更新时生效
+	leftover float32
+	// 依次播放时当前的补间
+	current int
+	// 已经播放的次数
+	played int
+	// 是否播放完毕
+	finished bool
+}
+
+var _ IObject = (*TweenGroup)(nil)
+var _ ITween = (*TweenGroup)(nil)
+
+// 添加依次播放的组合补间，补间创建时parent传nil
+func AddTweenSequenceChild(parent IObject, tweens ...ITween) *TweenGroup {
+	return addTweenGroupChild(parent, TweenGroupTypeSequence, tweens)
+}
+
+// 添加同时播放的组合补间，补间创建时parent传nil
+func AddTweenParallelChild(parent IObject, tweens ...ITween) *TweenGroup {
+	return addTweenGroupChild(parent, TweenGroupTypeParallel, tweens)
+}
+
+// 添加组合补间
+func addTweenGroupChild(parent IObject, groupType TweenGroupType, tweens []ITween) *TweenGroup {
+	g := &TweenGroup{}
+	g.Init()
+	g.groupType = groupType
+	g.tweens = tweens
+	if parent != nil {
+		parent.AddChild(g)
+	}
+	return g
+}
+
+// 初始化
+func (g *TweenGroup) Init() {
+	g.Object.Init()
+	g.loops = 1
+}
+
+// 更新，播放完毕后从拥有者中移除
+func (g *TweenGroup) Update(dt float32) {
+	if finished, _ := g.Step(dt); finished {
+		g.SetNeedRemove(true)
+	}
+}
+
+// 推进时间，返回是否播放完毕和播放完毕后没用完的时间
+func (g *TweenGroup) Step(dt float32) (bool, float32) {
+	if g.finished {
+		return true, dt
+	}
+	if g.delayTimer < g.delay {
+		g.delayTimer += dt
+		if g.delayTimer < g.delay {
+			return false, 0.0
+		}
+		// 延迟结束后多出来的时间交给内部的补间
+		dt = g.delayTimer - g.delay
+	}
+	dt += g.leftover
+	g.leftover = 0.0
+	done, leftover := g.stepTweens(dt)
+	if !done {
+		return false, 0.0
+	}
+	// 一次播放结束
+	g.played++
+	if g.loops > 0 && g.played >= g.loops {
+		g.finished = true
+		if g.onComplete != nil {
+			g.onComplete()
+		}
+		return true, leftover
+	}
+	// 多出来的时间留给下一次播放
+	g.leftover = leftover
+	g.restartTweens()
+	return false, 0.0
+}
+
+// 从头播放，已经延迟过时跳过延迟
+func (g *TweenGroup) Restart() {
+	if g.delayTimer < g.delay {
+		g.delayTimer = 0.0
+	}
+	g.leftover = 0.0
+	g.played = 0
+	g.finished = false
+	g.restartTweens()
+}
+
+// 获取是否播放完毕
+func (g *TweenGroup) GetFinish() bool {
+	return g.finished
+}
+
+// 非接口实现
+
+// 推进内部的补间，返回是否全部播放完毕和没用完的时间
+func (g *TweenGroup) stepTweens(dt float32) (bool, float32) {
+	if g.groupType == TweenGroupTypeSequence {
+		for g.current < len(g.tweens) {
+			done, leftover := g.tweens[g.current].Step(dt)
+			if !done {
+				return false, 0.0
+			}
+			// 没用完的时间交给下一个补间
+			g.current++
+			dt = leftover
+		}
+		return true, dt
+	}
+	// 同时播放时以最后结束的补间为准
+	done, unused := true, dt
+	for _, tween := range g.tweens {
+		finished, leftover := tween.Step(dt)
+		if !finished {
+			done = false
+		}
+		unused = min(unused, leftover)
+	}
+	if !done {
+		return false, 0.0
+	}
+	return true, unused
+}
+
+// 重新播放内部的补间
+func (g *TweenGroup) restartTweens() {
+	g.current = 0
+	for _, tween := range g.tweens {
+		tween.Restart()
+	}
+}
+
+// 停止播放并从拥有者中移除，不会回调播放完毕
+func (g *TweenGroup) Kill() {
+	g.finished = true
+	g.SetNeedRemove(true)
+}
+
+// 获取组合类型
+func (g *TweenGroup) GetGroupType() TweenGroupType {
+	return g.groupType
+}
+
+// 获取延迟
+func (g *TweenGroup) GetDelay() float32 {
+	return g.delay
+}
+
+// 设置延迟
+func (g *TweenGroup) SetDelay(delay float32) {
+	g.delay = max(delay, 0.0)
+}
+
+// 获取播放次数
+func (g *TweenGroup) GetLoops() int {
+	return g.loops
+}
+
+// 设置播放次数，小于等于0表示无限循环
+func (g *TweenGroup) SetLoops(loops int) {
+	g.loops = loops
+}
+
+// 设置播放完毕回调
+func (g *TweenGroup) SetOnComplete(onComplete func()) {
+	g.onComplete = onComplete
+}
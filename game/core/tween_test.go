@@ -0,0 +1,248 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// 按固定步长推进补间，返回每一步之后的值
+func stepTween(tween ITween, value *float32, steps int, dt float32) []float32 {
+	values := make([]float32, 0, steps)
+	for range steps {
+		tween.Step(dt)
+		values = append(values, *value)
+	}
+	return values
+}
+
+// 比较两组值
+func floatsEqual(a, b []float32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !mgl32.FloatEqualThreshold(a[i], b[i], 1e-4) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestTweenPlay(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(tween *Tween)
+		steps int
+		want  []float32
+	}{
+		{"linear", func(*Tween) {}, 5, []float32{2.5, 5.0, 7.5, 10.0, 10.0}},
+		{"ease", func(tween *Tween) { tween.SetEase(EaseInQuad) }, 4, []float32{0.625, 2.5, 5.625, 10.0}},
+		{"delay", func(tween *Tween) { tween.SetDelay(0.5) }, 6, []float32{0.0, 0.0, 2.5, 5.0, 7.5, 10.0}},
+		// 每次循环从起始值重新开始
+		{"loops", func(tween *Tween) { tween.SetLoops(2) }, 9, []float32{2.5, 5.0, 7.5, 10.0, 2.5, 5.0, 7.5, 10.0, 10.0}},
+		// 反向也算一次播放
+		{"yoyo", func(tween *Tween) { tween.SetLoops(2); tween.SetYoyo(true) }, 8, []float32{2.5, 5.0, 7.5, 10.0, 7.5, 5.0, 2.5, 0.0}},
+		// 延迟只在第一次播放前生效
+		{"delay loops", func(tween *Tween) { tween.SetDelay(0.5); tween.SetLoops(2) }, 10, []float32{0.0, 0.0, 2.5, 5.0, 7.5, 10.0, 2.5, 5.0, 7.5, 10.0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value := float32(0.0)
+			tween := AddTweenFloatChild(nil, &value, 10.0, 1.0)
+			tt.setup(tween)
+			if got := stepTween(tween, &value, tt.steps, 0.25); !floatsEqual(got, tt.want) {
+				t.Errorf("values %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTweenLeftoverTime(t *testing.T) {
+	value := float32(0.0)
+	tween := AddTweenFloatChild(nil, &value, 10.0, 1.0)
+	tween.SetLoops(0)
+	// 多出来的时间留给下一次播放，下一次更新时生效
+	tween.Step(1.5)
+	if !mgl32.FloatEqual(value, 10.0) {
+		t.Errorf("value after 1.5s = %v, want 10", value)
+	}
+	tween.Step(0.0)
+	if !mgl32.FloatEqual(value, 5.0) {
+		t.Errorf("value after leftover = %v, want 5", value)
+	}
+	if tween.GetFinish() {
+		t.Error("infinite tween finished")
+	}
+}
+
+func TestTweenComplete(t *testing.T) {
+	value := float32(0.0)
+	completed := 0
+	tween := AddTweenFloatChild(nil, &value, 10.0, 0.5)
+	tween.SetOnComplete(func() { completed++ })
+	if finished, _ := tween.Step(0.25); finished {
+		t.Error("finished too early")
+	}
+	// 结束时返回没用完的时间
+	if finished, leftover := tween.Step(0.3); !finished || !tween.GetFinish() || !mgl32.FloatEqualThreshold(leftover, 0.05, 1e-4) {
+		t.Errorf("finished %v, leftover %v at the end", finished, leftover)
+	}
+	tween.Step(0.25)
+	if completed != 1 {
+		t.Errorf("completed %d times, want 1", completed)
+	}
+
+	// 被移除时不回调
+	killed := AddTweenFloatChild(nil, &value, 0.0, 0.5)
+	killed.SetOnComplete(func() { completed++ })
+	killed.Kill()
+	if finished, _ := killed.Step(0.25); !finished || completed != 1 || !killed.GetNeedRemove() {
+		t.Error("killed tween still running")
+	}
+}
+
+func TestTweenRestart(t *testing.T) {
+	value := float32(0.0)
+	tween := AddTweenFloatChild(nil, &value, 10.0, 1.0)
+	tween.SetDelay(0.5)
+	stepTween(tween, &value, 6, 0.25)
+	if !tween.GetFinish() {
+		t.Fatal("not finished")
+	}
+	// 从头播放不再延迟，起始值不变
+	value = 100.0
+	tween.Restart()
+	if got := stepTween(tween, &value, 4, 0.25); !floatsEqual(got, []float32{2.5, 5.0, 7.5, 10.0}) {
+		t.Errorf("values after restart %v", got)
+	}
+
+	// 还没开始播放时重新开始，延迟仍然有效
+	value = 0.0
+	waiting := AddTweenFloatChild(nil, &value, 10.0, 1.0)
+	waiting.SetDelay(0.5)
+	waiting.Step(0.25)
+	waiting.Restart()
+	if got := stepTween(waiting, &value, 3, 0.25); !floatsEqual(got, []float32{0.0, 0.0, 2.5}) {
+		t.Errorf("values after restart during delay %v", got)
+	}
+}
+
+func TestTweenScale(t *testing.T) {
+	object := &testScalable{scale: 1.0}
+	object.Init()
+	tween := AddTweenScaleChild(object, 0.5, 1.0, 1.0)
+	tween.Step(0.0)
+	if !mgl32.FloatEqual(object.scale, 0.5) {
+		t.Errorf("scale at start = %v, want 0.5", object.scale)
+	}
+	tween.Step(1.0)
+	if !mgl32.FloatEqual(object.scale, 1.0) {
+		t.Errorf("scale at end = %v, want 1", object.scale)
+	}
+}
+
+// 记录累计缩放的对象
+type testScalable struct {
+	Object
+	// 累计缩放
+	scale float32
+}
+
+var _ IScalable = (*testScalable)(nil)
+
+// 设置缩放比例，相对当前大小
+func (s *testScalable) SetScale(scale float32) {
+	s.scale *= scale
+}
+
+func TestTweenSequence(t *testing.T) {
+	var a, b float32
+	first := AddTweenFloatChild(nil, &a, 1.0, 0.5)
+	second := AddTweenFloatChild(nil, &b, 1.0, 0.5)
+	second.SetDelay(0.25)
+	group := AddTweenSequenceChild(nil, first, second)
+	group.SetLoops(2)
+	completed := false
+	group.SetOnComplete(func() { completed = true })
+
+	// 每一步记录两个值
+	var got []float32
+	for range 10 {
+		group.Step(0.25)
+		got = append(got, a, b)
+	}
+	// 第二个补间在第一个结束的同一次更新开始，延迟只在第一次播放前生效
+	want := []float32{
+		0.5, 0.0, 1.0, 0.0,
+		1.0, 0.0, 1.0, 0.5, 1.0, 1.0,
+		0.5, 1.0, 1.0, 0.0,
+		1.0, 0.5, 1.0, 1.0,
+		1.0, 1.0,
+	}
+	if !floatsEqual(got, want) {
+		t.Errorf("values %v, want %v", got, want)
+	}
+	if !completed || !group.GetFinish() {
+		t.Error("sequence not finished")
+	}
+}
+
+func TestTweenSequenceLeftoverTime(t *testing.T) {
+	var a, b float32
+	first := AddTweenFloatChild(nil, &a, 1.0, 0.5)
+	second := AddTweenFloatChild(nil, &b, 1.0, 0.5)
+	group := AddTweenSequenceChild(nil, first, second)
+	// 第一个补间没用完的时间交给第二个补间
+	group.Step(0.4)
+	group.Step(0.4)
+	if !mgl32.FloatEqual(a, 1.0) || !mgl32.FloatEqualThreshold(b, 0.6, 1e-4) {
+		t.Errorf("a=%v b=%v, want 1 0.6", a, b)
+	}
+	// 总时长1秒，三次更新播放完毕
+	if finished, leftover := group.Step(0.4); !finished || !mgl32.FloatEqualThreshold(leftover, 0.2, 1e-4) {
+		t.Errorf("finished %v, leftover %v", finished, leftover)
+	}
+}
+
+func TestTweenGroupLoopLeftoverTime(t *testing.T) {
+	var value float32
+	group := AddTweenSequenceChild(nil, AddTweenFloatChild(nil, &value, 1.0, 0.5))
+	group.SetLoops(3)
+	// 每次播放多出来的时间留给下一次播放，总时长1.5秒，四次更新播放完毕
+	for i := range 4 {
+		finished, _ := group.Step(0.4)
+		if finished != (i == 3) {
+			t.Errorf("step %d: finished %v", i, finished)
+		}
+	}
+}
+
+func TestTweenParallel(t *testing.T) {
+	var a, b float32
+	short := AddTweenFloatChild(nil, &a, 1.0, 0.25)
+	long := AddTweenFloatChild(nil, &b, 1.0, 0.5)
+	group := AddTweenParallelChild(nil, short, long)
+	group.SetDelay(0.25)
+	steps := []struct {
+		a, b     float32
+		finished bool
+	}{
+		{0.0, 0.0, false},
+		{1.0, 0.5, false},
+		{1.0, 1.0, true},
+	}
+	for i, step := range steps {
+		finished, _ := group.Step(0.25)
+		if !mgl32.FloatEqual(a, step.a) || !mgl32.FloatEqual(b, step.b) || finished != step.finished {
+			t.Errorf("step %d: a=%v b=%v finished=%v, want %+v", i, a, b, finished, step)
+		}
+	}
+
+	// 从头播放跳过延迟
+	group.Restart()
+	group.Step(0.25)
+	if !mgl32.FloatEqual(a, 1.0) || !mgl32.FloatEqual(b, 0.5) {
+		t.Errorf("after restart a=%v b=%v, want 1 0.5", a, b)
+	}
+}
@@ -2,7 +2,10 @@ package core
 
 import (
 	"encoding/binary"
+	"runtime"
 	"unsafe"
+
+	"github.com/SunshineZzzz/purego-sdl3/sdl"
 )
 
 // float32切片转换为字节切片
@@ -13,3 +16,11 @@ func Float32ToBytes(data []float32) []byte {
 	}
 	return bytes
 }
+
+// 从内存中的文件数据加载，load收到直接引用data的IO流，需要负责关闭IO流
+func loadFromMemory[T any](data []byte, load func(ioStream *sdl.IOStream) T) T {
+	result := load(sdl.IOFromConstMem(data))
+	// IO流直接引用data，加载完成前不能被回收
+	runtime.KeepAlive(data)
+	return result
+}
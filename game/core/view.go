@@ -0,0 +1,62 @@
+package core
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// 视图变换，绕屏幕上的中心点缩放和旋转，把相机空间(世界坐标-相机位置)变换到屏幕
+type ViewTransform struct {
+	// 变换中心(屏幕坐标系)，一般是屏幕中心
+	Center mgl32.Vec2
+	// 缩放，大于1放大
+	Zoom float32
+	// 旋转，单位度，顺时针
+	Rotation float32
+}
+
+// 创建不做变换的视图变换
+func IdentityView(center mgl32.Vec2) ViewTransform {
+	return ViewTransform{Center: center, Zoom: 1.0}
+}
+
+// 是否不做变换
+func (v ViewTransform) IsIdentity() bool {
+	return v.Zoom == 1.0 && v.Rotation == 0.0
+}
+
+// 变换点，相机空间到屏幕
+func (v ViewTransform) Apply(p mgl32.Vec2) mgl32.Vec2 {
+	d := p.Sub(v.Center)
+	if v.Rotation != 0.0 {
+		d = rotateVec2(d, v.Rotation)
+	}
+	return d.Mul(v.Zoom).Add(v.Center)
+}
+
+// 逆变换点，屏幕到相机空间
+func (v ViewTransform) Inverse(p mgl32.Vec2) mgl32.Vec2 {
+	d := p.Sub(v.Center)
+	if v.Zoom != 0.0 {
+		d = d.Mul(1.0 / v.Zoom)
+	}
+	if v.Rotation != 0.0 {
+		d = rotateVec2(d, -v.Rotation)
+	}
+	return d.Add(v.Center)
+}
+
+// 变换矩形，中心点变换，大小按缩放，旋转不改变矩形的方向
+func (v ViewTransform) ApplyRect(pos, size mgl32.Vec2) (mgl32.Vec2, mgl32.Vec2) {
+	center := v.Apply(pos.Add(size.Mul(0.5)))
+	size = size.Mul(v.Zoom)
+	return center.Sub(size.Mul(0.5)), size
+}
+
+// 旋转向量，单位度
+func rotateVec2(v mgl32.Vec2, degrees float32) mgl32.Vec2 {
+	rad := float64(mgl32.DegToRad(degrees))
+	sin, cos := float32(math.Sin(rad)), float32(math.Cos(rad))
+	return mgl32.Vec2{v.X()*cos - v.Y()*sin, v.X()*sin + v.Y()*cos}
+}
@@ -3,20 +3,22 @@ package game
 import (
 	"ghost_escape/game/affiliate"
 	"ghost_escape/game/core"
+	"ghost_escape/game/world"
 
+	"github.com/SunshineZzzz/purego-sdl3/sdl"
 	"github.com/go-gl/mathgl/mgl32"
 )
 
 // 敌人状态
-type EnemyState int
+type EnemyState string
 
 const (
 	// 正常状态
-	EnemyStateNormal EnemyState = iota
+	EnemyStateNormal EnemyState = "normal"
 	// 受伤状态
-	EnemyStateHurt
+	EnemyStateHurt EnemyState = "hurt"
 	// 死亡状态
-	EnemyStateDead
+	EnemyStateDead EnemyState = "dead"
 )
 
 // 敌人
@@ -25,22 +27,34 @@ type Enemy struct {
 	core.Actor
 	// 目标玩家
 	target *Player
-	// 敌人状态
-	currentState EnemyState
-	// 正常状态精灵动画
-	spriteAnimNormal *affiliate.SpriteAnim
-	// 受伤状态精灵动画
-	spriteAnimHurt *affiliate.SpriteAnim
-	// 死亡状态精灵动画
-	spriteAnimDead *affiliate.SpriteAnim
-	// 当前精灵动画
-	currentSpriteAnim *affiliate.SpriteAnim
+	// 动画状态机
+	animator *affiliate.Animator
 	// 分数
 	score int
 }
 
+// 幽灵死亡时飘散的灵魂
+var ghostWispParticles = affiliate.ParticleConfig{
+	Burst:       24,
+	LifetimeMin: 0.6,
+	LifetimeMax: 1.2,
+	SpeedMin:    20.0,
+	SpeedMax:    80.0,
+	Direction:   -90.0,
+	Spread:      120.0,
+	Radius:      16.0,
+	Gravity:     mgl32.Vec2{0.0, -60.0},
+	Damping:     0.5,
+	StartColor:  sdl.FColor{R: 0.7, G: 0.9, B: 1.0, A: 0.8},
+	EndColor:    sdl.FColor{R: 0.4, G: 0.5, B: 1.0, A: 0.0},
+	StartSize:   6.0,
+	EndSize:     2.0,
+}
+
 var _ core.IObject = (*Enemy)(nil)
 var _ core.IObjectScreen = (*Enemy)(nil)
+var _ core.ICollisionEnter = (*Enemy)(nil)
+var _ core.ICollisionStay = (*Enemy)(nil)
 
 // 创建敌人
 func CreateEnemy(parent core.IObject, pos mgl32.Vec2, target *Player) *Enemy {
@@ -58,18 +72,26 @@ func CreateEnemy(parent core.IObject, pos mgl32.Vec2, target *Player) *Enemy {
 func (e *Enemy) Init() {
 	e.Actor.Init()
 	e.score = 10
-	e.currentState = EnemyStateNormal
-	e.spriteAnimNormal = affiliate.AddSpriteAnimChild(e, "assets/sprite/ghost-Sheet.png", 2.0, core.AnchorTypeCenter)
-	e.spriteAnimHurt = affiliate.AddSpriteAnimChild(e, "assets/sprite/ghostHurt-Sheet.png", 2.0, core.AnchorTypeCenter)
-	e.spriteAnimDead = affiliate.AddSpriteAnimChild(e, "assets/sprite/ghostDead-Sheet.png", 2.0, core.AnchorTypeCenter)
-	e.spriteAnimNormal.SetActive(true)
-	e.spriteAnimHurt.SetActive(false)
-	e.spriteAnimDead.SetActive(false)
-	e.spriteAnimDead.SetLoop(false)
-	e.currentSpriteAnim = e.spriteAnimNormal
-	e.Collider = affiliate.AddColliderChild(e, e.currentSpriteAnim.GetSize(), core.ColliderTypeCircle, core.AnchorTypeCenter)
+	spriteAnimNormal := affiliate.AddSpriteAnimChild(e, "assets/sprite/ghost-Sheet.png", 2.0, core.AnchorTypeCenter)
+	spriteAnimHurt := affiliate.AddSpriteAnimChild(e, "assets/sprite/ghostHurt-Sheet.png", 2.0, core.AnchorTypeCenter)
+	spriteAnimDead := affiliate.AddSpriteAnimChild(e, "assets/sprite/ghostDead-Sheet.png", 2.0, core.AnchorTypeCenter)
+	e.animator = affiliate.AddAnimatorChild(&e.Actor)
+	e.animator.AddState(affiliate.AnimState{Name: string(EnemyStateNormal), Anim: spriteAnimNormal, Loop: true})
+	e.animator.AddState(affiliate.AnimState{Name: string(EnemyStateHurt), Anim: spriteAnimHurt, Loop: true})
+	e.animator.AddState(affiliate.AnimState{Name: string(EnemyStateDead), Anim: spriteAnimDead, Loop: false, OnEnter: func() {
+		e.Game().AddScore(e.score)
+		e.Game().GetCurrentScene().SafeAddChild(world.AddParticleEffectChild(nil, ghostWispParticles, e.GetPosition()))
+	}})
+	// 死亡优先，其次受伤，死亡后不会再离开死亡状态
+	e.animator.AddTransition("", string(EnemyStateDead), affiliate.AnimCondition{Type: affiliate.AnimConditionHealthBelow, Value: 0.1})
+	e.animator.AddTransition("", string(EnemyStateHurt), affiliate.AnimCondition{Type: affiliate.AnimConditionInvincible})
+	e.animator.AddTransition("", string(EnemyStateNormal))
+	e.Collider = affiliate.AddColliderChild(e, spriteAnimNormal.GetSize(), core.ColliderTypeCircle, core.AnchorTypeCenter)
+	// 敌人碰到玩家时攻击
+	e.Collider.SetCollisionLayer(core.CollisionLayerEnemy)
+	e.Collider.SetCollisionMask(core.CollisionLayerPlayer)
 	e.Stats = core.AddStatusChild(&e.Actor, 100.0, 100.0, 40.0, 10.0)
-	size := e.spriteAnimNormal.GetSize()
+	size := spriteAnimNormal.GetSize()
 	e.HealthBar = affiliate.AddAffiliateBarChild(e, mgl32.Vec2{size.X() - 10, 10.0}, core.AnchorTypeCenter)
 	e.HealthBar.SetOffset(e.HealthBar.GetOffset().Add(mgl32.Vec2{0.0, size.Y() / 2}))
 	e.SetType(core.ObjectTypeEnemy)
@@ -81,12 +103,20 @@ func (e *Enemy) Update(dt float32) {
 	if e.Stats.GetAlive() {
 		e.aimTarget(e.target)
 		e.Move(dt)
-		e.attack()
 	}
-	e.checkState()
 	e.remove()
 }
 
+// 碰撞开始，碰到玩家时攻击
+func (e *Enemy) OnCollisionEnter(collider, other core.IObjectCollider) {
+	e.attack(other)
+}
+
+// 碰撞持续，一直接触玩家时持续攻击，玩家受伤后的无敌时间内不会再受伤
+func (e *Enemy) OnCollisionStay(collider, other core.IObjectCollider) {
+	e.attack(other)
+}
+
 // 非接口实现
 
 // 设置目标玩家
@@ -94,6 +124,16 @@ func (e *Enemy) SetTarget(target *Player) {
 	e.target = target
 }
 
+// 获取目标玩家
+func (e *Enemy) GetTarget() *Player {
+	return e.target
+}
+
+// 获取敌人状态
+func (e *Enemy) GetState() EnemyState {
+	return EnemyState(e.animator.GetState())
+}
+
 // 瞄准目标
 func (e *Enemy) aimTarget(target *Player) {
 	if target == nil {
@@ -106,56 +146,20 @@ func (e *Enemy) aimTarget(target *Player) {
 	e.SetVelocity(direction.Mul(e.GetMaxSpeed()))
 }
 
-// 检查状态
-func (e *Enemy) checkState() {
-	state := EnemyStateNormal
-	if e.Stats.GetHealth() < 0.1 {
-		state = EnemyStateDead
-	} else if e.Stats.GetInvincible() {
-		state = EnemyStateHurt
-	} else {
-		state = EnemyStateNormal
-	}
-
-	if e.currentState != state {
-		e.changeState(state)
-	}
-}
-
-// 改变状态
-func (e *Enemy) changeState(newState EnemyState) {
-	e.currentSpriteAnim.SetActive(false)
-	switch newState {
-	case EnemyStateNormal:
-		e.currentSpriteAnim = e.spriteAnimNormal
-		e.currentSpriteAnim.SetActive(true)
-	case EnemyStateHurt:
-		e.currentSpriteAnim = e.spriteAnimHurt
-		e.currentSpriteAnim.SetActive(true)
-	case EnemyStateDead:
-		e.currentSpriteAnim = e.spriteAnimDead
-		e.currentSpriteAnim.SetActive(true)
-		e.Game().AddScore(e.score)
-	}
-
-	e.currentState = newState
-}
-
 // 移除
 func (e *Enemy) remove() {
-	if e.currentSpriteAnim.GetFinish() {
+	if e.animator.GetFinish() {
 		e.SetNeedRemove(true)
 	}
 }
 
-// 攻击
-func (e *Enemy) attack() {
-	if e.target == nil {
+// 攻击碰到的对象
+func (e *Enemy) attack(other core.IObjectCollider) {
+	target, ok := other.GetParent().(core.IActor)
+	if !ok {
 		return
 	}
-	if e.Collider.IsColliding(e.target.GetCollider()) {
-		if e.Stats.GetAlive() && e.target.Stats.GetAlive() {
-			e.target.TakeDamage(e.Stats.GetDamage())
-		}
+	if e.Stats.GetAlive() && target.GetAlive() {
+		target.TakeDamage(e.Stats.GetDamage())
 	}
 }
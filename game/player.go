@@ -4,6 +4,7 @@ import (
 	"ghost_escape/game/affiliate"
 	"ghost_escape/game/core"
 	"ghost_escape/game/world"
+	"math"
 
 	"github.com/SunshineZzzz/purego-sdl3/sdl"
 	"github.com/go-gl/mathgl/mgl32"
@@ -15,18 +16,32 @@ type Player struct {
 	core.Actor
 	// 雷武器组件
 	Weapon *WeaponThunder
-	// 空闲精灵动画
-	spriteIdleAnim *affiliate.SpriteAnim
-	// 移动精灵动画
-	spriteMoveAnim *affiliate.SpriteAnim
-	// 是否在运动
-	isMoving bool
+	// 动画状态机
+	animator *affiliate.Animator
+	// 移动时的拖尾
+	trail *affiliate.ParticleEmitter
 	// 受伤闪烁timer
 	flashTimer *core.Timer
 	// 死亡特效
 	deadEffect *world.Effect
 }
 
+// 玩家移动时留下的拖尾
+var playerTrailParticles = affiliate.ParticleConfig{
+	Rate:        40.0,
+	LifetimeMin: 0.3,
+	LifetimeMax: 0.6,
+	SpeedMin:    0.0,
+	SpeedMax:    20.0,
+	Spread:      360.0,
+	Radius:      8.0,
+	StartColor:  sdl.FColor{R: 0.8, G: 0.8, B: 1.0, A: 0.5},
+	EndColor:    sdl.FColor{R: 0.5, G: 0.5, B: 1.0, A: 0.0},
+	StartSize:   8.0,
+	EndSize:     2.0,
+	Space:       affiliate.ParticleSpaceWorld,
+}
+
 var _ core.IObject = (*Player)(nil)
 var _ core.IObjectScreen = (*Player)(nil)
 var _ core.IActor = (*Player)(nil)
@@ -35,12 +50,18 @@ var _ core.IActor = (*Player)(nil)
 func (p *Player) Init() {
 	p.Actor.Init()
 	p.MaxSpeed = 500.0
-	p.spriteIdleAnim = affiliate.AddSpriteAnimChild(p, "assets/sprite/ghost-idle.png", 2.0, core.AnchorTypeCenter)
-	p.spriteMoveAnim = affiliate.AddSpriteAnimChild(p, "assets/sprite/ghost-move.png", 2.0, core.AnchorTypeCenter)
-	p.spriteIdleAnim.SetActive(true)
-	p.spriteMoveAnim.SetActive(false)
-	p.isMoving = false
-	p.Collider = affiliate.AddColliderChild(p, p.spriteIdleAnim.GetSize().Mul(0.5), core.ColliderTypeCircle, core.AnchorTypeCenter)
+	// 拖尾先添加，绘制在精灵下面
+	p.trail = affiliate.AddParticleEmitterChild(p, playerTrailParticles)
+	spriteIdleAnim := affiliate.AddSpriteAnimChild(p, "assets/sprite/ghost-idle.png", 2.0, core.AnchorTypeCenter)
+	spriteMoveAnim := affiliate.AddSpriteAnimChild(p, "assets/sprite/ghost-move.png", 2.0, core.AnchorTypeCenter)
+	// 空闲和移动切换时接着当前帧播放
+	p.animator = affiliate.AddAnimatorChild(&p.Actor)
+	p.animator.AddState(affiliate.AnimState{Name: "idle", Anim: spriteIdleAnim, Loop: true, Mode: affiliate.AnimTransitionModeSync})
+	p.animator.AddState(affiliate.AnimState{Name: "move", Anim: spriteMoveAnim, Loop: true, Mode: affiliate.AnimTransitionModeSync})
+	p.animator.AddTransition("", "move", affiliate.AnimCondition{Type: affiliate.AnimConditionSpeedAbove, Value: 0.1})
+	p.animator.AddTransition("", "idle")
+	p.Collider = affiliate.AddColliderChild(p, spriteIdleAnim.GetSize().Mul(0.5), core.ColliderTypeCircle, core.AnchorTypeCenter)
+	p.Collider.SetCollisionLayer(core.CollisionLayerPlayer)
 	p.Stats = core.AddStatusChild(&p.Actor, 100.0, 100.0, 40.0, 10.0)
 	// 雷武器组件
 	p.Weapon = AddWeaponThunderChild(&p.Actor, 2.0, 40.0)
@@ -61,12 +82,13 @@ func (p *Player) HandleEvent(event *sdl.Event) {
 // 更新
 func (p *Player) Update(dt float32) {
 	p.Actor.Update(dt)
-	// 速度慢慢减速
-	p.Velocity = p.Velocity.Mul(0.9)
+	// 速度慢慢减速，每1/60秒衰减为0.9倍，与tick频率无关
+	p.Velocity = p.Velocity.Mul(float32(math.Pow(0.9, float64(dt*60.0))))
 	p.keyboardControl()
+	p.gamepadControl()
 	p.Move(dt)
 	p.checkState()
-	p.syncCamera()
+	p.trail.SetEmitting(p.Velocity.Len() > 0.1)
 	p.checkIsDead()
 }
 
@@ -87,56 +109,35 @@ func (p *Player) Clean() {
 
 // 键盘控制
 func (p *Player) keyboardControl() {
-	currentKeyStates := sdl.GetKeyboardState()
-	if currentKeyStates[sdl.ScancodeW] {
+	if p.Game().IsActionPressed(core.ActionMoveUp) {
 		p.Velocity[1] = -p.MaxSpeed
 	}
-	if currentKeyStates[sdl.ScancodeS] {
+	if p.Game().IsActionPressed(core.ActionMoveDown) {
 		p.Velocity[1] = p.MaxSpeed
 	}
-	if currentKeyStates[sdl.ScancodeA] {
+	if p.Game().IsActionPressed(core.ActionMoveLeft) {
 		p.Velocity[0] = -p.MaxSpeed
 	}
-	if currentKeyStates[sdl.ScancodeD] {
+	if p.Game().IsActionPressed(core.ActionMoveRight) {
 		p.Velocity[0] = p.MaxSpeed
 	}
 }
 
-// 同步相机
-func (p *Player) syncCamera() {
-	// 相机跟着玩家一起移动
-	p.Game().GetCurrentScene().SetCameraPosition(p.Position.Sub(p.Game().GetScreenSize().Mul(0.5)))
-}
-
-// 检查状态
-func (p *Player) checkState() {
-	if p.Velocity.X() < 0.0 {
-		p.spriteIdleAnim.SetFlip(true)
-		p.spriteMoveAnim.SetFlip(true)
-	} else {
-		p.spriteIdleAnim.SetFlip(false)
-		p.spriteMoveAnim.SetFlip(false)
+// 手柄控制，左摇杆推动时按推杆幅度设置速度
+func (p *Player) gamepadControl() {
+	if p.Game().IsInputBlocked() {
+		return
 	}
-	newIsMoving := p.Velocity.Len() > 0.1
-	if newIsMoving != p.isMoving {
-		p.isMoving = newIsMoving
-		p.changeState()
+	stick := p.Game().GetGamepad().GetLeftStick()
+	if stick.Len() <= 0.0 {
+		return
 	}
+	p.Velocity = stick.Mul(p.MaxSpeed)
 }
 
-// 切换状态
-func (p *Player) changeState() {
-	if p.isMoving {
-		p.spriteIdleAnim.SetActive(false)
-		p.spriteMoveAnim.SetActive(true)
-		p.spriteMoveAnim.SetFrameTimer(p.spriteIdleAnim.GetCurrentFrame())
-		p.spriteMoveAnim.SetCurrentFrame(p.spriteIdleAnim.GetCurrentFrame())
-	} else {
-		p.spriteIdleAnim.SetActive(true)
-		p.spriteMoveAnim.SetActive(false)
-		p.spriteIdleAnim.SetFrameTimer(p.spriteMoveAnim.GetCurrentFrame())
-		p.spriteIdleAnim.SetCurrentFrame(p.spriteMoveAnim.GetCurrentFrame())
-	}
+// 检查状态，空闲和移动由动画状态机切换，这里只处理朝向
+func (p *Player) checkState() {
+	p.animator.SetFlip(p.Velocity.X() < 0.0)
 }
 
 // 获取技能使用恢复百分比
@@ -153,6 +154,7 @@ func (p *Player) TakeDamage(damage float32) {
 		return
 	}
 	p.Actor.TakeDamage(damage)
+	p.Game().GetCurrentScene().GetCamera().AddTrauma(0.5)
 	// fmt.Printf("玩家受到伤害：%f\n", damage)
 	p.Game().PlaySound("assets/sound/hit-flesh-02-266309.mp3", false)
 }
@@ -51,6 +51,7 @@ func AddBgStarChild(parent core.IObject, num int, far, mid, near float32) *BgSta
 	bgStar.starNear = make([]mgl32.Vec2, 0, num)
 
 	// 计算出，摄像机从左到右，从上到下的移动的像素值
+	random := bgStar.Game().GetRand(core.RandStreamVisual)
 	extra := bgStar.Game().GetCurrentScene().GetWorldSize().Add(mgl32.Vec2{-bgStar.Game().GetScreenSize().X(), -bgStar.Game().GetScreenSize().Y()})
 	for range num {
 		// 依次随机生成远星、中星、近星的图片内容
@@ -58,9 +59,9 @@ func AddBgStarChild(parent core.IObject, num int, far, mid, near float32) *BgSta
 		// 远星的视差系数是0.1，那么就随机生成一个位置范围是[0.0， 19.0]^2，当摄像机从世界的(0, 0)移动到(100, 100)时，
 		// 就会看到屏幕上的星星从(0, 0)移动到(19.0, 19.0)。
 		// 其他同理
-		bgStar.starFar = append(bgStar.starFar, core.GetInstance().RandVec2(mgl32.Vec2{0.0, 0.0}, bgStar.Game().GetScreenSize().Add(extra.Mul(bgStar.parallaxFar))))
-		bgStar.starMid = append(bgStar.starMid, core.GetInstance().RandVec2(mgl32.Vec2{0.0, 0.0}, bgStar.Game().GetScreenSize().Add(extra.Mul(bgStar.parallaxMid))))
-		bgStar.starNear = append(bgStar.starNear, core.GetInstance().RandVec2(mgl32.Vec2{0.0, 0.0}, bgStar.Game().GetScreenSize().Add(extra.Mul(bgStar.parallaxNear))))
+		bgStar.starFar = append(bgStar.starFar, random.RandVec2(mgl32.Vec2{0.0, 0.0}, bgStar.Game().GetScreenSize().Add(extra.Mul(bgStar.parallaxFar))))
+		bgStar.starMid = append(bgStar.starMid, random.RandVec2(mgl32.Vec2{0.0, 0.0}, bgStar.Game().GetScreenSize().Add(extra.Mul(bgStar.parallaxMid))))
+		bgStar.starNear = append(bgStar.starNear, random.RandVec2(mgl32.Vec2{0.0, 0.0}, bgStar.Game().GetScreenSize().Add(extra.Mul(bgStar.parallaxNear))))
 	}
 	if parent != nil {
 		parent.AddChild(bgStar)
@@ -104,7 +105,20 @@ func (b *BgStar) Update(dt float32) {
 // 渲染
 func (b *BgStar) Render() {
 	// 负数原因是，b.starFar中每一个星星的位置要计算到渲染坐标系的位置，可以理解为 远星位置 - 相机位置 * 视差系数 = 渲染坐标系的远星绘制位置
-	b.Game().DrawPoints(&b.starFar, b.Game().GetCurrentScene().GetCameraPosition().Mul(b.parallaxFar).Mul(-1.0), b.colorFar)
-	b.Game().DrawPoints(&b.starMid, b.Game().GetCurrentScene().GetCameraPosition().Mul(b.parallaxMid).Mul(-1.0), b.colorMid)
-	b.Game().DrawPoints(&b.starNear, b.Game().GetCurrentScene().GetCameraPosition().Mul(b.parallaxNear).Mul(-1.0), b.colorNear)
+	b.renderLayer(&b.starFar, b.parallaxFar, b.colorFar)
+	b.renderLayer(&b.starMid, b.parallaxMid, b.colorMid)
+	b.renderLayer(&b.starNear, b.parallaxNear, b.colorNear)
+}
+
+// 非接口实现
+
+// 渲染一层星星，相机的缩放和旋转也按视差系数生效，越远的星星变化越小
+func (b *BgStar) renderLayer(stars *[]mgl32.Vec2, parallax float32, color sdl.FColor) {
+	scene := b.Game().GetCurrentScene()
+	view := scene.GetCamera().GetView()
+	view.Zoom = 1.0 + (view.Zoom-1.0)*parallax
+	view.Rotation *= parallax
+	b.Game().BeginWorldView(view)
+	b.Game().DrawPoints(stars, scene.GetRenderCameraPosition().Mul(parallax).Mul(-1.0), color)
+	b.Game().EndWorldView()
 }
@@ -0,0 +1,79 @@
+package game
+
+import (
+	"ghost_escape/game/core"
+	"ghost_escape/game/screen"
+
+	"github.com/SunshineZzzz/purego-sdl3/sdl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// 贡献者名单场景，覆盖在标题场景上
+type SceneCredits struct {
+	// 继承基础场景
+	core.Scene
+	// 贡献者名单文本
+	creditsText *screen.HudText
+	// UI鼠标
+	uimouse *screen.UIMouse
+	// 是否已经请求关闭，防止重复弹出
+	isClosing bool
+}
+
+var _ core.IObject = (*SceneCredits)(nil)
+var _ core.IScene = (*SceneCredits)(nil)
+
+// 初始化
+func (s *SceneCredits) Init() {
+	s.Scene.Init()
+	s.RenderBelow = true
+	s.isClosing = false
+	text, err := s.Game().LoadTextFromFile("assets/credits.txt")
+	if err != nil {
+		s.close()
+		return
+	}
+	s.creditsText = screen.AddHudTextChild(s, text, s.Game().GetScreenSize().Mul(0.5),
+		mgl32.Vec2{500, 500}, "assets/font/VonwaonBitmap-16px.ttf", 16, "assets/UI/Textfield_01.png", core.AnchorTypeCenter)
+	s.creditsText.SetBgSizeByText(50.0)
+
+	// UI鼠标
+	s.uimouse = screen.AddUIMouseChild(s, "assets/UI/pointer_c_shaded.png", "assets/UI/pointer_c_shaded.png", 1.0, core.AnchorTypeTopLeft)
+}
+
+// 处理事件
+func (s *SceneCredits) HandleEvent(event *sdl.Event) {
+	s.Scene.HandleEvent(event)
+	if event.Type() == sdl.EventMouseButtonUp {
+		s.close()
+	}
+}
+
+// 更新
+func (s *SceneCredits) Update(dt float32) {
+	s.Scene.Update(dt)
+	if s.Game().WasActionJustPressed(core.ActionMenuConfirm) || s.Game().WasActionJustPressed(core.ActionMenuBack) {
+		s.close()
+	}
+}
+
+// 渲染
+func (s *SceneCredits) Render() {
+	s.Scene.Render()
+}
+
+// 清理
+func (s *SceneCredits) Clean() {
+	s.Scene.Clean()
+}
+
+// 非接口实现
+
+// 关闭贡献者名单，回到标题场景
+func (s *SceneCredits) close() {
+	if s.isClosing {
+		return
+	}
+	s.isClosing = true
+	s.Game().SafePopScene()
+}
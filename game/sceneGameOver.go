@@ -0,0 +1,125 @@
+package game
+
+import (
+	"ghost_escape/game/core"
+	"ghost_escape/game/screen"
+	"strconv"
+
+	"github.com/SunshineZzzz/purego-sdl3/sdl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// 游戏结束场景，覆盖在主场景上，主场景保持渲染但不更新
+type SceneGameOver struct {
+	// 继承基础场景
+	core.Scene
+	// 结束的主场景
+	sceneMain *SceneMain
+	// 重新游戏按钮
+	buttonRestart *screen.HudButton
+	// 退到标题场景按钮
+	buttonBack *screen.HudButton
+	// 随机种子文本
+	hudSeed *screen.HudText
+	// 菜单，手柄或者键盘导航按钮
+	menu *screen.HudMenu
+	// UI鼠标
+	uimouse *screen.UIMouse
+}
+
+var _ core.IObject = (*SceneGameOver)(nil)
+var _ core.IScene = (*SceneGameOver)(nil)
+
+// 创建游戏结束场景
+func CreateSceneGameOver(sceneMain *SceneMain) *SceneGameOver {
+	return &SceneGameOver{sceneMain: sceneMain}
+}
+
+// 初始化
+func (s *SceneGameOver) Init() {
+	s.Scene.Init()
+	s.RenderBelow = true
+	center := s.Game().GetScreenSize().Mul(0.5)
+
+	// 随机种子文本，方便分享和复现
+	seedText := "Seed: " + strconv.FormatInt(s.Game().GetSeed(), 10)
+	s.hudSeed = screen.AddHudTextChild(s, seedText, center.Add(mgl32.Vec2{0.0, -150.0}), mgl32.Vec2{200.0, 50.0},
+		"assets/font/VonwaonBitmap-16px.ttf", 32.0, "assets/UI/Textfield_01.png", core.AnchorTypeCenter)
+	s.hudSeed.SetBgSizeByText(30.0)
+
+	s.buttonRestart = screen.AddHudButtonChild(s, center.Add(mgl32.Vec2{-200.0, 0.0}),
+		"assets/UI/A_Restart1.png", "assets/UI/A_Restart2.png", "assets/UI/A_Restart3.png", 4.0, core.AnchorTypeCenter)
+	s.buttonBack = screen.AddHudButtonChild(s, center.Add(mgl32.Vec2{200.0, 0.0}),
+		"assets/UI/A_Back1.png", "assets/UI/A_Back2.png", "assets/UI/A_Back3.png", 4.0, core.AnchorTypeCenter)
+	s.menu = screen.AddHudMenuChild(s, s.buttonRestart, s.buttonBack)
+	// 按钮从屏幕下方弹入
+	s.showButton(s.buttonRestart, 0.0)
+	s.showButton(s.buttonBack, 0.1)
+
+	// UI鼠标
+	s.uimouse = screen.AddUIMouseChild(s, "assets/UI/29.png", "assets/UI/30.png", 1.0, core.AnchorTypeCenter)
+}
+
+// 处理事件
+func (s *SceneGameOver) HandleEvent(event *sdl.Event) {
+	s.Scene.HandleEvent(event)
+}
+
+// 更新
+func (s *SceneGameOver) Update(dt float32) {
+	s.Scene.Update(dt)
+	s.checkButtonRestart()
+	s.checkButtonBack()
+}
+
+// 渲染
+func (s *SceneGameOver) Render() {
+	s.Scene.Render()
+}
+
+// 清理
+func (s *SceneGameOver) Clean() {
+	s.Scene.Clean()
+}
+
+// 进入场景栈，暂停所有声音
+func (s *SceneGameOver) OnEnter() {
+	s.Game().PauseAllMusic()
+	s.Game().PauseAllEffects()
+}
+
+// 离开场景栈，恢复所有声音
+func (s *SceneGameOver) OnExit() {
+	s.Game().ResumeAllMusic()
+	s.Game().ResumeAllEffects()
+}
+
+// 非接口实现
+
+// 按钮从屏幕下方移动到当前位置，同时从一半大小放大
+func (s *SceneGameOver) showButton(button *screen.HudButton, delay float32) {
+	pos := button.GetRenderPosition()
+	button.SetRenderPosition(mgl32.Vec2{pos.X(), s.Game().GetScreenSize().Y() + 100.0})
+	move := core.AddTweenRenderPositionChild(button, pos, 0.5)
+	move.SetDelay(delay)
+	move.SetEase(core.EaseOutBack)
+	scale := core.AddTweenScaleChild(button, 0.5, 1.0, 0.5)
+	scale.SetDelay(delay)
+	scale.SetEase(core.EaseOutBack)
+}
+
+// 检查重新游戏按钮
+func (s *SceneGameOver) checkButtonRestart() {
+	if !s.buttonRestart.GetIsTrigger() {
+		return
+	}
+	s.sceneMain.restart()
+}
+
+// 检查退到标题场景按钮
+func (s *SceneGameOver) checkButtonBack() {
+	if !s.buttonBack.GetIsTrigger() {
+		return
+	}
+	s.sceneMain.backToTitle()
+}
@@ -0,0 +1,116 @@
+package game
+
+import (
+	"fmt"
+	"ghost_escape/game/core"
+	"ghost_escape/game/screen"
+	"strconv"
+
+	"github.com/SunshineZzzz/purego-sdl3/sdl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// 主场景素材清单
+const manifestMain = "assets/manifest/main.json"
+
+// 加载场景，预加载下一个场景的素材并显示进度
+type SceneLoading struct {
+	// 继承基础场景
+	core.Scene
+	// 素材清单路径
+	manifestPath string
+	// 加载完成后进入的场景
+	nextScene core.IScene
+	// 预加载器
+	preloader *core.AssetPreloader
+	// 进度文本
+	hudProgress *screen.HudText
+	// 进度条大小
+	barSize mgl32.Vec2
+	// 是否已经进入下一个场景
+	isDone bool
+}
+
+var _ core.IObject = (*SceneLoading)(nil)
+var _ core.IScene = (*SceneLoading)(nil)
+
+// 创建加载场景
+func CreateSceneLoading(manifestPath string, nextScene core.IScene) *SceneLoading {
+	return &SceneLoading{manifestPath: manifestPath, nextScene: nextScene}
+}
+
+// 初始化
+func (s *SceneLoading) Init() {
+	s.Scene.Init()
+	s.isDone = false
+	s.barSize = mgl32.Vec2{s.Game().GetScreenSize().X() * 0.5, 30.0}
+	s.hudProgress = screen.AddHudTextChild(s, "加载中 0%", s.Game().GetScreenSize().Mul(0.5).Add(mgl32.Vec2{0.0, -80.0}),
+		mgl32.Vec2{300.0, 60.0}, "assets/font/VonwaonBitmap-16px.ttf", 32.0, "assets/UI/Textfield_01.png", core.AnchorTypeCenter)
+
+	// 预加载的素材属于下一个场景，加载场景退出时不会被卸载
+	preloader, err := s.Game().PreloadAssets(s.manifestPath, s.nextScene)
+	if err != nil {
+		// 清单都读不到就不预加载了，素材会在使用时加载
+		fmt.Printf("preload assets error,%v\n", err)
+		return
+	}
+	s.preloader = preloader
+}
+
+// 处理事件
+func (s *SceneLoading) HandleEvent(event *sdl.Event) {
+	s.Scene.HandleEvent(event)
+}
+
+// 更新
+func (s *SceneLoading) Update(dt float32) {
+	s.Scene.Update(dt)
+	if s.isDone {
+		return
+	}
+	if s.preloader != nil && !s.preloader.Step() {
+		s.hudProgress.SetText("加载中 " + strconv.Itoa(int(s.GetProgress()*100.0)) + "%")
+		return
+	}
+	s.hudProgress.SetText("加载中 100%")
+	if s.preloader != nil {
+		if err := s.preloader.GetError(); err != nil {
+			fmt.Printf("preload assets error,%v\n", err)
+		}
+	}
+	s.isDone = true
+	// 圆形遮罩在玩家身上张开
+	transition := core.CreateTransition(core.TransitionTypeIris, 1.2)
+	transition.SetCenter(playerScreenPosition)
+	s.Game().SafeTransitionScene(s.nextScene, transition)
+}
+
+// 渲染
+func (s *SceneLoading) Render() {
+	s.Scene.Render()
+	s.renderProgressBar()
+}
+
+// 清理
+func (s *SceneLoading) Clean() {
+	s.Scene.Clean()
+}
+
+// 非接口实现
+
+// 渲染进度条
+func (s *SceneLoading) renderProgressBar() {
+	topLeft := s.Game().GetScreenSize().Mul(0.5).Sub(s.barSize.Mul(0.5))
+	bottomRight := topLeft.Add(s.barSize)
+	fill := mgl32.Vec2{topLeft.X() + s.barSize.X()*s.GetProgress(), bottomRight.Y()}
+	s.Game().DrawFillRect(topLeft, fill, sdl.FColor{R: 0.3, G: 0.6, B: 1.0, A: 1.0})
+	s.Game().DrawBoundary(topLeft, bottomRight, 3.0, sdl.FColor{R: 1.0, G: 1.0, B: 1.0, A: 1.0})
+}
+
+// 获取加载进度
+func (s *SceneLoading) GetProgress() float32 {
+	if s.preloader == nil {
+		return 1.0
+	}
+	return s.preloader.GetProgress()
+}
@@ -12,6 +12,9 @@ import (
 	"github.com/go-gl/mathgl/mgl32"
 )
 
+// 敌人数量达到这个值时相机拉远
+const crowdEnemyCount = 20
+
 type SceneMain struct {
 	// 继承基础场景
 	core.Scene
@@ -42,12 +45,13 @@ var _ core.IScene = (*SceneMain)(nil)
 
 func (s *SceneMain) Init() {
 	s.Scene.Init()
+	// 每一局开始重置随机数流
+	s.Game().ResetRand()
 	sdl.HideCursor()
 	s.Game().StopAllMusic()
 	s.Game().StopAllEffects()
 	s.Game().PlayMusic("assets/bgm/OhMyGhost.ogg", true)
 	s.WorldSize = s.Game().GetScreenSize().Mul(3.0)
-	s.CameraPositon = s.WorldSize.Mul(0.5).Sub(s.Game().GetScreenSize().Mul(0.5))
 
 	// 玩家
 	s.player = &Player{}
@@ -55,6 +59,16 @@ func (s *SceneMain) Init() {
 	s.player.SetPosition(s.WorldSize.Mul(0.5))
 	s.AddChild(s.player)
 
+	// 相机平滑跟随玩家，看向移动方向前方
+	camera := s.GetCamera()
+	camera.SetTarget(s.player)
+	camera.SetFollowSpeed(6.0)
+	camera.SetDeadzone(mgl32.Vec2{80.0, 60.0})
+	camera.SetLookAhead(0.25, 120.0)
+	camera.LookAt(s.player.GetPosition())
+	s.CameraPositon = camera.GetPosition()
+	s.PrevCameraPosition = s.CameraPositon
+
 	// 增加视差星空背景
 	raw.AddBgStarChild(s, 200, 0.2, 0.5, 0.7)
 
@@ -105,9 +119,14 @@ func (s *SceneMain) Update(dt float32) {
 	s.checkButtonRestart()
 	s.checkButtonBack()
 	s.checkButtonPause()
-	if s.player != nil && !s.player.GetActive() {
-		s.endTimer.Start()
-		s.SaveData("assets/score.dat")
+	s.updateZoom()
+	if s.player != nil {
+		// 手柄准星瞄准时隐藏鼠标准星
+		s.uimouse.SetActive(!s.player.Weapon.GetUseReticle())
+		if !s.player.GetActive() {
+			s.endTimer.Start()
+			s.SaveData("assets/score.dat")
+		}
 	}
 	s.checkEndTimer()
 }
@@ -121,16 +140,51 @@ func (s *SceneMain) Clean() {
 	s.Scene.Clean()
 }
 
+// 被暂停或者游戏结束场景覆盖时隐藏UI鼠标，覆盖场景有自己的UI鼠标
+func (s *SceneMain) OnPause() {
+	s.uimouse.SetActive(false)
+}
+
+// 重新成为栈顶时显示UI鼠标
+func (s *SceneMain) OnResume() {
+	s.uimouse.SetActive(true)
+}
+
 // 非接口实现
 
 // 渲染背景
 func (s *SceneMain) renderBackground() {
+	// 背景跟随插值后的摄像机，和世界对象保持一致
+	camera := s.GetRenderCameraPosition()
 	// 背景绘制起始
-	start := s.WorldToScreen(mgl32.Vec2{0.0, 0.0})
+	start := mgl32.Vec2{0.0, 0.0}.Sub(camera)
 	// 背景绘制结束
-	end := s.WorldToScreen(s.WorldSize)
+	end := s.WorldSize.Sub(camera)
+	// 背景和世界对象一样跟着相机缩放旋转
+	s.Game().BeginWorldView(s.Camera.GetView())
 	s.Game().DrawGrid(start, end, 80.0, sdl.FColor{R: 0.5, G: 0.5, B: 0.5, A: 1.0})
 	s.Game().DrawBoundary(start, end, 5.0, sdl.FColor{R: 1.0, G: 1.0, B: 1.0, A: 1.0})
+	s.Game().EndWorldView()
+}
+
+// 更新相机缩放，敌人多时拉远看到更大范围，玩家死亡时拉近
+func (s *SceneMain) updateZoom() {
+	camera := s.GetCamera()
+	if s.player != nil && !s.player.GetActive() {
+		camera.ZoomTo(1.5, 2.0)
+		return
+	}
+	count := 0
+	for e := s.GetChildWorld().Front(); e != nil; e = e.Next() {
+		if _, ok := e.Value.(*Enemy); ok {
+			count++
+		}
+	}
+	if count >= crowdEnemyCount {
+		camera.ZoomTo(0.8, 1.0)
+		return
+	}
+	camera.ZoomTo(1.0, 1.0)
 }
 
 // 更新分数
@@ -149,9 +203,7 @@ func (s *SceneMain) checkButtonRestart() {
 	if !s.buttonRestart.GetIsTrigger() {
 		return
 	}
-	s.SaveData("assets/score.dat")
-	s.Game().SetScore(0)
-	s.Game().SafeChangeScene(s)
+	s.restart()
 }
 
 // 检查退到标题场景按钮
@@ -162,24 +214,43 @@ func (s *SceneMain) checkButtonBack() {
 	if !s.buttonBack.GetIsTrigger() {
 		return
 	}
+	s.backToTitle()
+}
+
+// 重新游戏，暂停和游戏结束场景也会调用
+func (s *SceneMain) restart() {
+	s.SaveData("assets/score.dat")
+	s.Game().SetScore(0)
+	s.Game().SafeTransitionScene(s, core.CreateTransition(core.TransitionTypeFade, 0.8))
+}
+
+// 退到标题场景，暂停和游戏结束场景也会调用
+func (s *SceneMain) backToTitle() {
 	s.SaveData("assets/score.dat")
 	s.Game().SetScore(0)
-	s.Game().SafeChangeScene(&SceneTitle{})
+	s.Game().SafeTransitionScene(&SceneTitle{}, core.CreateTransition(core.TransitionTypeCrossfade, 0.8))
+}
+
+// 获取玩家屏幕位置，用于圆形转场，场景栈中没有主场景时返回屏幕中心
+func playerScreenPosition() mgl32.Vec2 {
+	scenes := core.GetInstance().GetSceneStack()
+	for i := len(scenes) - 1; i >= 0; i-- {
+		if sceneMain, ok := scenes[i].(*SceneMain); ok && sceneMain.player != nil {
+			return sceneMain.WorldToScreen(sceneMain.player.GetPosition())
+		}
+	}
+	return core.GetInstance().GetScreenSize().Mul(0.5)
 }
 
 // 检查暂停按钮
 func (s *SceneMain) checkButtonPause() {
-	if s.buttonPause == nil {
+	if s.buttonPause == nil || !s.buttonPause.GetActive() {
 		return
 	}
-	if !s.buttonPause.GetIsTrigger() {
+	if !s.buttonPause.GetIsTrigger() && !s.Game().WasActionJustPressed(core.ActionPause) {
 		return
 	}
-	if s.IsPause {
-		s.Resume()
-		return
-	}
-	s.Pause()
+	s.Game().SafePushScene(CreateScenePause(s))
 }
 
 // 检查游戏结束timer
@@ -187,19 +258,16 @@ func (s *SceneMain) checkEndTimer() {
 	if s.endTimer != nil && !s.endTimer.TimeOut() {
 		return
 	}
-	s.Pause()
-	s.buttonRestart.SetRenderPosition(s.Game().GetScreenSize().Mul(0.5).Add(mgl32.Vec2{-200.0, 0.0}))
-	s.buttonRestart.SetScale(4.0)
-	s.buttonBack.SetRenderPosition(s.Game().GetScreenSize().Mul(0.5).Add(mgl32.Vec2{200.0, 0.0}))
-	s.buttonBack.SetScale(4.0)
 	s.buttonPause.SetActive(false)
 	s.endTimer.Stop()
+	s.Game().SafePushScene(CreateSceneGameOver(s))
 }
 
 // 保存数据
 func (s *SceneMain) SaveData(filePath string) {
 	highScore := s.Game().GetHighScore()
-	file, err := os.Create(filePath)
+	// 存档写到系统目录，素材可能在素材包或者程序内
+	file, err := os.Create(s.Game().GetWritePath(filePath))
 	if err != nil {
 		return
 	}
@@ -210,10 +278,31 @@ func (s *SceneMain) SaveData(filePath string) {
 	}
 }
 
+// 获取玩家
+func (s *SceneMain) GetPlayer() *Player {
+	return s.player
+}
+
+// 获取生成器
+func (s *SceneMain) GetSpawner() *Spawner {
+	return s.spawner
+}
+
+// 获取场景中所有敌人
+func (s *SceneMain) GetEnemies() []*Enemy {
+	enemies := make([]*Enemy, 0)
+	for e := s.ChildrenWorld.Front(); e != nil; e = e.Next() {
+		if enemy, ok := e.Value.(*Enemy); ok {
+			enemies = append(enemies, enemy)
+		}
+	}
+	return enemies
+}
+
 // 检查是否需要减速
 func (s *SceneMain) checkSlowDown(dt *float32) {
-	if s.Game().GetMouseButtons()&sdl.ButtonRMask != 0 {
-		// 按下右键减速
+	if s.Game().IsActionPressed(core.ActionSlowTime) {
+		// 按下减速动作
 		*dt *= 0.4
 	}
 }
@@ -0,0 +1,117 @@
+package game
+
+import (
+	"ghost_escape/game/core"
+	"ghost_escape/game/screen"
+
+	"github.com/SunshineZzzz/purego-sdl3/sdl"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// 暂停场景，覆盖在主场景上，主场景保持渲染但不更新
+type ScenePause struct {
+	// 继承基础场景
+	core.Scene
+	// 被暂停的主场景
+	sceneMain *SceneMain
+	// 继续游戏按钮
+	buttonResume *screen.HudButton
+	// 重新游戏按钮
+	buttonRestart *screen.HudButton
+	// 退到标题场景按钮
+	buttonBack *screen.HudButton
+	// 菜单，手柄或者键盘导航按钮
+	menu *screen.HudMenu
+	// UI鼠标
+	uimouse *screen.UIMouse
+}
+
+var _ core.IObject = (*ScenePause)(nil)
+var _ core.IScene = (*ScenePause)(nil)
+
+// 创建暂停场景
+func CreateScenePause(sceneMain *SceneMain) *ScenePause {
+	return &ScenePause{sceneMain: sceneMain}
+}
+
+// 初始化
+func (s *ScenePause) Init() {
+	s.Scene.Init()
+	s.RenderBelow = true
+	center := s.Game().GetScreenSize().Mul(0.5)
+	screen.AddHudTextChild(s, "暂 停", center.Add(mgl32.Vec2{0.0, -150.0}), mgl32.Vec2{300.0, 100.0},
+		"assets/font/VonwaonBitmap-16px.ttf", 48.0, "assets/UI/Textfield_01.png", core.AnchorTypeCenter)
+
+	s.buttonResume = screen.AddHudButtonChild(s, center.Add(mgl32.Vec2{-200.0, 50.0}),
+		"assets/UI/A_Start1.png", "assets/UI/A_Start2.png", "assets/UI/A_Start3.png", 2.0, core.AnchorTypeCenter)
+	s.buttonRestart = screen.AddHudButtonChild(s, center.Add(mgl32.Vec2{0.0, 50.0}),
+		"assets/UI/A_Restart1.png", "assets/UI/A_Restart2.png", "assets/UI/A_Restart3.png", 2.0, core.AnchorTypeCenter)
+	s.buttonBack = screen.AddHudButtonChild(s, center.Add(mgl32.Vec2{200.0, 50.0}),
+		"assets/UI/A_Back1.png", "assets/UI/A_Back2.png", "assets/UI/A_Back3.png", 2.0, core.AnchorTypeCenter)
+	s.menu = screen.AddHudMenuChild(s, s.buttonResume, s.buttonRestart, s.buttonBack)
+
+	// UI鼠标
+	s.uimouse = screen.AddUIMouseChild(s, "assets/UI/29.png", "assets/UI/30.png", 1.0, core.AnchorTypeCenter)
+}
+
+// 处理事件
+func (s *ScenePause) HandleEvent(event *sdl.Event) {
+	s.Scene.HandleEvent(event)
+}
+
+// 更新
+func (s *ScenePause) Update(dt float32) {
+	s.Scene.Update(dt)
+	s.checkButtonResume()
+	s.checkButtonRestart()
+	s.checkButtonBack()
+}
+
+// 渲染
+func (s *ScenePause) Render() {
+	s.Scene.Render()
+}
+
+// 清理
+func (s *ScenePause) Clean() {
+	s.Scene.Clean()
+}
+
+// 进入场景栈，暂停所有声音
+func (s *ScenePause) OnEnter() {
+	s.Game().PauseAllMusic()
+	s.Game().PauseAllEffects()
+}
+
+// 离开场景栈，恢复所有声音
+func (s *ScenePause) OnExit() {
+	s.Game().ResumeAllMusic()
+	s.Game().ResumeAllEffects()
+}
+
+// 非接口实现
+
+// 检查继续游戏按钮
+func (s *ScenePause) checkButtonResume() {
+	if !s.buttonResume.GetIsTrigger() && !s.Game().WasActionJustPressed(core.ActionPause) &&
+		!s.Game().WasActionJustPressed(core.ActionMenuBack) {
+		return
+	}
+	s.Game().SafePopScene()
+}
+
+// 检查重新游戏按钮
+func (s *ScenePause) checkButtonRestart() {
+	if !s.buttonRestart.GetIsTrigger() {
+		return
+	}
+	s.sceneMain.restart()
+}
+
+// 检查退到标题场景按钮
+func (s *ScenePause) checkButtonBack() {
+	if !s.buttonBack.GetIsTrigger() {
+		return
+	}
+	s.sceneMain.backToTitle()
+}
@@ -18,16 +18,14 @@ type SceneTitle struct {
 	core.Scene
 	// 边界颜色
 	boundaryColor sdl.FColor
-	// 颜色计时器
-	colorTimer float32
 	// 开始按钮
 	startButton *screen.HudButton
 	// 退出按钮
 	quitButton *screen.HudButton
 	// 贡献者名单按钮
 	creditsButton *screen.HudButton
-	// 贡献者名单文本
-	creditsText *screen.HudText
+	// 菜单，手柄或者键盘导航按钮
+	menu *screen.HudMenu
 	// UI鼠标
 	uimouse *screen.UIMouse
 }
@@ -38,6 +36,7 @@ var _ core.IScene = (*SceneTitle)(nil)
 // 初始化
 func (s *SceneTitle) Init() {
 	s.Scene.Init()
+	s.initColor()
 	s.LoadData("assets/score.dat")
 	sdl.HideCursor()
 	s.Game().StopAllMusic()
@@ -59,15 +58,8 @@ func (s *SceneTitle) Init() {
 	// 退出按钮
 	s.quitButton = screen.AddHudButtonChild(s, s.Game().GetScreenSize().Mul(0.5).Add(mgl32.Vec2{200.0, 200.0}),
 		"assets/UI/A_Quit1.png", "assets/UI/A_Quit2.png", "assets/UI/A_Quit3.png", 2.0, core.AnchorTypeCenter)
-
-	text, err := s.Game().LoadTextFromFile("assets/credits.txt")
-	if err != nil {
-		return
-	}
-	s.creditsText = screen.AddHudTextChild(s, text, s.Game().GetScreenSize().Mul(0.5),
-		mgl32.Vec2{500, 500}, "assets/font/VonwaonBitmap-16px.ttf", 16, "assets/UI/Textfield_01.png", core.AnchorTypeCenter)
-	s.creditsText.SetActive(false)
-	s.creditsText.SetBgSizeByText(50.0)
+	// 菜单
+	s.menu = screen.AddHudMenuChild(s, s.startButton, s.creditsButton, s.quitButton)
 
 	// UI鼠标
 	s.uimouse = screen.AddUIMouseChild(s, "assets/UI/pointer_c_shaded.png", "assets/UI/pointer_c_shaded.png", 1.0, core.AnchorTypeTopLeft)
@@ -75,23 +67,11 @@ func (s *SceneTitle) Init() {
 
 // 处理事件
 func (s *SceneTitle) HandleEvent(event *sdl.Event) {
-	if s.creditsText.GetActive() {
-		if event.Type() == sdl.EventMouseButtonUp {
-			s.creditsText.SetActive(false)
-		}
-		return
-	}
 	s.Scene.HandleEvent(event)
 }
 
 // 更新
 func (s *SceneTitle) Update(dt float32) {
-	s.colorTimer += dt
-	s.updateColor()
-	if s.creditsText.GetActive() {
-		s.uimouse.Update(dt)
-		return
-	}
 	s.Scene.Update(dt)
 	s.checkButtonQuit()
 	s.checkButtonStart()
@@ -107,6 +87,16 @@ func (s *SceneTitle) Clean() {
 	s.Scene.Clean()
 }
 
+// 被贡献者名单覆盖时隐藏UI鼠标，覆盖场景有自己的UI鼠标
+func (s *SceneTitle) OnPause() {
+	s.uimouse.SetActive(false)
+}
+
+// 重新成为栈顶时显示UI鼠标
+func (s *SceneTitle) OnResume() {
+	s.uimouse.SetActive(true)
+}
+
 // 非接口实现
 
 // 渲染背景
@@ -114,11 +104,17 @@ func (s *SceneTitle) renderBackground() {
 	s.Game().DrawBoundary(mgl32.Vec2{30.0, 30.0}, s.Game().GetScreenSize().Sub(mgl32.Vec2{30.0, 30.0}), 10.0, s.boundaryColor)
 }
 
-// 更新颜色
-func (s *SceneTitle) updateColor() {
-	s.boundaryColor.R = 0.5 + 0.5*float32(math.Sin(float64(s.colorTimer*0.9)))
-	s.boundaryColor.G = 0.5 + 0.5*float32(math.Sin(float64(s.colorTimer*0.8)))
-	s.boundaryColor.B = 0.5 + 0.5*float32(math.Sin(float64(s.colorTimer*0.7)))
+// 边界颜色的三个通道以不同周期在0和1之间来回变化
+func (s *SceneTitle) initColor() {
+	channels := []*float32{&s.boundaryColor.R, &s.boundaryColor.G, &s.boundaryColor.B}
+	speeds := []float32{0.9, 0.8, 0.7}
+	for i, channel := range channels {
+		*channel = 0.0
+		tween := core.AddTweenFloatChild(s, channel, 1.0, math.Pi/speeds[i])
+		tween.SetEase(core.EaseInOutSine)
+		tween.SetYoyo(true)
+		tween.SetLoops(0)
+	}
 }
 
 // 检查退出按钮是否触发
@@ -131,20 +127,21 @@ func (s *SceneTitle) checkButtonQuit() {
 // 检查开始按钮是否触发
 func (s *SceneTitle) checkButtonStart() {
 	if s.startButton.GetIsTrigger() {
-		s.Game().SafeChangeScene(&SceneMain{})
+		// 先进入加载场景预加载主场景素材
+		s.Game().SafeTransitionScene(CreateSceneLoading(manifestMain, &SceneMain{}), core.CreateTransition(core.TransitionTypeFade, 0.6))
 	}
 }
 
 // 检查贡献者名单按钮是否触发
 func (s *SceneTitle) checkButtonCredits() {
 	if s.creditsButton.GetIsTrigger() {
-		s.creditsText.SetActive(true)
+		s.Game().SafePushScene(&SceneCredits{})
 	}
 }
 
 // 加载数据
 func (s *SceneTitle) LoadData(filePath string) {
-	file, err := os.Open(filePath)
+	file, err := os.Open(s.Game().GetWritePath(filePath))
 	if err != nil {
 		return
 	}
@@ -24,6 +24,8 @@ type HudButton struct {
 	isPress bool
 	// 是否触发
 	isTrigger bool
+	// 是否被菜单选中，手柄或者键盘导航时等同于悬停
+	isFocus bool
 }
 
 func AddHudButtonChild(parent core.IObject, renderPos mgl32.Vec2, normalPath string, hoverPath string,
@@ -92,11 +94,12 @@ func (b *HudButton) checkHover() {
 
 // 检查状态
 func (b *HudButton) checkState() {
-	if !b.isPress && !b.isHover {
+	isHover := b.isHover || b.isFocus
+	if !b.isPress && !isHover {
 		b.normalSprite.SetActive(true)
 		b.hoverSprite.SetActive(false)
 		b.pressSprite.SetActive(false)
-	} else if !b.isPress && b.isHover {
+	} else if !b.isPress && isHover {
 		b.normalSprite.SetActive(false)
 		b.hoverSprite.SetActive(true)
 		b.pressSprite.SetActive(false)
@@ -116,6 +119,25 @@ func (b *HudButton) GetIsTrigger() bool {
 	return false
 }
 
+// 触发按钮，菜单确认时使用
+func (b *HudButton) Trigger() {
+	b.Game().PlaySound("assets/sound/UI_button08.wav", false)
+	b.isTrigger = true
+}
+
+// 获取是否被选中
+func (b *HudButton) GetFocus() bool {
+	return b.isFocus
+}
+
+// 设置是否被选中
+func (b *HudButton) SetFocus(focus bool) {
+	if focus && !b.isFocus && !b.isHover {
+		b.Game().PlaySound("assets/sound/UI_button12.wav", false)
+	}
+	b.isFocus = focus
+}
+
 // 设置缩放
 func (b *HudButton) SetScale(scale float32) {
 	b.normalSprite.SetScale(scale)
@@ -0,0 +1,121 @@
+package screen
+
+import (
+	"ghost_escape/game/core"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// 菜单HUD，用手柄或者键盘在一组按钮之间导航
+type HudMenu struct {
+	// 继承基础屏幕对象
+	core.ObjectScreen
+	// 按钮，按导航顺序排列
+	buttons []*HudButton
+	// 当前选中按钮索引，-1表示没有选中
+	focusIndex int
+	// 上一次鼠标位置，鼠标移动时取消选中，交给鼠标悬停
+	lastMousePosition mgl32.Vec2
+}
+
+var _ core.IObject = (*HudMenu)(nil)
+var _ core.IObjectScreen = (*HudMenu)(nil)
+
+// 创建菜单HUD
+func AddHudMenuChild(parent core.IObject, buttons ...*HudButton) *HudMenu {
+	m := &HudMenu{}
+	m.Init()
+	m.buttons = buttons
+	m.focusIndex = -1
+	m.lastMousePosition = m.Game().GetMousePosition()
+	if parent != nil {
+		parent.AddChild(m)
+	}
+	return m
+}
+
+// 更新
+func (m *HudMenu) Update(dt float32) {
+	m.ObjectScreen.Update(dt)
+	mousePosition := m.Game().GetMousePosition()
+	if mousePosition != m.lastMousePosition {
+		m.lastMousePosition = mousePosition
+		m.SetFocusIndex(-1)
+	}
+	if m.Game().WasActionJustPressed(core.ActionMenuUp) || m.Game().WasActionJustPressed(core.ActionMenuLeft) {
+		m.moveFocus(-1)
+	}
+	if m.Game().WasActionJustPressed(core.ActionMenuDown) || m.Game().WasActionJustPressed(core.ActionMenuRight) {
+		m.moveFocus(1)
+	}
+	if m.Game().WasActionJustPressed(core.ActionMenuConfirm) {
+		if button := m.GetFocusButton(); button != nil {
+			button.Trigger()
+		}
+	}
+}
+
+// 非接口实现
+
+// 设置是否激活，关闭时取消选中
+func (m *HudMenu) SetActive(active bool) {
+	m.ObjectScreen.SetActive(active)
+	if !active {
+		m.SetFocusIndex(-1)
+	}
+}
+
+// 移动选中，跳过未激活的按钮，首次导航选中第一个按钮
+func (m *HudMenu) moveFocus(step int) {
+	count := len(m.buttons)
+	if count == 0 {
+		return
+	}
+	index := m.focusIndex
+	if index < 0 {
+		index = count - 1
+		if step < 0 {
+			index = 0
+		}
+	}
+	for range count {
+		index = (index + step + count) % count
+		if m.buttons[index].GetActive() {
+			m.SetFocusIndex(index)
+			return
+		}
+	}
+}
+
+// 获取当前选中按钮，没有选中返回nil
+func (m *HudMenu) GetFocusButton() *HudButton {
+	if m.focusIndex < 0 || m.focusIndex >= len(m.buttons) {
+		return nil
+	}
+	button := m.buttons[m.focusIndex]
+	if !button.GetActive() {
+		return nil
+	}
+	return button
+}
+
+// 获取当前选中按钮索引
+func (m *HudMenu) GetFocusIndex() int {
+	return m.focusIndex
+}
+
+// 设置当前选中按钮索引，-1表示取消选中
+func (m *HudMenu) SetFocusIndex(index int) {
+	if index >= len(m.buttons) {
+		index = -1
+	}
+	for i, button := range m.buttons {
+		button.SetFocus(i == index)
+	}
+	m.focusIndex = index
+}
+
+// 获取按钮
+func (m *HudMenu) GetButtons() []*HudButton {
+	return m.buttons
+}
@@ -42,10 +42,11 @@ func (hs *HudSkill) Init() {
 // 渲染
 func (hs *HudSkill) Render() {
 	// 先绘制浅色背景
-	sdl.SetTextureColorModFloat(hs.icon.GetTexture().Texture, 0.3, 0.3, 0.3)
+	texture := hs.icon.GetTexture()
+	texture.Color = sdl.FColor{R: 0.3, G: 0.3, B: 0.3, A: 1.0}
 	pos := hs.GetRenderPosition().Add(hs.icon.GetOffset())
-	hs.Game().RenderTexture(hs.icon.GetTexture(), pos, hs.icon.GetSize(), mgl32.Vec2{1.0, 1.0})
-	sdl.SetTextureColorModFloat(hs.icon.GetTexture().Texture, 1.0, 1.0, 1.0)
+	hs.Game().RenderTexture(texture, pos, hs.icon.GetSize(), mgl32.Vec2{1.0, 1.0})
+	texture.Color = sdl.FColor{R: 1.0, G: 1.0, B: 1.0, A: 1.0}
 	// 再进行正常绘制
 	hs.ObjectScreen.Render()
 }
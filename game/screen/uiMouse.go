@@ -23,6 +23,7 @@ var _ core.IObjectScreen = (*UIMouse)(nil)
 func AddUIMouseChild(parent core.IObject, spritePath1, spritePath2 string, scale float32, anchor core.AnchorType) *UIMouse {
 	uiMouse := &UIMouse{}
 	uiMouse.Init()
+	uiMouse.SetRenderLayer(core.RenderLayerCursor)
 	uiMouse.sprite1 = affiliate.AddSpriteChild(uiMouse, spritePath1, scale, anchor)
 	uiMouse.sprite2 = affiliate.AddSpriteChild(uiMouse, spritePath2, scale, anchor)
 	if parent != nil {
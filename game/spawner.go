@@ -17,6 +17,8 @@ type Spawner struct {
 	timer float32
 	// 目标
 	target *Player
+	// 累计生成数量
+	spawnedCount int
 }
 
 var _ core.IObject = (*Spawner)(nil)
@@ -39,14 +41,17 @@ func (s *Spawner) Update(dt float32) {
 			s.Game().PlaySound("assets/sound/silly-ghost-sound-242342.mp3", false)
 		}
 		for i := 0; i < s.num; i++ {
-			pos := core.GetInstance().RandVec2(
-				core.GetInstance().GetCurrentScene().GetCameraPosition(),
-				core.GetInstance().GetCurrentScene().GetCameraPosition().
-					Add(core.GetInstance().GetScreenSize()),
-			)
+			// 在相机实际看到的范围内生成，缩放后范围是屏幕大小/缩放
+			scene := core.GetInstance().GetCurrentScene()
+			center := scene.ScreenToWorld(core.GetInstance().GetScreenSize().Mul(0.5))
+			halfVisible := core.GetInstance().GetScreenSize().Mul(0.5 / scene.GetCamera().GetZoom())
+			pos := core.GetInstance().GetRand(core.RandStreamSpawn).RandVec2(center.Sub(halfVisible), center.Add(halfVisible))
 			enemy := CreateEnemy(nil, pos, s.target)
+			s.spawnedCount++
 			// 敌人产生是从特效精灵动画结束后产生，所以这里生成特效
-			world.AddEffectChild(core.GetInstance().GetCurrentScene(), "assets/effect/184_3.png", enemy.GetPosition(), 1.0, core.AnchorTypeCenter, enemy)
+			effect := world.AddEffectChild(core.GetInstance().GetCurrentScene(), "assets/effect/184_3.png", enemy.GetPosition(), 1.0, core.AnchorTypeCenter, enemy)
+			// 出生法阵贴在地面上，不挡住角色
+			effect.SetRenderLayer(core.RenderLayerGround)
 		}
 		// s.interval = 1000.0
 	}
@@ -89,6 +94,11 @@ func (s *Spawner) GetTarget() *Player {
 	return s.target
 }
 
+// 获取累计生成数量
+func (s *Spawner) GetSpawnedCount() int {
+	return s.spawnedCount
+}
+
 // 设置目标
 func (s *Spawner) SetTarget(target *Player) {
 	s.target = target
@@ -1,17 +1,49 @@
 package game
 
 import (
+	"ghost_escape/game/affiliate"
 	"ghost_escape/game/core"
 	"ghost_escape/game/raw"
 	"ghost_escape/game/world"
 
 	"github.com/SunshineZzzz/purego-sdl3/sdl"
+	"github.com/go-gl/mathgl/mgl32"
 )
 
+// 雷击动画中闪电落地的帧
+const thunderImpactFrame = 5
+
+// 闪电落地时溅出的火花
+var thunderSparkParticles = affiliate.ParticleConfig{
+	Burst:       40,
+	LifetimeMin: 0.2,
+	LifetimeMax: 0.5,
+	SpeedMin:    150.0,
+	SpeedMax:    450.0,
+	Spread:      360.0,
+	Radius:      10.0,
+	Gravity:     mgl32.Vec2{0.0, 600.0},
+	Damping:     0.9,
+	StartColor:  sdl.FColor{R: 1.0, G: 0.95, B: 0.6, A: 1.0},
+	EndColor:    sdl.FColor{R: 1.0, G: 0.5, B: 0.1, A: 0.0},
+	StartSize:   5.0,
+	EndSize:     1.0,
+}
+
 // 雷武器组件
 type WeaponThunder struct {
 	// 继承基础武器组件
 	raw.Weapon
+	// 准星纹理，右摇杆瞄准时显示
+	reticle *core.Texture
+	// 准星相对玩家的偏移
+	aimOffset mgl32.Vec2
+	// 右摇杆推满时准星离玩家的距离
+	aimRange float32
+	// 是否使用准星瞄准，鼠标移动后切回鼠标瞄准
+	useReticle bool
+	// 上一次鼠标位置
+	lastMousePosition mgl32.Vec2
 }
 
 var _ core.IObject = (*WeaponThunder)(nil)
@@ -23,29 +55,87 @@ func AddWeaponThunderChild(parent *core.Actor, cooldown float32, manaCost float3
 	w.SetParent(parent)
 	w.SetCooldown(cooldown)
 	w.SetManaCost(manaCost)
+	w.reticle = core.CreateTexture("assets/UI/29.png")
+	w.aimRange = 300.0
+	w.lastMousePosition = w.Game().GetMousePosition()
 	if parent != nil {
 		parent.AddChild(w)
 	}
 	return w
 }
 
-// 处理事件
-func (w *WeaponThunder) HandleEvent(event *sdl.Event) {
-	w.Weapon.HandleEvent(event)
-	// 处理攻击事件
-	if event.Type() == sdl.EventMouseButtonDown {
-		if event.Button().Button == uint8(sdl.ButtonLeft) {
-			if w.CanAttack() {
-				w.Game().PlaySound("assets/sound/big-thunder.mp3", false)
-				pos := core.GetInstance().GetMousePosition().Add(core.GetInstance().GetCurrentScene().GetCameraPosition())
-				spell := world.AddSpellChild(nil, "assets/effect/Thunderstrike w blur.png", pos, 40.0, 3.0, core.AnchorTypeCenter)
-				// 攻击
-				w.Attack(pos, spell)
-			}
+// 更新
+func (w *WeaponThunder) Update(dt float32) {
+	w.Weapon.Update(dt)
+	w.updateAim()
+	// 处理攻击动作
+	if w.Game().WasActionJustPressed(core.ActionAttack) {
+		if w.CanAttack() {
+			w.Game().PlaySound("assets/sound/big-thunder.mp3", false)
+			pos := w.GetAimPosition()
+			spell := world.AddSpellChild(nil, "assets/effect/Thunderstrike w blur.png", pos, 40.0, 3.0, core.AnchorTypeCenter, thunderImpactFrame)
+			spell.SetImpactParticles(&thunderSparkParticles)
+			// 闪电落地时震屏
+			spell.GetSpriteAnim().OnFrameEvent(world.SpellEventImpact, func(string, int) {
+				w.Game().GetCurrentScene().GetCamera().AddTrauma(0.4)
+			})
+			// 攻击
+			w.Attack(pos, spell)
 		}
 	}
 }
 
+// 渲染
+func (w *WeaponThunder) Render() {
+	w.Weapon.Render()
+	if !w.useReticle || w.Parent == nil {
+		return
+	}
+	// 准星跟随插值后的玩家位置，和玩家渲染保持一致
+	pos := w.Parent.GetInterpolatedPosition().Add(w.aimOffset).Sub(w.Game().GetCurrentScene().GetRenderCameraPosition())
+	size := mgl32.Vec2{w.reticle.SrcRect.W, w.reticle.SrcRect.H}
+	w.Game().RenderTexture(w.reticle, pos.Sub(size.Mul(0.5)), size, mgl32.Vec2{1.0, 1.0})
+}
+
+// 非接口实现
+
+// 更新瞄准方式，右摇杆推动时使用准星，鼠标移动时切回鼠标
+func (w *WeaponThunder) updateAim() {
+	stick := w.Game().GetGamepad().GetRightStick()
+	if stick.Len() > 0.0 {
+		w.useReticle = true
+		w.aimOffset = stick.Mul(w.aimRange)
+	}
+	mousePosition := w.Game().GetMousePosition()
+	if mousePosition != w.lastMousePosition {
+		w.lastMousePosition = mousePosition
+		w.useReticle = false
+	}
+}
+
+// 获取瞄准位置(世界坐标系)
+func (w *WeaponThunder) GetAimPosition() mgl32.Vec2 {
+	if w.useReticle && w.Parent != nil {
+		return w.Parent.GetPosition().Add(w.aimOffset)
+	}
+	return w.Game().GetCurrentScene().ScreenToWorld(w.Game().GetMousePosition())
+}
+
+// 获取是否使用准星瞄准
+func (w *WeaponThunder) GetUseReticle() bool {
+	return w.useReticle
+}
+
+// 获取瞄准范围
+func (w *WeaponThunder) GetAimRange() float32 {
+	return w.aimRange
+}
+
+// 设置瞄准范围
+func (w *WeaponThunder) SetAimRange(aimRange float32) {
+	w.aimRange = aimRange
+}
+
 // 获取技能使用恢复百分比
 func (w *WeaponThunder) GetSkillPercent() float32 {
 	return w.CooldownTimer / w.Cooldown
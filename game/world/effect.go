@@ -25,6 +25,7 @@ var _ core.IObjectWorld = (*Effect)(nil)
 func AddEffectChild(parent core.IObject, filePath string, pos mgl32.Vec2, scale float32, anchorType core.AnchorType, nextObject core.IObjectWorld) *Effect {
 	effect := &Effect{}
 	effect.Init()
+	effect.SetRenderLayer(core.RenderLayerEffects)
 	effect.spriteAnim = affiliate.AddSpriteAnimChild(effect, filePath, scale, anchorType)
 	effect.spriteAnim.SetLoop(false)
 	effect.SetPosition(pos)
@@ -51,6 +52,11 @@ func (s *Effect) checkFinish() {
 	}
 }
 
+// 获取精灵动画，用于添加帧事件
+func (s *Effect) GetSpriteAnim() core.IObjectAnima {
+	return s.spriteAnim
+}
+
 // 设置特效播放完成后需要添加到场景中的对象
 func (s *Effect) SetNextObject(nextObject core.IObjectWorld) {
 	s.nextObject = nextObject
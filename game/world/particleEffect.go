@@ -0,0 +1,48 @@
+package world
+
+import (
+	"ghost_escape/game/affiliate"
+	"ghost_escape/game/core"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// 粒子特效，在世界中的某个位置发射粒子，发射完毕并且粒子全部消失后移除
+type ParticleEffect struct {
+	// 继承基础世界对象
+	core.ObjectWorld
+	// 粒子发射器
+	emitter *affiliate.ParticleEmitter
+}
+
+var _ core.IObject = (*ParticleEffect)(nil)
+var _ core.IObjectScreen = (*ParticleEffect)(nil)
+var _ core.IObjectWorld = (*ParticleEffect)(nil)
+
+// 创建粒子特效，持续发射的配置需要指定Duration，否则不会被移除
+func AddParticleEffectChild(parent core.IObject, config affiliate.ParticleConfig, pos mgl32.Vec2) *ParticleEffect {
+	effect := &ParticleEffect{}
+	effect.Init()
+	effect.SetRenderLayer(core.RenderLayerEffects)
+	effect.SetPosition(pos)
+	effect.emitter = affiliate.AddParticleEmitterChild(effect, config)
+	if parent != nil {
+		parent.AddChild(effect)
+	}
+	return effect
+}
+
+// 更新
+func (s *ParticleEffect) Update(dt float32) {
+	s.ObjectWorld.Update(dt)
+	if s.emitter.GetFinish() {
+		s.NeedRemove = true
+	}
+}
+
+// 非接口实现
+
+// 获取粒子发射器
+func (s *ParticleEffect) GetEmitter() *affiliate.ParticleEmitter {
+	return s.emitter
+}
@@ -15,21 +15,36 @@ type Spell struct {
 	spriteAnim core.IObjectAnima
 	// 伤害值
 	damage float32
+	// 命中时的粒子，为nil时没有粒子
+	impactParticles *affiliate.ParticleConfig
 }
 
+// 法术命中事件，动画播放到命中帧时造成伤害
+const SpellEventImpact = "impact"
+
 var _ core.IObject = (*Spell)(nil)
 var _ core.IObjectScreen = (*Spell)(nil)
 var _ core.IObjectWorld = (*Spell)(nil)
 
-// 创建法术
-func AddSpellChild(parent core.IObject, filePath string, pos mgl32.Vec2, damage, scale float32, anchor core.AnchorType) *Spell {
+// 创建法术，impactFrame为命中帧，动画播放到该帧时造成一次伤害
+func AddSpellChild(parent core.IObject, filePath string, pos mgl32.Vec2, damage, scale float32, anchor core.AnchorType, impactFrame int) *Spell {
 	spell := &Spell{}
 	spell.Init()
+	spell.SetRenderLayer(core.RenderLayerEffects)
 	spell.damage = damage
 	spell.spriteAnim = affiliate.AddSpriteAnimChild(spell, filePath, scale, anchor)
 	spell.spriteAnim.SetLoop(false)
+	spell.spriteAnim.AddFrameEvent(impactFrame, SpellEventImpact)
+	spell.spriteAnim.OnFrameEvent(SpellEventImpact, func(string, int) {
+		spell.attack()
+		spell.emitImpactParticles()
+	})
 	size := spell.spriteAnim.GetSize()
 	spell.Collider = affiliate.AddColliderChild(spell, size, core.ColliderTypeCircle, anchor)
+	// 法术只在命中帧伤害敌人，是触发器，敌人不会感知到法术
+	spell.Collider.SetCollisionLayer(core.CollisionLayerSpell)
+	spell.Collider.SetCollisionMask(core.CollisionLayerEnemy)
+	spell.Collider.SetTrigger(true)
 	spell.SetPosition(pos)
 	if parent != nil {
 		parent.AddChild(spell)
@@ -43,24 +58,37 @@ func (s *Spell) Update(dt float32) {
 	if s.spriteAnim.GetFinish() {
 		s.NeedRemove = true
 	}
-	s.attack()
 }
 
 // 非接口实现
 
+// 获取精灵动画，用于添加其他帧事件
+func (s *Spell) GetSpriteAnim() core.IObjectAnima {
+	return s.spriteAnim
+}
+
+// 设置命中时的粒子，为nil时没有粒子
+func (s *Spell) SetImpactParticles(config *affiliate.ParticleConfig) {
+	s.impactParticles = config
+}
+
+// 在命中位置发射粒子
+func (s *Spell) emitImpactParticles() {
+	if s.impactParticles == nil {
+		return
+	}
+	effect := AddParticleEffectChild(nil, *s.impactParticles, s.GetPosition())
+	s.Game().GetCurrentScene().SafeAddChild(effect)
+}
+
 // 攻击
 func (s *Spell) attack() {
-	objects := core.GetInstance().GetCurrentScene().GetChildWorld()
-	// 遍历所有对象
-	for e := objects.Front(); e != nil; e = e.Next() {
-		object := e.Value.(core.IObjectWorld)
-		if object.GetType() != core.ObjectTypeEnemy {
-			continue
-		}
-		// 检查碰撞
-		if s.Collider.IsColliding(object.GetCollider()) {
-			// 敌人受到伤害
-			object.TakeDamage(s.damage)
+	spatialHash := core.GetInstance().GetCurrentScene().GetSpatialHash()
+	// 只检查法术附近、在碰撞掩码内的对象
+	colliders := spatialHash.OverlapCollider(s.Collider, core.LayerFilter(s.Collider.GetCollisionMask()))
+	for _, collider := range colliders {
+		if target, ok := collider.GetParent().(core.IActor); ok {
+			target.TakeDamage(s.damage)
 		}
 	}
 }
@@ -1,19 +1,133 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"io/fs"
 
 	"ghost_escape/game"
 	"ghost_escape/game/core"
 )
 
+// 编译进程序的素材，使用embed标签编译时设置
+var embeddedAssets fs.FS
+
 func main() {
-	sceneTitle := &game.SceneTitle{}
+	headless := flag.Bool("headless", false, "无头模式，不创建窗口直接运行主场景")
+	ticks := flag.Int("ticks", 60*60, "无头模式下运行的tick数")
+	seed := flag.Int64("seed", 0, "随机种子，不指定则每一局随机生成")
+	record := flag.String("record", "", "录制输入到指定文件，直接从主场景开始")
+	replay := flag.String("replay", "", "回放指定录像文件，直接从主场景开始")
+	assets := flag.Bool("assets", false, "退出前打印已加载素材及占用内存")
+	pack := flag.String("pack", "", "挂载zip素材包，包内文件覆盖素材目录中的同名文件")
+	mod := flag.String("mod", "", "挂载mod目录，目录中的文件覆盖素材包和素材目录中的同名文件")
+	hotReload := flag.Bool("hotreload", false, "开发模式，素材文件修改后自动重新加载")
+	dumpAtlas := flag.String("dumpatlas", "", "把生成的纹理图集和布局导出到指定目录")
+	debug := flag.Bool("debug", false, "绘制碰撞器等调试信息")
+	flag.Parse()
+
+	// 只有显式指定了种子才固定种子
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "seed" {
+			core.GetInstance().SetSeed(*seed)
+		}
+	})
+
+	assetFS, err := createAssetFS(*pack, *mod)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	core.GetInstance().SetAssetFS(assetFS)
+
+	// 启动时检查素材清单，提前报告缺失的文件，避免游戏中途加载失败
+	if err := core.ValidateAssetManifests(assetFS, core.AssetManifestDir); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	// 回放需要在初始化之前载入录像，使用录像中的种子
+	if *replay != "" {
+		if err := core.GetInstance().StartPlayback(*replay); err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+
+	if *headless {
+		runHeadless(*ticks, *record, *assets)
+		return
+	}
+
+	// 录制和回放都从主场景开始，保证起点一致
+	var scene core.IScene = &game.SceneTitle{}
+	if *record != "" || *replay != "" {
+		scene = &game.SceneMain{}
+	}
 	game := core.GetInstance()
-	if err := game.Init("GhostEscape", 1280, 720, sceneTitle); err != nil {
+	if err := game.Init("GhostEscape", 1280, 720, scene); err != nil {
 		fmt.Println(err)
 		return
 	}
+	if *record != "" {
+		game.StartRecording(*record)
+	}
+	game.GetAssetStore().SetHotReload(*hotReload)
+	game.GetAssetStore().SetAtlasDumpDir(*dumpAtlas)
+	game.SetDebugDraw(*debug)
 	game.Run()
+	if *assets {
+		game.PrintAssetReport()
+	}
 	game.Clean()
 }
+
+// 创建素材文件系统，优先级从低到高依次是素材目录或者编译进程序的素材、素材包、mod目录
+func createAssetFS(pack string, mod string) (*core.AssetFS, error) {
+	assetFS := core.CreateAssetFS(core.FindAssetBaseDir())
+	if embeddedAssets != nil {
+		assetFS.Mount("", embeddedAssets)
+	} else if err := assetFS.MountDir("", assetFS.GetBaseDir()); err != nil {
+		return nil, err
+	}
+	if pack != "" {
+		if err := assetFS.MountZip("", pack); err != nil {
+			return nil, err
+		}
+	}
+	if mod != "" {
+		if err := assetFS.MountDir("", mod); err != nil {
+			return nil, err
+		}
+	}
+	return assetFS, nil
+}
+
+// 无头模式运行主场景，结束后输出场景状态
+func runHeadless(ticks int, record string, assets bool) {
+	sceneMain := &game.SceneMain{}
+	g := core.GetInstance()
+	if err := g.InitHeadless(1280, 720, sceneMain); err != nil {
+		fmt.Println(err)
+		return
+	}
+	if record != "" {
+		g.StartRecording(record)
+	}
+	// 回放时一直运行到录像结束
+	if player := g.GetReplayPlayer(); player != nil {
+		for g.IsRunning() && !player.GetFinish() {
+			g.Step(1)
+		}
+	} else {
+		g.Step(ticks)
+	}
+	player := sceneMain.GetPlayer()
+	fmt.Printf("seed: %d, tick: %d, score: %d, player alive: %v, health: %.1f, position: %v, spawned: %d, enemies: %d\n",
+		g.GetSeed(), g.GetTick(), g.GetScore(), player.GetAlive(), player.GetStats().GetHealth(), player.GetPosition(),
+		sceneMain.GetSpawner().GetSpawnedCount(), len(sceneMain.GetEnemies()))
+	if assets {
+		g.PrintAssetReport()
+	}
+	g.Clean()
+}